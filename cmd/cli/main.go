@@ -2,16 +2,22 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 
 	"github.com/iagonc/jorge-cli/cmd/cli/commands"
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/config"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/network"
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/resource"
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
+	"github.com/iagonc/jorge-cli/cmd/cli/pkg/client"
 )
 
 func main() {
@@ -22,34 +28,76 @@ func main() {
 	}
 	defer logger.Sync()
 
-	// Load configurations
-	cfg, err := config.LoadConfig()
+	// Credentials stored by login, per profile
+	credentialsPath, err := config.DefaultCredentialsPath()
 	if err != nil {
-		logger.Error("Error loading configurations", zap.Error(err))
+		logger.Error("Error locating credentials", zap.Error(err))
 		os.Exit(1)
 	}
-
-	// Initialize the HTTP client
-	client := utils.NewHTTPClient(cfg.Timeout)
+	credentialStore := &config.CredentialStore{Path: credentialsPath}
 
 	// Initialize the resource Usecase
-	resourceUsecase := resource.NewResourceUsecase(client, cfg, logger)
-	networkUsecase := network.NewNetworkDebugUsecase(logger)
+	// The API client is built once the flags that select the config file and
+	// profile are parsed, and only for commands that call the API.
+	resourceUsecase := resource.NewResourceUsecase(nil, logger)
+	networkUsecase := network.NewNetworkDebugUsecase(logger, network.NewExecRunner())
 
 	// Set up the root command
 	var rootCmd = &cobra.Command{
 		Use:     "jorge-cli",
 		Short:   "Jorge CLI - A friendly network diagnostic and resource management tool",
 		Long:    "A command-line tool to perform network diagnostics and manage resources via API.",
-		Version: cfg.Version,
+		Version: config.Version(),
+		// Errors are printed below, except the exit codes of commands that
+		// already reported their outcome.
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if debugLog, _ := cmd.Flags().GetBool("debug-log"); debugLog {
+				utils.LogLevel.SetLevel(zap.DebugLevel)
+			}
+			// Flags > environment > context > defaults
+			resourceUsecase.Connect = func() (resource.ResourceAPI, error) {
+				configPath, _ := cmd.Flags().GetString("config")
+				profile, _ := cmd.Flags().GetString("profile")
+				cfg, err := config.LoadConfig(config.LoadOptions{Path: configPath, Profile: profile, Flags: cmd.Flags()})
+				if err != nil {
+					return nil, err
+				}
+				apiClient, err := newAPIClient(cfg, credentialStore, cfg.Profile, logger)
+				if err != nil {
+					return nil, err
+				}
+				return apiClient, nil
+			}
+			// Commands that skip the API build the client only if they call it
+			if commands.SkipsAPI(cmd) {
+				return nil
+			}
+			if err := resourceUsecase.EnsureAPI(); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			return nil
+		},
 	}
 
+	rootCmd.PersistentFlags().StringP("output", "o", string(output.FormatTable), output.FlagUsage)
+	rootCmd.PersistentFlags().String("config", "", "Config file (default: $TEEMO_CONFIG or teemo/config.yaml in the user config directory)")
+	rootCmd.PersistentFlags().String("profile", "", "Context whose settings and stored credentials are used (default: $TEEMO_PROFILE, the current context or \"default\")")
+	rootCmd.PersistentFlags().String("api-url", "", "Base URL of the resource API, overriding the environment and the context")
+	rootCmd.PersistentFlags().String("api-timeout", "", "Timeout of each API request attempt, e.g. 30s, overriding the environment and the context")
+	rootCmd.PersistentFlags().Bool("debug-log", false, "Log every check, command line, exit code and tool output at debug level to stderr")
+
 	// Add commands, passing the usecases
 	rootCmd.AddCommand(commands.NewListCommand(resourceUsecase))
+	rootCmd.AddCommand(commands.NewGetCommand(resourceUsecase))
 	rootCmd.AddCommand(commands.NewCreateCommand(resourceUsecase))
 	rootCmd.AddCommand(commands.NewDeleteCommand(resourceUsecase))
 	rootCmd.AddCommand(commands.NewUpdateCommand(resourceUsecase))
-	rootCmd.AddCommand(commands.NewNetworkDebugCommand(networkUsecase))
+	rootCmd.AddCommand(commands.NewNetworkDebugCommand(networkUsecase, resourceUsecase))
+	rootCmd.AddCommand(commands.NewLoginCommand(credentialStore, logger))
+	rootCmd.AddCommand(commands.NewLogoutCommand(credentialStore, logger))
+	rootCmd.AddCommand(commands.NewConfigCommand(logger))
 
 	// Handle system signals for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -59,7 +107,58 @@ func main() {
 
 	// Execute the root command with context
 	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		// Commands that report their own outcome only ask for an exit code.
+		var exitErr *commands.ExitError
+		if errors.As(err, &exitErr) {
+			logger.Sync()
+			os.Exit(exitErr.Code)
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err)
 		logger.Error("Error executing command", zap.Error(err))
 		os.Exit(1)
 	}
 }
+
+// newAPIClient builds the resource API client, giving each attempt of a call
+// the configured timeout, retrying failed ones and authenticating with the
+// credentials login stored for profile, if any, when they were stored for
+// this API.
+func newAPIClient(cfg *config.Config, store *config.CredentialStore, profile string, logger *zap.Logger) (*client.Client, error) {
+	// No http.Client.Timeout: it would cover every attempt and the backoff
+	// between them, leaving a slow first attempt no room to be retried.
+	httpClient := &http.Client{
+		Transport: &client.RetryTransport{
+			MaxAttempts:    cfg.RetryMaxAttempts,
+			BaseDelay:      cfg.RetryBackoff,
+			MaxDelay:       cfg.RetryMaxBackoff,
+			AttemptTimeout: cfg.Timeout,
+			Logger:         logger,
+		},
+	}
+	opts := []client.Option{
+		client.WithHTTPClient(httpClient),
+		client.WithUserAgent("teemo-cli/" + cfg.Version),
+	}
+
+	creds, err := store.Load(profile)
+	if err != nil {
+		return nil, err
+	}
+	if creds != nil {
+		if err := creds.CheckAPIURL(cfg.APIBaseURL); err != nil {
+			return nil, fmt.Errorf("not sending the credentials of profile '%s': %w", profile, err)
+		}
+		// Cache renewed OAuth2 tokens, so the next command does not fetch another one
+		auth, err := creds.Authenticator(httpClient, func(token string, expiry time.Time) {
+			if err := store.SaveToken(profile, token, expiry); err != nil {
+				logger.Warn("Error caching OAuth2 token", zap.Error(err))
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials for profile '%s': %w", profile, err)
+		}
+		opts = append(opts, client.WithAuth(auth))
+	}
+
+	return client.New(cfg.APIBaseURL, opts...)
+}
@@ -0,0 +1,20 @@
+package commands
+
+import "github.com/spf13/cobra"
+
+// annotationSkipAPI marks commands that work without loading the config and
+// the API client up front, so broken settings or credentials cannot stop
+// them from fixing those, or from diagnosing the network.
+// It applies to the subcommands of an annotated command too.
+const annotationSkipAPI = "skip-api"
+
+// SkipsAPI reports whether cmd or one of its parents runs without the config
+// and the API client.
+func SkipsAPI(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[annotationSkipAPI] == "true" {
+			return true
+		}
+	}
+	return false
+}
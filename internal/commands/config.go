@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/config"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
+)
+
+func NewConfigCommand(logger *zap.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the config file and its contexts",
+		Long: "Manage the YAML config file (--config, $TEEMO_CONFIG or the user config directory). " +
+			"Each context holds the settings of one API; --profile or $TEEMO_PROFILE picks one for a single command, " +
+			"use-context for every command. Flags override environment variables, which override the context, " +
+			"which overrides the defaults.",
+	}
+
+	cmd.AddCommand(newConfigViewCommand(logger))
+	cmd.AddCommand(newConfigUseContextCommand(logger))
+	cmd.AddCommand(newConfigSetCommand(logger))
+
+	return cmd
+}
+
+func newConfigViewCommand(logger *zap.Logger) *cobra.Command {
+	var resolved bool
+
+	cmd := &cobra.Command{
+		Use:          "view",
+		Short:        "Show the config file, or with --resolved the settings in effect",
+		Args:         cobra.NoArgs,
+		Annotations:  map[string]string{annotationSkipAPI: "true"},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := outputOptions(cmd)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+			titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
+
+			if resolved {
+				profile, _ := cmd.Flags().GetString("profile")
+				path, _ := cmd.Flags().GetString("config")
+				cfg, err := config.LoadConfig(config.LoadOptions{Path: path, Profile: profile, Flags: cmd.Flags()})
+				if err != nil {
+					logger.Error("Error loading configurations", zap.Error(err))
+					fmt.Fprintln(os.Stderr, err)
+					return errExitError
+				}
+
+				if opts.IsHuman() {
+					fmt.Println(titleStyle.Render(fmt.Sprintf("⚙️  Context '%s' (%s)", cfg.Profile, cfg.Path)))
+					fmt.Println()
+				}
+				data := resolvedConfig{Path: cfg.Path, Profile: cfg.Profile, Settings: cfg.Settings}
+				if err := output.Render(os.Stdout, opts, data, settingsTable(cfg.Settings)); err != nil {
+					logger.Error("Error rendering settings", zap.Error(err))
+					fmt.Fprintln(os.Stderr, "Error rendering settings:", err)
+					return errExitError
+				}
+				return nil
+			}
+
+			file, path, err := configFile(cmd)
+			if err != nil {
+				logger.Error("Error loading config file", zap.Error(err))
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+
+			if !opts.IsHuman() {
+				if err := output.Render(os.Stdout, opts, file, nil); err != nil {
+					logger.Error("Error rendering config file", zap.Error(err))
+					fmt.Fprintln(os.Stderr, "Error rendering config file:", err)
+					return errExitError
+				}
+				return nil
+			}
+
+			fmt.Println(titleStyle.Render("📄 " + path))
+			if len(file.Contexts) == 0 && file.CurrentContext == "" {
+				fmt.Println("- No contexts configured; settings come from flags, the environment and defaults.")
+				return nil
+			}
+			data, err := yaml.Marshal(file)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error rendering config file:", err)
+				return errExitError
+			}
+			fmt.Print(string(data))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&resolved, "resolved", false, "Show every setting of the selected context with its value and source")
+
+	return cmd
+}
+
+func newConfigUseContextCommand(logger *zap.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "use-context NAME",
+		Short:        "Make NAME the context every command uses by default",
+		Args:         cobra.ExactArgs(1),
+		Annotations:  map[string]string{annotationSkipAPI: "true"},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, path, err := configFile(cmd)
+			if err != nil {
+				logger.Error("Error loading config file", zap.Error(err))
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+			if err := file.UseContext(args[0]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+			if err := file.Write(path); err != nil {
+				logger.Error("Error saving config file", zap.Error(err))
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+
+			fmt.Printf("Switched to context '%s'.\n", args[0])
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newConfigSetCommand(logger *zap.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set KEY VALUE",
+		Short: "Set a setting of the selected context, creating the context if needed",
+		Long: "Set a setting of the context selected with --profile, $TEEMO_PROFILE or use-context. " +
+			"Durations take Go syntax such as 30s or a number of seconds.\n\nKeys:\n" + settingKeys(),
+		Args:         cobra.ExactArgs(2),
+		Annotations:  map[string]string{annotationSkipAPI: "true"},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, value := args[0], args[1]
+
+			file, path, err := configFile(cmd)
+			if err != nil {
+				logger.Error("Error loading config file", zap.Error(err))
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+			profile, _ := cmd.Flags().GetString("profile")
+			context := file.Profile(profile)
+			if err := file.Set(context, key, value); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+			if err := file.Write(path); err != nil {
+				logger.Error("Error saving config file", zap.Error(err))
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+
+			fmt.Printf("Set %s to %s in context '%s'.\n", key, value, context)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// resolvedConfig is what config view --resolved renders in the data formats.
+type resolvedConfig struct {
+	Path     string                `json:"path" yaml:"path"`
+	Profile  string                `json:"profile" yaml:"profile"`
+	Settings []config.SettingValue `json:"settings" yaml:"settings"`
+}
+
+// configFile reads the config file selected with the global --config flag.
+func configFile(cmd *cobra.Command) (*config.File, string, error) {
+	flagPath, _ := cmd.Flags().GetString("config")
+	path, err := config.ConfigPath(flagPath)
+	if err != nil {
+		return nil, "", err
+	}
+	file, err := config.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return file, path, nil
+}
+
+func settingsTable(settings []config.SettingValue) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Header: "Key"},
+			{Header: "Value"},
+			{Header: "Source"},
+		},
+	}
+	for _, setting := range settings {
+		table.AddRow(setting.Key, setting.Value, setting.Source)
+	}
+	return table
+}
+
+// settingKeys lists the keys for the help of config set.
+func settingKeys() string {
+	var keys string
+	for _, setting := range config.Settings {
+		env := setting.EnvNames()[0]
+		if setting.Flag != "" {
+			env += ", --" + setting.Flag
+		}
+		keys += fmt.Sprintf("  %-20s %s (default %s; %s)\n", setting.Key, setting.Description, setting.Default, env)
+	}
+	return keys
+}
@@ -2,10 +2,12 @@ package commands
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/resource"
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
 
@@ -16,23 +18,39 @@ func NewCreateCommand(usecase *resource.ResourceUsecase) *cobra.Command {
 	var name, dns string
 
 	cmd := &cobra.Command{
-		Use:   "create",
-		Short: "Create a new resource",
-		Run: func(cmd *cobra.Command, args []string) {
+		Use:          "create",
+		Short:        "Create a new resource",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
+			opts, err := outputOptions(cmd)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+
 			// Validate inputs
 			if err := utils.ValidateCreateInputs(name, dns); err != nil {
 				usecase.Logger.Error("Invalid input", zap.Error(err))
-				fmt.Println(err)
-				return
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
 			}
 
 			resource, err := usecase.CreateResource(ctx, name, dns)
 			if err != nil {
 				usecase.Logger.Error("Error creating resource", zap.Error(err))
-				fmt.Println("Error creating resource:", err)
-				return
+				fmt.Fprintln(os.Stderr, "Error creating resource:", err)
+				return errExitError
+			}
+
+			if opts.Format != output.FormatTable {
+				if err := output.Render(os.Stdout, opts, resource, resourceTable(*resource)); err != nil {
+					usecase.Logger.Error("Error rendering resource", zap.Error(err))
+					fmt.Fprintln(os.Stderr, "Error rendering resource:", err)
+					return errExitError
+				}
+				return nil
 			}
 
 			successStyle := lipgloss.NewStyle().
@@ -45,6 +63,7 @@ func NewCreateCommand(usecase *resource.ResourceUsecase) *cobra.Command {
 			)
 
 			fmt.Println(result)
+			return nil
 		},
 	}
 
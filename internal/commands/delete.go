@@ -2,10 +2,12 @@ package commands
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/resource"
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
 
@@ -16,37 +18,58 @@ func NewDeleteCommand(usecase *resource.ResourceUsecase) *cobra.Command {
 	var id string
 
 	cmd := &cobra.Command{
-		Use:   "delete",
-		Short: "Delete a resource by ID",
-		Run: func(cmd *cobra.Command, args []string) {
+		Use:          "delete",
+		Short:        "Delete a resource by ID",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
+			opts, err := outputOptions(cmd)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+
 			idInt, err := utils.ParseID(id)
 			if err != nil {
 				usecase.Logger.Error("Invalid ID", zap.Error(err))
-				fmt.Println(err)
-				return
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
 			}
 
 			resource, err := usecase.GetResourceByID(ctx, idInt)
 			if err != nil {
 				usecase.Logger.Error("Error fetching resource", zap.Error(err))
-				fmt.Println("Error fetching resource:", err)
-				return
+				fmt.Fprintln(os.Stderr, "Error fetching resource:", err)
+				return errExitError
 			}
 
-			fmt.Printf("Resource Details:\nID: %d\nName: %s\nDNS: %s\n", resource.ID, resource.Name, resource.Dns)
+			// Keep stdout for the deleted resource in the data formats
+			details := os.Stdout
+			if !opts.IsHuman() {
+				details = os.Stderr
+			}
+			fmt.Fprintf(details, "Resource Details:\nID: %d\nName: %s\nDNS: %s\n", resource.ID, resource.Name, resource.Dns)
 
 			if !utils.ConfirmAction("Are you sure you want to delete this resource? (yes/no): ") {
-				fmt.Println("Delete operation canceled.")
-				return
+				fmt.Fprintln(details, "Delete operation canceled.")
+				return nil
 			}
 
 			deletedResource, err := usecase.DeleteResource(ctx, idInt)
 			if err != nil {
 				usecase.Logger.Error("Error deleting resource", zap.Error(err))
-				fmt.Println("Error deleting resource:", err)
-				return
+				fmt.Fprintln(os.Stderr, "Error deleting resource:", err)
+				return errExitError
+			}
+
+			if opts.Format != output.FormatTable {
+				if err := output.Render(os.Stdout, opts, deletedResource, resourceTable(*deletedResource)); err != nil {
+					usecase.Logger.Error("Error rendering resource", zap.Error(err))
+					fmt.Fprintln(os.Stderr, "Error rendering resource:", err)
+					return errExitError
+				}
+				return nil
 			}
 
 			successStyle := lipgloss.NewStyle().
@@ -59,6 +82,7 @@ func NewDeleteCommand(usecase *resource.ResourceUsecase) *cobra.Command {
 			)
 
 			fmt.Println(result)
+			return nil
 		},
 	}
 
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// Exit codes of commands that rate what they checked, so scripts can tell a
+// degraded target from one that is down or a run that could not be made.
+const (
+	ExitCodePass  = 0
+	ExitCodeError = 1
+	ExitCodeWarn  = 2
+	ExitCodeFail  = 3
+)
+
+// ExitError asks main to exit with Code. The command has already reported
+// the outcome, so there is nothing left to print.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// errExitError is returned by commands that printed their own error.
+var errExitError = &ExitError{Code: ExitCodeError}
+
+// verdictExit maps a verdict status to the error that exits with its code.
+func verdictExit(status string) error {
+	switch status {
+	case models.VerdictWarn:
+		return &ExitError{Code: ExitCodeWarn}
+	case models.VerdictFail:
+		return &ExitError{Code: ExitCodeFail}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+func TestVerdictExit(t *testing.T) {
+	tests := []struct {
+		status string
+		code   int
+	}{
+		{models.VerdictPass, ExitCodePass},
+		{models.VerdictWarn, ExitCodeWarn},
+		{models.VerdictFail, ExitCodeFail},
+		// A run that produced no verdict has nothing to fail on.
+		{"", ExitCodePass},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			err := verdictExit(tt.status)
+			if tt.code == ExitCodePass {
+				if err != nil {
+					t.Fatalf("verdictExit(%q) = %v, want nil", tt.status, err)
+				}
+				return
+			}
+
+			// main unwraps the code with errors.As, also through wrapping.
+			var exitErr *ExitError
+			if !errors.As(fmt.Errorf("debug: %w", err), &exitErr) {
+				t.Fatalf("verdictExit(%q) = %v, want an *ExitError", tt.status, err)
+			}
+			if exitErr.Code != tt.code {
+				t.Errorf("verdictExit(%q) exits with %d, want %d", tt.status, exitErr.Code, tt.code)
+			}
+			if want := fmt.Sprintf("exit status %d", tt.code); err.Error() != want {
+				t.Errorf("error = %q, want %q", err, want)
+			}
+		})
+	}
+}
+
+func TestExitCodesAreDistinct(t *testing.T) {
+	codes := map[int]string{}
+	for name, code := range map[string]int{"pass": ExitCodePass, "error": ExitCodeError, "warn": ExitCodeWarn, "fail": ExitCodeFail} {
+		if other, ok := codes[code]; ok {
+			t.Errorf("%s and %s share exit code %d", name, other, code)
+		}
+		codes[code] = name
+	}
+	if errExitError.Code != ExitCodeError {
+		t.Errorf("errExitError exits with %d, want %d", errExitError.Code, ExitCodeError)
+	}
+}
+
+func TestDNSComparisonStatus(t *testing.T) {
+	agrees := models.ResolverAnswer{Resolver: "10.0.0.1:53", Agrees: true}
+	stale := models.ResolverAnswer{Resolver: "10.0.0.2:53", Agrees: true, StaleTTL: true}
+	differs := models.ResolverAnswer{Resolver: "10.0.0.3:53"}
+	failed := models.ResolverAnswer{Resolver: "10.0.0.4:53", Error: "i/o timeout"}
+
+	tests := []struct {
+		name   string
+		result models.DNSComparisonResult
+		want   string
+	}{
+		{"consistent", models.DNSComparisonResult{Consistent: true, Resolvers: []models.ResolverAnswer{agrees}}, models.VerdictPass},
+		{"stale TTL", models.DNSComparisonResult{Resolvers: []models.ResolverAnswer{agrees, stale}}, models.VerdictWarn},
+		{"differing answer", models.DNSComparisonResult{Resolvers: []models.ResolverAnswer{agrees, stale, differs}}, models.VerdictFail},
+		{"resolver error", models.DNSComparisonResult{Resolvers: []models.ResolverAnswer{agrees, failed}}, models.VerdictFail},
+	}
+	for _, tt := range tests {
+		if got := dnsComparisonStatus(&tt.result); got != tt.want {
+			t.Errorf("%s: dnsComparisonStatus = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/resource"
+)
+
+func NewGetCommand(usecase *resource.ResourceUsecase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <id|name>",
+		Short: "Show one resource by ID, or by exact name or DNS",
+		Long: "Show every field of one resource. The argument is looked up as an ID when it is a number, " +
+			"otherwise, or when no resource has that ID, as an exact name or DNS.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			opts, err := outputOptions(cmd)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+
+			resource, err := usecase.FindResource(ctx, args[0])
+			if err != nil {
+				usecase.Logger.Error("Error fetching resource", zap.String("resource", args[0]), zap.Error(err))
+				fmt.Fprintln(os.Stderr, "Error fetching resource:", err)
+				return errExitError
+			}
+
+			if opts.IsHuman() {
+				printResourceDetails(resource)
+				return nil
+			}
+			if err := output.Render(os.Stdout, opts, resource, resourceTable(*resource)); err != nil {
+				usecase.Logger.Error("Error rendering resource", zap.Error(err))
+				fmt.Fprintln(os.Stderr, "Error rendering resource:", err)
+				return errExitError
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// printResourceDetails shows one field per line, with the full timestamps.
+func printResourceDetails(resource *models.Resource) {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
+	fmt.Println(titleStyle.Render("Resource " + strconv.Itoa(resource.ID)))
+
+	deletedAt := "-"
+	if resource.DeletedAt != nil {
+		deletedAt = formatTimestamp(*resource.DeletedAt)
+	}
+	fields := [][2]string{
+		{"ID", strconv.Itoa(resource.ID)},
+		{"Name", resource.Name},
+		{"DNS", resource.Dns},
+		{"CreatedAt", formatTimestamp(resource.CreatedAt)},
+		{"UpdatedAt", formatTimestamp(resource.UpdatedAt)},
+		{"DeletedAt", deletedAt},
+	}
+	for _, field := range fields {
+		fmt.Printf("  %-10s %s\n", field[0]+":", field[1])
+	}
+}
+
+// formatTimestamp renders an API timestamp to the second, keeping its zone.
+func formatTimestamp(value string) string {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return value
+	}
+	return t.Format("2006-01-02 15:04:05 MST")
+}
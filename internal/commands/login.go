@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/config"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
+	"github.com/iagonc/jorge-cli/cmd/cli/pkg/client"
+)
+
+func NewLoginCommand(store *config.CredentialStore, logger *zap.Logger) *cobra.Command {
+	var creds config.Credentials
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Store the credentials the CLI authenticates to the API with",
+		Long: "Store the credentials of the selected profile for the API it points at: a bearer token (--token), " +
+			"an API key (--api-key), a username and password (--username) or OAuth2 client credentials " +
+			"(--client-id, --client-secret, --token-url). Secrets are prompted for, or read from stdin when it is not a terminal; " +
+			"--token=VALUE and the like also work but leave the secret in the shell history and the process list. " +
+			"The credentials are only sent to the API URL in effect at login.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			// --token VALUE parses as a bare --token followed by an argument.
+			if len(args) > 0 {
+				return fmt.Errorf("unexpected argument %q: secrets are prompted for or read from stdin; use --flag=VALUE to pass one inline", args[0])
+			}
+			return nil
+		},
+		Annotations:  map[string]string{annotationSkipAPI: "true"},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			configPath, _ := cmd.Flags().GetString("config")
+			profileFlag, _ := cmd.Flags().GetString("profile")
+			cfg, err := config.LoadConfig(config.LoadOptions{Path: configPath, Profile: profileFlag, Flags: cmd.Flags()})
+			if err != nil {
+				logger.Error("Error loading configurations", zap.Error(err))
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+			profile := cfg.Profile
+			creds.APIURL = cfg.APIBaseURL
+
+			given := 0
+			for _, set := range []bool{creds.Token != "", creds.APIKey != "", creds.Username != "", creds.ClientID != ""} {
+				if set {
+					given++
+				}
+			}
+			if given != 1 {
+				fmt.Fprintln(os.Stderr, "exactly one of --token, --api-key, --username or --client-id is required")
+				return errExitError
+			}
+
+			switch {
+			case creds.Token != "":
+				creds.Type = config.AuthBearer
+				creds.Token, err = secretValue(creds.Token, "Token")
+			case creds.APIKey != "":
+				creds.Type = config.AuthAPIKey
+				creds.APIKey, err = secretValue(creds.APIKey, "API key")
+			case creds.Username != "":
+				creds.Type = config.AuthBasic
+				creds.Password, err = secretValue("-", "Password")
+			default:
+				creds.Type = config.AuthOAuth2
+				if creds.ClientSecret == "" {
+					creds.ClientSecret = "-"
+				}
+				creds.ClientSecret, err = secretValue(creds.ClientSecret, "Client secret")
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error reading credentials:", err)
+				return errExitError
+			}
+			if err := creds.Validate(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+
+			// Fetch a token now, so wrong client credentials fail here rather than on the next command
+			if creds.Type == config.AuthOAuth2 {
+				auth, err := creds.Authenticator(nil, nil)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return errExitError
+				}
+				oauth := auth.(*client.ClientCredentials)
+				if _, err := oauth.FetchToken(ctx); err != nil {
+					logger.Error("Error fetching OAuth2 token", zap.Error(err))
+					fmt.Fprintln(os.Stderr, "Error fetching OAuth2 token:", err)
+					return errExitError
+				}
+				creds.Token, creds.TokenExpiry = oauth.Token, oauth.Expiry
+			}
+
+			if err := store.Save(profile, creds); err != nil {
+				logger.Error("Error saving credentials", zap.Error(err))
+				fmt.Fprintln(os.Stderr, "Error saving credentials:", err)
+				return errExitError
+			}
+
+			successStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#10B981")).Bold(true) // Green
+			fmt.Println(successStyle.Render(fmt.Sprintf("Logged in to profile '%s' with %s credentials for %s.", profile, creds.Type, creds.APIURL)))
+			fmt.Printf("Credentials saved to %s\n", store.Path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&creds.Token, "token", "", "Authenticate with a bearer token, prompted for or read from stdin")
+	cmd.Flags().StringVar(&creds.APIKey, "api-key", "", "Authenticate with an API key sent in the --api-key-header header, prompted for or read from stdin")
+	cmd.Flags().StringVar(&creds.APIKeyHeader, "api-key-header", "", "Header the API key is sent in (default: "+client.DefaultAPIKeyHeader+")")
+	cmd.Flags().StringVar(&creds.Username, "username", "", "Authenticate with HTTP basic authentication as this user; the password is prompted for or read from stdin")
+	cmd.Flags().StringVar(&creds.ClientID, "client-id", "", "OAuth2 client ID for the client credentials grant")
+	cmd.Flags().StringVar(&creds.ClientSecret, "client-secret", "", "OAuth2 client secret, prompted for or read from stdin when omitted")
+	cmd.Flags().StringVar(&creds.TokenURL, "token-url", "", "OAuth2 token endpoint")
+	cmd.Flags().StringSliceVar(&creds.Scopes, "scopes", nil, "OAuth2 scopes to request")
+	// A bare --token, --api-key or --client-secret asks for the secret.
+	for _, name := range []string{"token", "api-key", "client-secret"} {
+		cmd.Flags().Lookup(name).NoOptDefVal = "-"
+	}
+
+	return cmd
+}
+
+func NewLogoutCommand(store *config.CredentialStore, logger *zap.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "logout",
+		Short:        "Remove the stored credentials of the selected profile",
+		Args:         cobra.NoArgs,
+		Annotations:  map[string]string{annotationSkipAPI: "true"},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile := profileName(cmd)
+			removed, err := store.Delete(profile)
+			if err != nil {
+				logger.Error("Error removing credentials", zap.Error(err))
+				fmt.Fprintln(os.Stderr, "Error removing credentials:", err)
+				return errExitError
+			}
+			if !removed {
+				fmt.Printf("Profile '%s' has no stored credentials.\n", profile)
+				return nil
+			}
+			fmt.Printf("Logged out of profile '%s'.\n", profile)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// profileName returns the profile selected with the global --profile flag,
+// $TEEMO_PROFILE or the current context. A config file that cannot be read
+// is skipped, so credentials can still be managed while it is broken.
+func profileName(cmd *cobra.Command) string {
+	profile, _ := cmd.Flags().GetString("profile")
+	file, _, err := configFile(cmd)
+	if err != nil {
+		file = &config.File{}
+	}
+	return file.Profile(profile)
+}
+
+// secretValue returns value, or reads it from stdin when value is "-":
+// without echo from a terminal, otherwise as the first line.
+func secretValue(value, prompt string) (string, error) {
+	if value != "-" {
+		return value, nil
+	}
+	if utils.IsTerminal(os.Stdin) {
+		fmt.Fprintf(os.Stderr, "%s: ", prompt)
+		secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return string(secret), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -2,76 +2,319 @@ package commands
 
 import (
 	"fmt"
-	"os/exec"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/network"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/resource"
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 )
 
-func NewNetworkDebugCommand(usecase *network.NetworkDebugUsecase) *cobra.Command {
-    var domain string
+func NewNetworkDebugCommand(usecase *network.NetworkDebugUsecase, resourceUsecase *resource.ResourceUsecase) *cobra.Command {
+    var domain, dnsServer string
+    var targetsFile string
+    var allResources bool
+    var concurrency int
+    var tlsExpiryWarnDays int
+    var onlyChecks, skipChecks []string
+    var httpURL, httpMethod, expectBody string
+    var httpHeaders, expectStatus []string
+    var pingProtocol string
+    var pingCount, pingSize, pingPort int
+    var pingInterval time.Duration
+    var allSockets bool
+    var iface string
+    var traceProtocol string
+    var maxHops, probesPerHop, firstTTL, tracePort int
+    var traceNumeric bool
+    var interfaceWindow time.Duration
+    var portValues []string
+    var portTimeout time.Duration
+    var portBanner bool
+    var watch bool
+    var watchInterval, latencyThreshold time.Duration
+    var maxLatency time.Duration
+    var maxLoss float64
+    var minTLSDays int
+    var savePath string
+    var reportPath string
+    var raw bool
 
     cmd := &cobra.Command{
         Use:   "debug",
         Short: "Performs network diagnostics in a user-friendly manner",
-        Run: func(cmd *cobra.Command, args []string) {
+        // Only --all-resources calls the API, and builds the client then.
+        Annotations: map[string]string{annotationSkipAPI: "true"},
+        // The verdict is reported through the exit code, not as a usage error.
+        SilenceUsage: true,
+        RunE: func(cmd *cobra.Command, args []string) error {
             ctx := cmd.Context()
 
-            // Check if all tools are installed
-            tools := []string{"iftop", "dig", "nslookup", "traceroute", "curl", "ping", "netstat"}
-            missingTools := []string{}
-            for _, tool := range tools {
-                if _, err := exec.LookPath(tool); err != nil {
-                    missingTools = append(missingTools, tool)
+            opts, err := outputOptions(cmd)
+            if err != nil {
+                fmt.Fprintln(os.Stderr, err)
+                return errExitError
+            }
+            multiTarget := targetsFile != "" || allResources
+            if err := validateDebugTargets(domain, targetsFile, allResources, watch); err != nil {
+                fmt.Fprintln(os.Stderr, err)
+                return errExitError
+            }
+            if (savePath != "" || reportPath != "") && (multiTarget || watch) {
+                fmt.Fprintln(os.Stderr, "--save and --report need a single --domain without --watch")
+                return errExitError
+            }
+            var reportFormat string
+            if reportPath != "" {
+                if reportFormat, err = utils.ReportFormat(reportPath); err != nil {
+                    fmt.Fprintln(os.Stderr, err)
+                    return errExitError
                 }
             }
+            if opts.Format == output.FormatCSV && !multiTarget {
+                fmt.Fprintln(os.Stderr, "csv output is only supported by the debug command with --targets-file or --all-resources")
+                return errExitError
+            }
+
+            checks, err := network.SelectChecks(onlyChecks, skipChecks)
+            if err != nil {
+                fmt.Fprintln(os.Stderr, err)
+                return errExitError
+            }
+
+            header, err := network.ParseHeaders(httpHeaders)
+            if err != nil {
+                fmt.Fprintln(os.Stderr, err)
+                return errExitError
+            }
+            statusRanges, err := network.ParseStatusRanges(expectStatus)
+            if err != nil {
+                fmt.Fprintln(os.Stderr, err)
+                return errExitError
+            }
+            traceProtocol, err := network.ParseTraceProtocol(traceProtocol)
+            if err != nil {
+                fmt.Fprintln(os.Stderr, err)
+                return errExitError
+            }
+            pingProtocol, err := network.ParsePingProtocol(pingProtocol)
+            if err != nil {
+                fmt.Fprintln(os.Stderr, err)
+                return errExitError
+            }
+            ports, err := network.ParsePorts(portValues)
+            if err != nil {
+                fmt.Fprintln(os.Stderr, err)
+                return errExitError
+            }
+
+            // Check if the tools needed by the selected checks are installed
+            missingTools := network.MissingTools(checks)
 
             if len(missingTools) > 0 {
-                fmt.Printf("⚠️  The following tools are missing: %s\n", strings.Join(missingTools, ", "))
-                fmt.Println("Please install them to use the network-debug command.")
-                fmt.Println("Installation example on Ubuntu/Debian:")
-                fmt.Printf("  sudo apt install %s\n", strings.Join(missingTools, " "))
-                return
+                fmt.Fprintf(os.Stderr, "⚠️  The following tools are missing: %s\n", strings.Join(missingTools, ", "))
+                fmt.Fprintln(os.Stderr, "Please install them or leave out the checks that need them with --skip.")
+                if slices.Contains(missingTools, "traceroute") {
+                    fmt.Fprintln(os.Stderr, "traceroute is only needed without raw socket access; running as root or with CAP_NET_RAW also works.")
+                }
+                fmt.Fprintln(os.Stderr, "Installation example on Ubuntu/Debian:")
+                fmt.Fprintf(os.Stderr, "  sudo apt install %s\n", strings.Join(missingTools, " "))
+                return errExitError
+            }
+
+            debugOpts := network.DebugOptions{
+                Checks:            checks,
+                DNSServer:         dnsServer,
+                TLSExpiryWarnDays: tlsExpiryWarnDays,
+                Raw:               raw,
+                HTTP: network.HTTPProbeOptions{
+                    URL:                httpURL,
+                    Method:             httpMethod,
+                    Header:             header,
+                    ExpectStatus:       statusRanges,
+                    ExpectBodyContains: expectBody,
+                },
+                Traceroute: network.TracerouteOptions{
+                    Protocol:     traceProtocol,
+                    MaxHops:      maxHops,
+                    ProbesPerHop: probesPerHop,
+                    FirstTTL:     firstTTL,
+                    Port:         tracePort,
+                    Numeric:      traceNumeric,
+                },
+                Ping: network.PingOptions{
+                    Count:    pingCount,
+                    Interval: pingInterval,
+                    Size:     pingSize,
+                    Protocol: pingProtocol,
+                    Port:     pingPort,
+                },
+                Ports: network.PortsOptions{
+                    Ports:   ports,
+                    Timeout: portTimeout,
+                    Banner:  portBanner,
+                },
+                AllSockets:      allSockets,
+                Interface:       iface,
+                InterfaceWindow: interfaceWindow,
+                Thresholds: network.Thresholds{
+                    MaxLatency:     maxLatency,
+                    MaxLossPercent: maxLoss,
+                    MinTLSDays:     minTLSDays,
+                },
+            }
+
+            if multiTarget {
+                targets, err := collectDebugTargets(ctx, resourceUsecase, targetsFile, allResources)
+                if err != nil {
+                    usecase.Logger.Error("Error collecting targets", zap.Error(err))
+                    fmt.Fprintln(os.Stderr, "Error collecting targets:", err)
+                    return errExitError
+                }
+                return verdictExit(runTargets(ctx, usecase, targets, opts, debugOpts, concurrency))
             }
 
-            s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+            if watch {
+                return verdictExit(runWatch(ctx, usecase, domain, opts, debugOpts, network.WatchOptions{
+                    Interval:         watchInterval,
+                    LatencyThreshold: latencyThreshold,
+                }))
+            }
+
+            // The spinner goes to stderr and only when someone is watching, so piped output stays clean
+            s := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(os.Stderr))
             s.Suffix = " Running network diagnostics, it may take a few minutes..."
-            s.Start()
+            if utils.IsTerminal(os.Stderr) {
+                s.Start()
+            }
 
-            result, errorsList := usecase.NetworkDebug(ctx, domain)
+            result, errorsList := usecase.NetworkDebug(ctx, domain, debugOpts)
 
             s.Stop()
 
-            utils.FormatAndDisplayNetworkDebugResult(result, domain)
-
-            // Display errors, if any
-            if len(errorsList) > 0 {
-                errorStyle := lipgloss.NewStyle().
-                    Bold(true).
-                    Foreground(lipgloss.Color("#FF6347")) // Soft red color
-                fmt.Println(errorStyle.Render("⚠️  Some tools encountered errors:"))
-                for _, err := range errorsList {
-                    fmt.Printf("- %v\n", err)
+            if savePath != "" {
+                if err := writeJSONReport(savePath, result); err != nil {
+                    usecase.Logger.Error("Error saving network debug snapshot", zap.Error(err))
+                    fmt.Fprintln(os.Stderr, "Error saving network debug snapshot:", err)
+                    return errExitError
+                }
+            }
+            if reportPath != "" {
+                if err := writeReport(reportPath, result, reportFormat); err != nil {
+                    usecase.Logger.Error("Error writing incident report", zap.Error(err))
+                    fmt.Fprintln(os.Stderr, "Error writing incident report:", err)
+                    return errExitError
                 }
             }
 
-            if len(errorsList) == 0 {
-                successStyle := lipgloss.NewStyle().
-                    Bold(true).
-                    Foreground(lipgloss.Color("#10B981")). // Green
-                    Padding(0, 2)
-                fmt.Println(successStyle.Render("🔧 Network diagnostics executed successfully!"))
+            if !opts.IsHuman() {
+                if err := output.Render(os.Stdout, opts, result, nil); err != nil {
+                    usecase.Logger.Error("Error writing network debug result", zap.Error(err))
+                    fmt.Fprintln(os.Stderr, "Error writing network debug result:", err)
+                    return errExitError
+                }
+                return verdictExit(result.Verdict.Status)
             }
+
+            displayNetworkDebugResult(result, errorsList, domain)
+            return verdictExit(result.Verdict.Status)
         },
     }
 
     cmd.Flags().StringVarP(&domain, "domain", "d", "", "Domain to perform network diagnostics")
-    cmd.MarkFlagRequired("domain")
+    cmd.Flags().StringVar(&targetsFile, "targets-file", "", "File with one domain per line to diagnose instead of --domain")
+    cmd.Flags().BoolVar(&allResources, "all-resources", false, "Diagnose the DNS of every resource in the API instead of --domain")
+    cmd.Flags().IntVar(&concurrency, "concurrency", network.DefaultTargetConcurrency, "Targets diagnosed at the same time with --targets-file or --all-resources")
+    cmd.Flags().StringSliceVar(&onlyChecks, "checks", nil, "Comma-separated checks to run ("+strings.Join(network.CheckNames(), ", ")+")")
+    cmd.Flags().StringSliceVar(&skipChecks, "skip", nil, "Comma-separated checks to leave out")
+    cmd.Flags().StringVar(&dnsServer, "dns-server", "", "DNS server queried by the dns check (default: first nameserver in /etc/resolv.conf)")
+    cmd.Flags().IntVar(&tlsExpiryWarnDays, "tls-expiry-warn", network.DefaultTLSExpiryWarnDays, "Warn when a certificate expires within this many days")
+    cmd.Flags().StringVar(&httpURL, "url", "", "URL requested by the http check, e.g. a health endpoint (default: http://<domain>/)")
+    cmd.Flags().StringVar(&httpMethod, "method", "GET", "HTTP method used by the http check")
+    cmd.Flags().StringArrayVar(&httpHeaders, "header", nil, "Request header for the http check as 'Name: value' (repeatable)")
+    cmd.Flags().StringSliceVar(&expectStatus, "expect-status", nil, "Acceptable status codes for the http check, e.g. 200,301 or 2xx or 200-399")
+    cmd.Flags().StringVar(&expectBody, "expect-body-contains", "", "Fail the http check unless the response body contains this text")
+    cmd.Flags().StringVar(&traceProtocol, "trace-protocol", network.TraceProtocolUDP, "Traceroute probes: udp, icmp or tcp (SYN)")
+    cmd.Flags().IntVar(&maxHops, "max-hops", network.DefaultMaxHops, "Maximum number of hops traceroute probes")
+    cmd.Flags().IntVar(&probesPerHop, "probes-per-hop", network.DefaultProbesPerHop, "Traceroute probes sent to each hop")
+    cmd.Flags().IntVar(&firstTTL, "first-ttl", 1, "TTL of the first traceroute hop")
+    cmd.Flags().IntVar(&tracePort, "trace-port", 0, "Destination port of udp and tcp traceroute probes (default: 33434 for udp, 80 for tcp)")
+    cmd.Flags().BoolVar(&traceNumeric, "trace-numeric", false, "Skip reverse DNS and ASN lookups of traceroute hops")
+    cmd.Flags().StringVar(&pingProtocol, "ping-protocol", network.PingProtocolAuto, "Ping with icmp, tcp or udp; auto uses ICMP and falls back to TCP when ICMP sockets are not permitted")
+    cmd.Flags().IntVar(&pingCount, "ping-count", network.DefaultPingCount, "Number of probes sent by the ping check")
+    cmd.Flags().DurationVar(&pingInterval, "ping-interval", network.DefaultPingInterval, "Delay between ping probes")
+    cmd.Flags().IntVar(&pingSize, "ping-size", network.DefaultPingSize, "Payload size of ICMP and UDP ping probes in bytes")
+    cmd.Flags().IntVar(&pingPort, "ping-port", 0, "Port for tcp and udp ping (default: 443 for tcp, 33434 for udp)")
+    cmd.Flags().StringSliceVar(&portValues, "ports", nil, "Ports probed by the ports check, e.g. 22,443,8000-8010,53/udp (default: 80,443)")
+    cmd.Flags().DurationVar(&portTimeout, "port-timeout", network.DefaultPortTimeout, "How long the ports check waits for each port before calling it filtered")
+    cmd.Flags().BoolVar(&portBanner, "banner", false, "Read the banner of open TCP ports in the ports check")
+    cmd.Flags().BoolVar(&allSockets, "all-sockets", false, "List every socket on this host instead of only those involving the domain's addresses")
+    cmd.Flags().StringVar(&iface, "interface", "", "Network interface sampled by the interface check (default: the default-route interface)")
+    cmd.Flags().DurationVar(&interfaceWindow, "interface-window", network.DefaultInterfaceWindow, "How long the interface check samples traffic counters")
+    cmd.Flags().DurationVar(&maxLatency, "max-latency", 0, "Fail the http check above this response time and the ping check above this average round trip (default: no limit)")
+    cmd.Flags().Float64Var(&maxLoss, "max-loss", 0, "Fail the ping check above this packet loss percentage; lower loss is a warning (default: no limit)")
+    cmd.Flags().IntVar(&minTLSDays, "min-tls-days", network.DefaultMinTLSDays, "Fail the tls check when the certificate expires within this many days")
+    cmd.Flags().BoolVar(&watch, "watch", false, "Re-run the diagnostics every --interval and highlight what changed until interrupted with Ctrl-C")
+    cmd.Flags().DurationVar(&watchInterval, "interval", network.DefaultWatchInterval, "Delay between runs with --watch")
+    cmd.Flags().DurationVar(&latencyThreshold, "latency-threshold", network.DefaultLatencyThreshold, "Latency increase reported as a regression with --watch")
+    cmd.Flags().StringVar(&savePath, "save", "", "Also write the result as JSON to this file, to compare later with 'debug diff'")
+    cmd.Flags().StringVar(&reportPath, "report", "", "Also write an incident report to this .md or .html file")
+    cmd.Flags().BoolVar(&raw, "raw", false, "Keep how each check ran, with the command line, exit code, duration and output of the tools it ran, and show it")
+
+    cmd.AddCommand(newDNSCompareCommand(usecase))
+    cmd.AddCommand(newPathCommand(usecase))
+    cmd.AddCommand(newPortsCommand(usecase))
+    cmd.AddCommand(newDiffCommand(usecase))
+    cmd.AddCommand(newReportCommand(usecase))
 
     return cmd
 }
+
+// displayNetworkDebugResult prints the report of one run followed by its errors and verdict.
+func displayNetworkDebugResult(result *models.NetworkDebugResult, errorsList []error, domain string) {
+    utils.FormatAndDisplayNetworkDebugResult(result, domain)
+
+    // Display errors, if any
+    if len(errorsList) > 0 {
+        errorStyle := lipgloss.NewStyle().
+            Bold(true).
+            Foreground(lipgloss.Color("#FF6347")) // Soft red color
+        fmt.Println(errorStyle.Render("⚠️  Some tools encountered errors:"))
+        for _, err := range errorsList {
+            fmt.Printf("- %v\n", err)
+        }
+        fmt.Println()
+    }
+
+    // Checks that did not pass, with why
+    for _, check := range result.Verdict.Checks {
+        if check.Status == models.VerdictPass {
+            continue
+        }
+        for _, reason := range check.Reasons {
+            fmt.Printf("- %s %s: %s\n", strings.ToUpper(check.Status), check.Check, reason)
+        }
+    }
+    displayVerdictStatus(result.Verdict.Status)
+}
+
+// displayVerdictStatus prints the overall verdict in its colour.
+func displayVerdictStatus(status string) {
+    style := lipgloss.NewStyle().Bold(true).Padding(0, 2)
+    switch status {
+    case models.VerdictFail:
+        fmt.Println(style.Foreground(lipgloss.Color("#FF6347")).Render("❌ Verdict: FAIL")) // Soft red
+    case models.VerdictWarn:
+        fmt.Println(style.Foreground(lipgloss.Color("#F59E0B")).Render("⚠️  Verdict: WARN")) // Amber
+    default:
+        fmt.Println(style.Foreground(lipgloss.Color("#10B981")).Render("✅ Verdict: PASS")) // Green
+    }
+}
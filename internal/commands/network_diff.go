@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/network"
+)
+
+func newDiffCommand(usecase *network.NetworkDebugUsecase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "diff before.json after.json",
+		Short:        "Compare two snapshots saved with debug --save",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := outputOptions(cmd)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+			if opts.Format == output.FormatCSV {
+				fmt.Fprintln(os.Stderr, "csv output is not supported by debug diff")
+				return errExitError
+			}
+
+			before, err := loadSnapshot(args[0])
+			if err != nil {
+				usecase.Logger.Error("Error loading snapshot", zap.String("path", args[0]), zap.Error(err))
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+			after, err := loadSnapshot(args[1])
+			if err != nil {
+				usecase.Logger.Error("Error loading snapshot", zap.String("path", args[1]), zap.Error(err))
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+
+			diff := network.DiffSnapshots(before, after)
+
+			if opts.IsHuman() {
+				titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
+				fmt.Println(titleStyle.Render(fmt.Sprintf("🔀 %s at %s → %s at %s",
+					diff.BeforeDomain, diff.BeforeTimestamp.Local().Format("2006-01-02 15:04:05"),
+					diff.AfterDomain, diff.AfterTimestamp.Local().Format("2006-01-02 15:04:05"))))
+				if len(diff.Changes) == 0 {
+					fmt.Println("- No differences.")
+					return nil
+				}
+				fmt.Println()
+			}
+
+			if err := output.Render(os.Stdout, opts, diff, diffTable(diff)); err != nil {
+				usecase.Logger.Error("Error rendering snapshot diff", zap.Error(err))
+				fmt.Fprintln(os.Stderr, "Error rendering snapshot diff:", err)
+				return errExitError
+			}
+			if opts.IsHuman() {
+				fmt.Println()
+				fmt.Printf("%d differences\n", len(diff.Changes))
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func loadSnapshot(path string) (*models.NetworkDebugResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	snapshot, err := network.LoadSnapshot(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+// diffTable renders one row per change, marked + for added, - for removed and ~ for changed.
+func diffTable(diff *models.SnapshotDiff) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Header: ""},
+			{Header: "Section"},
+			{Header: "Field"},
+			{Header: "Before"},
+			{Header: "After"},
+		},
+	}
+
+	markers := map[string]string{
+		models.ChangeAdded:   "+",
+		models.ChangeRemoved: "-",
+		models.ChangeChanged: "~",
+	}
+	for _, change := range diff.Changes {
+		table.AddRow(markers[change.Kind], change.Section, change.Field, change.Before, change.After)
+	}
+
+	return table
+}
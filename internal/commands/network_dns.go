@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/network"
+)
+
+func newDNSCompareCommand(usecase *network.NetworkDebugUsecase) *cobra.Command {
+	var domain, recordType string
+	var resolvers []string
+	var authoritative bool
+
+	cmd := &cobra.Command{
+		Use:   "dns",
+		Short: "Compare DNS answers across the system, custom and authoritative resolvers",
+		// The verdict is reported through the exit code, not as a usage error.
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			opts, err := outputOptions(cmd)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+
+			result, err := usecase.CompareResolvers(ctx, domain, network.DNSCompareOptions{
+				Resolvers:     resolvers,
+				Authoritative: authoritative,
+				RecordType:    recordType,
+			})
+			if err != nil {
+				usecase.Logger.Error("Error comparing resolvers", zap.Error(err))
+				fmt.Fprintln(os.Stderr, "Error comparing resolvers:", err)
+				return errExitError
+			}
+
+			if err := output.Render(os.Stdout, opts, result, dnsComparisonTable(result)); err != nil {
+				usecase.Logger.Error("Error rendering DNS comparison", zap.Error(err))
+				fmt.Fprintln(os.Stderr, "Error rendering DNS comparison:", err)
+				return errExitError
+			}
+
+			if opts.IsHuman() {
+				displayDNSComparisonIssues(result)
+			}
+			return verdictExit(dnsComparisonStatus(result))
+		},
+	}
+
+	cmd.Flags().StringVarP(&domain, "domain", "d", "", "Domain to look up")
+	cmd.Flags().StringSliceVar(&resolvers, "resolvers", nil, "Comma-separated resolvers to query besides the system ones, e.g. 10.0.0.2,10.0.0.3")
+	cmd.Flags().BoolVar(&authoritative, "authoritative", false, "Also query the authoritative nameservers of the domain's zone")
+	cmd.Flags().StringVarP(&recordType, "type", "t", "A", "Record type to compare")
+	cmd.MarkFlagRequired("domain")
+
+	return cmd
+}
+
+// dnsComparisonTable renders one row per resolver; wide adds latency and the NS host.
+func dnsComparisonTable(result *models.DNSComparisonResult) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Header: "Resolver"},
+			{Header: "Source"},
+			{Header: "NameServer", Wide: true},
+			{Header: "Answers"},
+			{Header: "TTL"},
+			{Header: "Latency", Wide: true},
+			{Header: "Status"},
+		},
+	}
+
+	for _, answer := range result.Resolvers {
+		answers := strings.Join(answer.Answers, ", ")
+		ttl := fmt.Sprintf("%ds", answer.TTL)
+		status := "✅ agrees"
+		switch {
+		case answer.Error != "":
+			answers, ttl, status = "-", "-", "❌ error"
+		case !answer.Agrees:
+			status = "❌ differs"
+		}
+		if len(answer.Answers) == 0 && answer.Error == "" {
+			answers = answer.Rcode
+		}
+		if answer.StaleTTL {
+			status += ", ⚠️ stale TTL"
+		}
+
+		table.AddRow(
+			answer.Resolver,
+			answer.Source,
+			answer.NameServer,
+			answers,
+			ttl,
+			answer.Latency.Round(10*time.Microsecond).String(),
+			status,
+		)
+	}
+
+	return table
+}
+
+// dnsComparisonStatus fails a comparison in which a resolver erred or gave
+// another answer, and warns when the only issue is a stale TTL.
+func dnsComparisonStatus(result *models.DNSComparisonResult) string {
+	if result.Consistent {
+		return models.VerdictPass
+	}
+	for _, answer := range result.Resolvers {
+		if answer.Error != "" || !answer.Agrees {
+			return models.VerdictFail
+		}
+	}
+	return models.VerdictWarn
+}
+
+func displayDNSComparisonIssues(result *models.DNSComparisonResult) {
+	fmt.Println()
+	if result.Consistent {
+		successStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#10B981")) // Green
+		fmt.Println(successStyle.Render(fmt.Sprintf("🔧 All resolvers agree on the %s records of %s.", result.RecordType, result.Domain)))
+	} else {
+		errorStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#FF6347")) // Soft red color
+		fmt.Println(errorStyle.Render("⚠️  Resolvers disagree:"))
+	}
+
+	for _, issue := range result.Issues {
+		fmt.Printf("- %s\n", issue)
+	}
+}
@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/network"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
+)
+
+// clearScreen moves the cursor home and clears the terminal before each redraw.
+const clearScreen = "\033[H\033[2J"
+
+func newPathCommand(usecase *network.NetworkDebugUsecase) *cobra.Command {
+	var domain, traceProtocol, reportFile string
+	var watch, numeric bool
+	var interval time.Duration
+	var count, maxHops, tracePort int
+
+	cmd := &cobra.Command{
+		Use:   "path",
+		Short: "Probe every hop to a domain repeatedly and report loss and latency per hop, like mtr",
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+
+			opts, err := outputOptions(cmd)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return
+			}
+			protocol, err := network.ParseTraceProtocol(traceProtocol)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return
+			}
+
+			// --watch runs until interrupted unless a count was asked for explicitly.
+			rounds := count
+			if watch && !cmd.Flags().Changed("count") {
+				rounds = 0
+			}
+
+			var update func(*models.PathReport)
+			s := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(os.Stderr))
+			if watch && opts.IsHuman() && utils.IsTerminal(os.Stdout) {
+				update = func(report *models.PathReport) {
+					var buf bytes.Buffer
+					buf.WriteString(clearScreen)
+					buf.WriteString(pathHeader(report) + "\n\n")
+					output.Render(&buf, opts, report, pathTable(report))
+					buf.WriteString("\nPress Ctrl-C to stop.\n")
+					os.Stdout.Write(buf.Bytes())
+				}
+			} else if utils.IsTerminal(os.Stderr) {
+				s.Suffix = " Probing the path..."
+				if rounds > 0 {
+					s.Suffix = fmt.Sprintf(" Probing the path for %d rounds...", rounds)
+				}
+				s.Start()
+			}
+
+			report, err := usecase.WatchPath(ctx, domain, network.PathWatchOptions{
+				Trace: network.TracerouteOptions{
+					Protocol: protocol,
+					MaxHops:  maxHops,
+					Port:     tracePort,
+					Numeric:  numeric,
+				},
+				Interval: interval,
+				Rounds:   rounds,
+			}, update)
+			s.Stop()
+			if err != nil {
+				usecase.Logger.Error("Error probing path", zap.Error(err))
+				fmt.Fprintln(os.Stderr, "Error probing path:", err)
+				return
+			}
+
+			if reportFile != "" {
+				if err := writeJSONReport(reportFile, report); err != nil {
+					usecase.Logger.Error("Error writing path report", zap.Error(err))
+					fmt.Fprintln(os.Stderr, "Error writing path report:", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Path report written to %s\n", reportFile)
+				}
+			}
+
+			if opts.IsHuman() {
+				if update != nil {
+					fmt.Print(clearScreen)
+				}
+				titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
+				fmt.Println(titleStyle.Render(pathHeader(report)))
+				fmt.Println()
+			}
+			if err := output.Render(os.Stdout, opts, report, pathTable(report)); err != nil {
+				usecase.Logger.Error("Error rendering path report", zap.Error(err))
+				fmt.Fprintln(os.Stderr, "Error rendering path report:", err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&domain, "domain", "d", "", "Domain whose path to probe")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Keep probing and redraw the table until interrupted with Ctrl-C")
+	cmd.Flags().DurationVar(&interval, "interval", network.DefaultPathInterval, "Delay between rounds of probes")
+	cmd.Flags().IntVar(&count, "count", 10, "Rounds of probes to send (with --watch: until interrupted)")
+	cmd.Flags().StringVar(&reportFile, "report", "", "Write a JSON summary to this file when done or interrupted")
+	cmd.Flags().StringVar(&traceProtocol, "trace-protocol", network.TraceProtocolICMP, "Probes to send: udp, icmp or tcp (SYN)")
+	cmd.Flags().IntVar(&maxHops, "max-hops", network.DefaultMaxHops, "Maximum number of hops to probe")
+	cmd.Flags().IntVar(&tracePort, "trace-port", 0, "Destination port of udp and tcp probes (default: 33434 for udp, 80 for tcp)")
+	cmd.Flags().BoolVar(&numeric, "trace-numeric", false, "Skip reverse DNS lookups of hops")
+	cmd.MarkFlagRequired("domain")
+
+	return cmd
+}
+
+func pathHeader(report *models.PathReport) string {
+	status := "not reached yet"
+	if report.Reached {
+		status = "reached"
+	}
+	return fmt.Sprintf("🛰️  Path to %s (%s) over %s: %d rounds since %s, target %s",
+		report.Domain, report.Target, report.Protocol, report.Rounds, report.StartedAt.Local().Format("15:04:05"), status)
+}
+
+// pathTable lays out one row per hop with the columns mtr users expect.
+func pathTable(report *models.PathReport) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Header: "Hop"},
+			{Header: "Host"},
+			{Header: "Loss%"},
+			{Header: "Sent"},
+			{Header: "Last"},
+			{Header: "Avg"},
+			{Header: "Best"},
+			{Header: "Worst"},
+			{Header: "StdDev"},
+			{Header: "Responders", Wide: true},
+		},
+	}
+
+	round := func(d time.Duration) string {
+		return d.Round(10 * time.Microsecond).String()
+	}
+	for _, hop := range report.Hops {
+		host := "???"
+		if hop.Hostname != "" {
+			host = fmt.Sprintf("%s (%s)", hop.Hostname, hop.Address)
+		} else if hop.Address != "" {
+			host = hop.Address
+		}
+		if hop.Received == 0 {
+			table.AddRow(fmt.Sprint(hop.HopNumber), host, fmt.Sprintf("%.1f", hop.LossPercent), fmt.Sprint(hop.Sent), "-", "-", "-", "-", "-", "-")
+			continue
+		}
+		table.AddRow(
+			fmt.Sprint(hop.HopNumber),
+			host,
+			fmt.Sprintf("%.1f", hop.LossPercent),
+			fmt.Sprint(hop.Sent),
+			round(hop.Last),
+			round(hop.Avg),
+			round(hop.Best),
+			round(hop.Worst),
+			round(hop.StdDev),
+			fmt.Sprint(len(hop.Responders)),
+		)
+	}
+
+	return table
+}
+
+// writeJSONReport writes data to path as indented JSON.
+func writeJSONReport(path string, data any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := output.Render(f, output.Options{Format: output.FormatJSON}, data, nil); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/network"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
+)
+
+func newPortsCommand(usecase *network.NetworkDebugUsecase) *cobra.Command {
+	var domain string
+	var portValues []string
+	var timeout time.Duration
+	var banner bool
+	var concurrency int
+
+	cmd := &cobra.Command{
+		Use:   "ports",
+		Short: "Check which TCP and UDP ports of a domain are open, refused or filtered",
+		// The verdict is reported through the exit code, not as a usage error.
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			opts, err := outputOptions(cmd)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+			ports, err := network.ParsePorts(portValues)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+			checks, err := network.SelectChecks([]string{"ports"}, nil)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+
+			s := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(os.Stderr))
+			s.Suffix = " Probing ports..."
+			if utils.IsTerminal(os.Stderr) {
+				s.Start()
+			}
+			result, errorsList := usecase.NetworkDebug(ctx, domain, network.DebugOptions{
+				Checks: checks,
+				Ports: network.PortsOptions{
+					Ports:       ports,
+					Timeout:     timeout,
+					Banner:      banner,
+					Concurrency: concurrency,
+				},
+			})
+			s.Stop()
+
+			if len(result.Ports.Probes) == 0 {
+				for _, err := range errorsList {
+					usecase.Logger.Error("Error probing ports", zap.Error(err))
+					fmt.Fprintln(os.Stderr, "Error probing ports:", err)
+				}
+				return errExitError
+			}
+
+			if err := output.Render(os.Stdout, opts, result.Ports, portsTable(&result.Ports)); err != nil {
+				usecase.Logger.Error("Error rendering port probes", zap.Error(err))
+				fmt.Fprintln(os.Stderr, "Error rendering port probes:", err)
+				return errExitError
+			}
+
+			if opts.IsHuman() {
+				summaryStyle := lipgloss.NewStyle().Bold(true)
+				fmt.Println()
+				fmt.Println(summaryStyle.Render(fmt.Sprintf("%s: %d open, %d refused, %d filtered",
+					result.Ports.Target, result.Ports.Open, result.Ports.Refused, result.Ports.Filtered)))
+				displayVerdictStatus(result.Verdict.Status)
+			}
+			return verdictExit(result.Verdict.Status)
+		},
+	}
+
+	cmd.Flags().StringVarP(&domain, "domain", "d", "", "Domain whose ports to probe")
+	cmd.Flags().StringSliceVarP(&portValues, "ports", "p", nil, "Ports to probe, e.g. 22,443,5432,8000-8010,53/udp,123/udp (default: 80,443)")
+	cmd.Flags().DurationVar(&timeout, "timeout", network.DefaultPortTimeout, "How long to wait for each port before calling it filtered")
+	cmd.Flags().BoolVar(&banner, "banner", false, "Read the banner of open TCP ports")
+	cmd.Flags().IntVar(&concurrency, "concurrency", network.DefaultPortConcurrency, "Ports probed at the same time")
+	cmd.MarkFlagRequired("domain")
+
+	return cmd
+}
+
+// portsTable renders one row per probed port; wide adds the error of ports that are not open.
+func portsTable(result *models.PortsResult) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Header: "Port"},
+			{Header: "Proto"},
+			{Header: "State"},
+			{Header: "Latency"},
+			{Header: "Banner"},
+			{Header: "Error", Wide: true},
+		},
+	}
+
+	for _, probe := range result.Probes {
+		latency := "-"
+		if probe.State == models.PortOpen || probe.State == models.PortRefused {
+			latency = probe.Latency.Round(10 * time.Microsecond).String()
+		}
+		table.AddRow(fmt.Sprint(probe.Port), probe.Protocol, probe.State, latency, probe.Banner, probe.Error)
+	}
+
+	return table
+}
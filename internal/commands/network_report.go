@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/network"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
+)
+
+func newReportCommand(usecase *network.NetworkDebugUsecase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "report snapshot.json report.md|report.html",
+		Short:        "Write an incident report from a snapshot saved with debug --save",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := utils.ReportFormat(args[1])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+			snapshot, err := loadSnapshot(args[0])
+			if err != nil {
+				usecase.Logger.Error("Error loading snapshot", zap.String("path", args[0]), zap.Error(err))
+				fmt.Fprintln(os.Stderr, err)
+				return errExitError
+			}
+			if err := writeReport(args[1], snapshot, format); err != nil {
+				usecase.Logger.Error("Error writing incident report", zap.Error(err))
+				fmt.Fprintln(os.Stderr, "Error writing incident report:", err)
+				return errExitError
+			}
+			fmt.Printf("Incident report written to %s\n", args[1])
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func writeReport(path string, result *models.NetworkDebugResult, format string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := utils.WriteNetworkDebugReport(f, result, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
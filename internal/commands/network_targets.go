@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/charmbracelet/lipgloss"
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/network"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/resource"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
+)
+
+// validateDebugTargets checks that exactly one source of targets was given.
+func validateDebugTargets(domain, targetsFile string, allResources, watch bool) error {
+	sources := 0
+	for _, given := range []bool{domain != "", targetsFile != "", allResources} {
+		if given {
+			sources++
+		}
+	}
+	switch {
+	case sources == 0:
+		return fmt.Errorf("one of --domain, --targets-file or --all-resources is required")
+	case sources > 1:
+		return fmt.Errorf("--domain, --targets-file and --all-resources cannot be combined")
+	case watch && domain == "":
+		return fmt.Errorf("--watch needs a single --domain")
+	}
+	return nil
+}
+
+// collectDebugTargets reads the targets from the file or from the DNS of every resource.
+func collectDebugTargets(ctx context.Context, resourceUsecase *resource.ResourceUsecase, targetsFile string, allResources bool) ([]string, error) {
+	var targets []string
+	if targetsFile != "" {
+		f, err := os.Open(targetsFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if targets, err = network.ReadTargets(f); err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", targetsFile, err)
+		}
+	}
+
+	if allResources {
+		resources, err := resourceUsecase.ListResources(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var dns []string
+		for _, r := range resources {
+			dns = append(dns, r.Dns)
+		}
+		// ReadTargets drops the empty and repeated DNS entries.
+		targets, _ = network.ReadTargets(strings.NewReader(strings.Join(dns, "\n")))
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no targets to diagnose")
+	}
+	return targets, nil
+}
+
+// runTargets diagnoses every target and prints the summary table, followed by
+// the errors of the targets that failed. It returns the worst verdict.
+func runTargets(ctx context.Context, usecase *network.NetworkDebugUsecase, targets []string, opts output.Options, debugOpts network.DebugOptions, concurrency int) string {
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(os.Stderr))
+	s.Suffix = fmt.Sprintf(" Running network diagnostics on %d targets...", len(targets))
+	if utils.IsTerminal(os.Stderr) {
+		s.Start()
+	}
+	result := usecase.NetworkDebugTargets(ctx, targets, debugOpts, concurrency, func(done, total int) {
+		s.Lock()
+		s.Suffix = fmt.Sprintf(" Running network diagnostics on %d targets (%d done)...", total, done)
+		s.Unlock()
+	})
+	s.Stop()
+
+	status := models.VerdictPass
+	for _, debug := range result.Results {
+		status = models.WorseVerdict(status, debug.Verdict.Status)
+	}
+
+	if err := output.Render(os.Stdout, opts, result, targetsTable(result)); err != nil {
+		usecase.Logger.Error("Error writing network debug results", zap.Error(err))
+		fmt.Fprintln(os.Stderr, "Error writing network debug results:", err)
+		return status
+	}
+	if !opts.IsHuman() {
+		return status
+	}
+
+	if len(result.Summary) < len(targets) {
+		fmt.Printf("\n%d of %d targets were not diagnosed before the run was interrupted.\n", len(targets)-len(result.Summary), len(targets))
+	}
+	displayTargetFailures(result)
+	displayVerdictStatus(status)
+	return status
+}
+
+// targetsTable renders one row per target; wide adds the failed checks.
+func targetsTable(result *models.MultiDebugResult) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Header: "Target"},
+			{Header: "DNS"},
+			{Header: "HTTP"},
+			{Header: "Latency"},
+			{Header: "Loss"},
+			{Header: "TLS Expiry"},
+			{Header: "Verdict"},
+			{Header: "Failed Checks", Wide: true},
+		},
+	}
+
+	for i, summary := range result.Summary {
+		dns := "fail"
+		if summary.DNSOK {
+			dns = "ok"
+		}
+		if debug := result.Results[i]; debug.IsSkipped("dns") && debug.IsSkipped("nslookup") && !summary.DNSOK {
+			dns = "-"
+		}
+
+		httpStatus := "-"
+		if summary.HTTPStatus != 0 {
+			httpStatus = fmt.Sprint(summary.HTTPStatus)
+		}
+
+		latency := "-"
+		if summary.Latency > 0 {
+			latency = summary.Latency.Round(10 * time.Microsecond).String()
+		}
+
+		loss := "-"
+		if result.Results[i].Ping.Sent > 0 {
+			loss = fmt.Sprintf("%.0f%%", summary.LossPercent)
+		}
+
+		expiry := "-"
+		if summary.TLSExpiry != nil {
+			days := int(time.Until(*summary.TLSExpiry).Hours() / 24)
+			expiry = fmt.Sprintf("%s (%dd)", summary.TLSExpiry.Format("2006-01-02"), days)
+		}
+
+		var failedChecks []string
+		for _, toolErr := range summary.Errors {
+			failedChecks = append(failedChecks, toolErr.Tool)
+		}
+
+		table.AddRow(summary.Target, dns, httpStatus, latency, loss, expiry, summary.Verdict, strings.Join(failedChecks, ","))
+	}
+
+	return table
+}
+
+// displayTargetFailures drills down into the targets that did not pass,
+// listing why each of their checks warned or failed.
+func displayTargetFailures(result *models.MultiDebugResult) {
+	var unhealthy []int
+	for i, summary := range result.Summary {
+		if summary.Verdict != models.VerdictPass {
+			unhealthy = append(unhealthy, i)
+		}
+	}
+	fmt.Println()
+	if len(unhealthy) == 0 {
+		return
+	}
+
+	errorStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FF6347")) // Soft red color
+	fmt.Println(errorStyle.Render(fmt.Sprintf("⚠️  %d of %d targets did not pass:", len(unhealthy), len(result.Summary))))
+	for _, i := range unhealthy {
+		fmt.Printf("\n%s\n", lipgloss.NewStyle().Bold(true).Render(result.Summary[i].Target))
+		for _, check := range result.Results[i].Verdict.Checks {
+			if check.Status == models.VerdictPass {
+				continue
+			}
+			for _, reason := range check.Reasons {
+				fmt.Printf("- %s %s: %s\n", strings.ToUpper(check.Status), check.Check, reason)
+			}
+		}
+		fmt.Printf("  Full report: debug -d %s\n", result.Summary[i].Target)
+	}
+	fmt.Println()
+}
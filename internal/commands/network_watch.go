@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/network"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
+)
+
+// runWatch re-runs the diagnostics until ctx is cancelled. Human output
+// redraws the report with the changes since the previous run on top; other
+// formats emit one document per run. The failure summary comes last. It
+// returns the verdict of the last run.
+func runWatch(ctx context.Context, usecase *network.NetworkDebugUsecase, domain string, opts output.Options, debugOpts network.DebugOptions, watch network.WatchOptions) string {
+	redraw := opts.IsHuman() && utils.IsTerminal(os.Stdout)
+	if opts.IsHuman() {
+		fmt.Fprintf(os.Stderr, "Running network diagnostics every %s, press Ctrl-C to stop...\n", watch.Interval)
+	}
+
+	status := models.VerdictPass
+	summary := usecase.WatchNetworkDebug(ctx, domain, debugOpts, watch, func(run models.DebugWatchRun) {
+		status = run.Result.Verdict.Status
+		if !opts.IsHuman() {
+			if err := output.Render(os.Stdout, opts, run, nil); err != nil {
+				usecase.Logger.Error("Error writing network debug result", zap.Error(err))
+				fmt.Fprintln(os.Stderr, "Error writing network debug result:", err)
+			}
+			return
+		}
+
+		if redraw {
+			fmt.Print(clearScreen)
+		}
+		titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
+		fmt.Println(titleStyle.Render(fmt.Sprintf("🔁 Run %d at %s", run.Run, run.Result.Timestamp.Local().Format("15:04:05"))))
+		displayDebugChanges(run)
+		fmt.Println()
+
+		var errorsList []error
+		for _, toolErr := range run.Result.Errors {
+			errorsList = append(errorsList, toolErr)
+		}
+		displayNetworkDebugResult(run.Result, errorsList, domain)
+	})
+
+	if !opts.IsHuman() {
+		if err := output.Render(os.Stdout, opts, summary, nil); err != nil {
+			usecase.Logger.Error("Error writing watch summary", zap.Error(err))
+			fmt.Fprintln(os.Stderr, "Error writing watch summary:", err)
+		}
+		return status
+	}
+	displayWatchSummary(summary, debugOpts.Checks)
+	return status
+}
+
+// displayDebugChanges highlights what changed since the previous run.
+func displayDebugChanges(run models.DebugWatchRun) {
+	if run.Run == 1 {
+		fmt.Println("- First run, changes are reported from the next one.")
+		return
+	}
+	if len(run.Changes) == 0 {
+		fmt.Println("- No changes since the previous run.")
+		return
+	}
+
+	changeStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#F59E0B")) // Amber
+	fmt.Println(changeStyle.Render(fmt.Sprintf("⚡ %d changes since the previous run:", len(run.Changes))))
+	for _, change := range run.Changes {
+		fmt.Println(changeStyle.Render(fmt.Sprintf("- %s: %s", change.Check, change.Message)))
+	}
+}
+
+// displayWatchSummary prints how many runs failed each of the checks that ran.
+func displayWatchSummary(summary *models.DebugWatchSummary, checks []network.Check) {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
+	fmt.Println()
+	fmt.Println(titleStyle.Render(fmt.Sprintf("📋 Watch summary for %s: %d runs in %s",
+		summary.Domain, summary.Runs, summary.EndedAt.Sub(summary.StartedAt).Round(time.Second))))
+	if summary.Runs == 0 {
+		fmt.Println("- No run completed.")
+		return
+	}
+
+	if len(checks) == 0 {
+		checks = network.Checks()
+	}
+	for _, check := range checks {
+		fmt.Printf("- %s: %d of %d runs failed\n", check.Name, summary.Failures[check.Name], summary.Runs)
+	}
+}
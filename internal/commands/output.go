@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
+)
+
+// outputOptions reads the persistent --output flag defined on the root command.
+func outputOptions(cmd *cobra.Command) (output.Options, error) {
+	value, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return output.Options{}, err
+	}
+	return output.Parse(value)
+}
+
+// resourceTable lays out resources for the table, wide and csv formats.
+func resourceTable(resources ...models.Resource) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Header: "ID"},
+			{Header: "Name"},
+			{Header: "DNS"},
+			{Header: "CreatedAt"},
+			{Header: "UpdatedAt"},
+			{Header: "DeletedAt", Wide: true},
+		},
+	}
+
+	for _, resource := range resources {
+		deletedAt := ""
+		if resource.DeletedAt != nil {
+			deletedAt = utils.FormatDate(*resource.DeletedAt)
+		}
+		table.AddRow(
+			strconv.Itoa(resource.ID),
+			resource.Name,
+			resource.Dns,
+			utils.FormatDate(resource.CreatedAt),
+			utils.FormatDate(resource.UpdatedAt),
+			deletedAt,
+		)
+	}
+
+	return table
+}
@@ -2,10 +2,12 @@ package commands
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/output"
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/usecase/resource"
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
 
@@ -16,28 +18,44 @@ func NewUpdateCommand(usecase *resource.ResourceUsecase) *cobra.Command {
     var id, name, dns string
 
     cmd := &cobra.Command{
-        Use:   "update",
-        Short: "Update an existing resource",
-        Run: func(cmd *cobra.Command, args []string) {
+        Use:          "update",
+        Short:        "Update an existing resource",
+        SilenceUsage: true,
+        RunE: func(cmd *cobra.Command, args []string) error {
             ctx := cmd.Context()
 
+            opts, err := outputOptions(cmd)
+            if err != nil {
+                fmt.Fprintln(os.Stderr, err)
+                return errExitError
+            }
+
             idInt, err := utils.ParseID(id)
             if err != nil {
                 usecase.Logger.Error("Invalid ID", zap.Error(err))
-                fmt.Println(err)
-                return
+                fmt.Fprintln(os.Stderr, err)
+                return errExitError
             }
 
             if name == "" && dns == "" {
-                fmt.Println("At least one of 'name' or 'dns' must be provided")
-                return
+                fmt.Fprintln(os.Stderr, "At least one of 'name' or 'dns' must be provided")
+                return errExitError
             }
 
             updatedResource, err := usecase.UpdateResource(ctx, idInt, name, dns)
             if err != nil {
                 usecase.Logger.Error("Error updating resource", zap.Error(err))
-                fmt.Println("Error updating resource:", err)
-                return
+                fmt.Fprintln(os.Stderr, "Error updating resource:", err)
+                return errExitError
+            }
+
+            if opts.Format != output.FormatTable {
+                if err := output.Render(os.Stdout, opts, updatedResource, resourceTable(*updatedResource)); err != nil {
+                    usecase.Logger.Error("Error rendering resource", zap.Error(err))
+                    fmt.Fprintln(os.Stderr, "Error rendering resource:", err)
+                    return errExitError
+                }
+                return nil
             }
 
             successStyle := lipgloss.NewStyle().
@@ -52,6 +70,7 @@ func NewUpdateCommand(usecase *resource.ResourceUsecase) *cobra.Command {
             )
 
             fmt.Println(result)
+            return nil
         },
     }
 
@@ -1,9 +1,14 @@
 package config
 
 import (
-	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -11,25 +16,229 @@ type Config struct {
     APIBaseURL string
     Timeout    time.Duration
     Version    string
+    // RetryMaxAttempts, RetryBackoff and RetryMaxBackoff tune how failed API
+    // calls are retried.
+    RetryMaxAttempts int
+    RetryBackoff     time.Duration
+    RetryMaxBackoff  time.Duration
+
+    // Path is the config file the settings were read from and Profile the
+    // context they were taken from.
+    Path    string
+    Profile string
+    // Settings lists every setting with its value and where it came from.
+    Settings []SettingValue
+}
+
+// EnvPrefix prefixes the environment variable of every setting, e.g.
+// TEEMO_API_BASE_URL. The unprefixed names are still read for compatibility.
+const EnvPrefix = "TEEMO_"
+
+// Setting is a key of the config file, overridable by environment variable
+// and, for some, by flag.
+type Setting struct {
+    Key         string
+    Flag        string
+    Default     string
+    Description string
+    validate    func(value string) error
+}
+
+// SettingValue is the resolved value of a setting and its source: a flag, an
+// environment variable, a context or "default".
+type SettingValue struct {
+    Key    string `json:"key" yaml:"key"`
+    Value  string `json:"value" yaml:"value"`
+    Source string `json:"source" yaml:"source"`
+}
+
+// Settings are the keys a context can set. Bare numbers given as durations
+// are seconds.
+var Settings = []Setting{
+    {Key: "api_base_url", Flag: "api-url", Default: "http://localhost:8080/api/v1", Description: "Base URL of the resource API", validate: validateURL},
+    {Key: "timeout", Flag: "api-timeout", Default: "10s", Description: "Timeout of each API request attempt", validate: validatePositiveDuration},
+    {Key: "retry_max_attempts", Default: "3", Description: "Attempts per API call, including the first", validate: validateAttempts},
+    {Key: "retry_backoff", Default: "200ms", Description: "Delay before the first retry, doubled on every further one", validate: validateDuration},
+    {Key: "retry_max_backoff", Default: "5s", Description: "Longest delay between retries", validate: validateDuration},
+}
+
+// EnvNames returns the environment variables that set s, in precedence order.
+func (s Setting) EnvNames() []string {
+    name := strings.ToUpper(s.Key)
+    return []string{EnvPrefix + name, name}
+}
+
+// LookupSetting returns the setting of key.
+func LookupSetting(key string) (Setting, error) {
+    keys := make([]string, 0, len(Settings))
+    for _, setting := range Settings {
+        if setting.Key == key {
+            return setting, nil
+        }
+        keys = append(keys, setting.Key)
+    }
+    return Setting{}, fmt.Errorf("unknown key %q; valid keys: %s", key, strings.Join(keys, ", "))
 }
 
-func LoadConfig() (*Config, error) {
-    viper.SetDefault("API_BASE_URL", "http://localhost:8080/api/v1")
-    viper.SetDefault("TIMEOUT", 10)
-    viper.SetDefault("VERSION", "v1.0.0")
+// LoadOptions locate the config file and carry the flags that override it.
+type LoadOptions struct {
+    // Path is the --config flag; empty means $TEEMO_CONFIG or DefaultConfigPath.
+    Path string
+    // Profile is the --profile flag; empty means $TEEMO_PROFILE or the
+    // current context of the file.
+    Profile string
+    // Flags holds the flags of Settings, if defined.
+    Flags *pflag.FlagSet
+}
 
-    viper.AutomaticEnv()
+// LoadConfig resolves every setting from, in order of precedence, its flag,
+// its environment variable, the selected context of the config file and its
+// default. Only the default context may be missing from the file. Errors name
+// the offending key and where its value came from.
+func LoadConfig(opts LoadOptions) (*Config, error) {
+    path, err := ConfigPath(opts.Path)
+    if err != nil {
+        return nil, err
+    }
+    file, err := ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    profile := file.Profile(opts.Profile)
+    context, ok := file.Contexts[profile]
+    if !ok && profile != DefaultProfile {
+        return nil, fmt.Errorf("context '%s' not found in %s; create it with config set --profile %s KEY VALUE", profile, path, profile)
+    }
 
-    cfg := &Config{
-        APIBaseURL: viper.GetString("API_BASE_URL"),
-        Timeout:    viper.GetDuration("TIMEOUT") * time.Second,
-        Version:    viper.GetString("VERSION"),
+    v := viper.New()
+    values := make(map[string]any, len(context))
+    for key, value := range context {
+        if _, err := LookupSetting(key); err != nil {
+            return nil, fmt.Errorf("context '%s' in %s: %w", profile, path, err)
+        }
+        values[key] = value
+    }
+    if err := v.MergeConfigMap(values); err != nil {
+        return nil, err
+    }
+    for _, setting := range Settings {
+        v.SetDefault(setting.Key, setting.Default)
+        if err := v.BindEnv(append([]string{setting.Key}, setting.EnvNames()...)...); err != nil {
+            return nil, err
+        }
+        if flag := lookupFlag(opts.Flags, setting.Flag); flag != nil {
+            if err := v.BindPFlag(setting.Key, flag); err != nil {
+                return nil, err
+            }
+        }
     }
 
-    // Validate configurations
-    if cfg.APIBaseURL == "" {
-        return nil, errors.New("API_BASE_URL is required")
+    cfg := &Config{Version: Version(), Path: path, Profile: profile}
+    for _, setting := range Settings {
+        value := v.GetString(setting.Key)
+        source := settingSource(setting, opts.Flags, context, profile)
+        if err := setting.validate(value); err != nil {
+            if source == contextSource(profile) {
+                source += " in " + path
+            }
+            return nil, fmt.Errorf("invalid value %q for %s (from %s): %w", value, setting.Key, source, err)
+        }
+        cfg.Settings = append(cfg.Settings, SettingValue{Key: setting.Key, Value: value, Source: source})
     }
 
+    // Validated above, so the conversions cannot fail
+    cfg.APIBaseURL = v.GetString("api_base_url")
+    cfg.Timeout, _ = parseDuration(v.GetString("timeout"))
+    cfg.RetryMaxAttempts, _ = strconv.Atoi(v.GetString("retry_max_attempts"))
+    cfg.RetryBackoff, _ = parseDuration(v.GetString("retry_backoff"))
+    cfg.RetryMaxBackoff, _ = parseDuration(v.GetString("retry_max_backoff"))
+
     return cfg, nil
 }
+
+// Version is the CLI version reported by --version and the User-Agent,
+// overridable with $TEEMO_VERSION.
+func Version() string {
+    for _, name := range []string{EnvPrefix + "VERSION", "VERSION"} {
+        if version := os.Getenv(name); version != "" {
+            return version
+        }
+    }
+    return "v1.0.0"
+}
+
+func lookupFlag(flags *pflag.FlagSet, name string) *pflag.Flag {
+    if flags == nil || name == "" {
+        return nil
+    }
+    return flags.Lookup(name)
+}
+
+// settingSource mirrors the precedence viper applies, to report where a
+// value came from.
+func settingSource(setting Setting, flags *pflag.FlagSet, context map[string]string, profile string) string {
+    if flag := lookupFlag(flags, setting.Flag); flag != nil && flag.Changed {
+        return "--" + flag.Name
+    }
+    for _, name := range setting.EnvNames() {
+        if os.Getenv(name) != "" {
+            return name
+        }
+    }
+    if _, ok := context[setting.Key]; ok {
+        return contextSource(profile)
+    }
+    return "default"
+}
+
+func contextSource(profile string) string {
+    return fmt.Sprintf("context '%s'", profile)
+}
+
+// parseDuration accepts Go durations such as 1m30s and bare numbers of seconds.
+func parseDuration(value string) (time.Duration, error) {
+    if seconds, err := strconv.Atoi(value); err == nil {
+        return time.Duration(seconds) * time.Second, nil
+    }
+    return time.ParseDuration(value)
+}
+
+func validateURL(value string) error {
+    u, err := url.Parse(value)
+    if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+        return fmt.Errorf("must be an http or https URL")
+    }
+    return nil
+}
+
+func validateDuration(value string) error {
+    d, err := parseDuration(value)
+    if err != nil {
+        return fmt.Errorf("must be a duration such as 30s or a number of seconds")
+    }
+    if d < 0 {
+        return fmt.Errorf("must not be negative")
+    }
+    return nil
+}
+
+func validatePositiveDuration(value string) error {
+    if err := validateDuration(value); err != nil {
+        return err
+    }
+    if d, _ := parseDuration(value); d == 0 {
+        return fmt.Errorf("must be greater than zero")
+    }
+    return nil
+}
+
+func validateAttempts(value string) error {
+    attempts, err := strconv.Atoi(value)
+    if err != nil {
+        return fmt.Errorf("must be a whole number")
+    }
+    if attempts < 1 {
+        return fmt.Errorf("must be at least 1")
+    }
+    return nil
+}
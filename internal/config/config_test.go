@@ -0,0 +1,238 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// clearEnv unsets every variable LoadConfig reads, so the environment of the
+// test run cannot leak into the results.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv(ConfigPathEnv, "")
+	t.Setenv(ProfileEnv, "")
+	for _, setting := range Settings {
+		for _, name := range setting.EnvNames() {
+			t.Setenv(name, "")
+		}
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// settingFlags defines the flags of Settings the way the root command does.
+func settingFlags(t *testing.T, args ...string) *pflag.FlagSet {
+	t.Helper()
+	flags := pflag.NewFlagSet("teemo", pflag.ContinueOnError)
+	for _, setting := range Settings {
+		if setting.Flag != "" {
+			flags.String(setting.Flag, "", setting.Description)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags
+}
+
+const prodConfig = `current-context: prod
+contexts:
+  prod:
+    api_base_url: https://context.example.com/api/v1
+    timeout: "30"
+`
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		flags      []string
+		env        map[string]string
+		wantURL    string
+		wantSource string
+	}{
+		{name: "context", wantURL: "https://context.example.com/api/v1", wantSource: "context 'prod'"},
+		{
+			name:       "unprefixed env over context",
+			env:        map[string]string{"API_BASE_URL": "https://legacy.example.com"},
+			wantURL:    "https://legacy.example.com",
+			wantSource: "API_BASE_URL",
+		},
+		{
+			name:       "prefixed env over unprefixed",
+			env:        map[string]string{"API_BASE_URL": "https://legacy.example.com", "TEEMO_API_BASE_URL": "https://env.example.com"},
+			wantURL:    "https://env.example.com",
+			wantSource: "TEEMO_API_BASE_URL",
+		},
+		{
+			name:       "flag over env",
+			flags:      []string{"--api-url", "https://flag.example.com"},
+			env:        map[string]string{"TEEMO_API_BASE_URL": "https://env.example.com"},
+			wantURL:    "https://flag.example.com",
+			wantSource: "--api-url",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			path := writeConfig(t, prodConfig)
+
+			cfg, err := LoadConfig(LoadOptions{Path: path, Flags: settingFlags(t, tt.flags...)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.APIBaseURL != tt.wantURL {
+				t.Errorf("APIBaseURL = %q, want %q", cfg.APIBaseURL, tt.wantURL)
+			}
+			if got := source(cfg, "api_base_url"); got != tt.wantSource {
+				t.Errorf("source = %q, want %q", got, tt.wantSource)
+			}
+
+			// The other settings come from the context or their default.
+			if cfg.Timeout != 30*time.Second || source(cfg, "timeout") != "context 'prod'" {
+				t.Errorf("timeout = %s from %s, want 30s from the context", cfg.Timeout, source(cfg, "timeout"))
+			}
+			if cfg.RetryMaxAttempts != 3 || source(cfg, "retry_max_attempts") != "default" {
+				t.Errorf("retry_max_attempts = %d from %s, want the default 3", cfg.RetryMaxAttempts, source(cfg, "retry_max_attempts"))
+			}
+			if cfg.Path != path || cfg.Profile != "prod" || len(cfg.Settings) != len(Settings) {
+				t.Errorf("loaded %d settings of context %q from %s", len(cfg.Settings), cfg.Profile, cfg.Path)
+			}
+		})
+	}
+}
+
+func TestLoadConfigProfile(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, prodConfig+`  staging:
+    api_base_url: https://staging.example.com/api/v1
+`)
+
+	cfg, err := LoadConfig(LoadOptions{Path: path, Profile: "staging"})
+	if err != nil || cfg.Profile != "staging" || cfg.APIBaseURL != "https://staging.example.com/api/v1" {
+		t.Fatalf("--profile staging = %+v, %v", cfg, err)
+	}
+
+	t.Setenv(ProfileEnv, "staging")
+	cfg, err = LoadConfig(LoadOptions{Path: path})
+	if err != nil || cfg.Profile != "staging" {
+		t.Fatalf("$%s=staging = %+v, %v", ProfileEnv, cfg, err)
+	}
+
+	// $TEEMO_CONFIG locates the file when --config is not given.
+	t.Setenv(ProfileEnv, "")
+	t.Setenv(ConfigPathEnv, path)
+	cfg, err = LoadConfig(LoadOptions{})
+	if err != nil || cfg.Path != path || cfg.Profile != "prod" {
+		t.Fatalf("$%s = %+v, %v", ConfigPathEnv, cfg, err)
+	}
+}
+
+func TestLoadConfigWithoutFile(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+
+	cfg, err := LoadConfig(LoadOptions{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile != DefaultProfile || cfg.APIBaseURL != "http://localhost:8080/api/v1" || cfg.Timeout != 10*time.Second {
+		t.Errorf("without a file = %+v, want the defaults", cfg)
+	}
+	for _, setting := range cfg.Settings {
+		if setting.Source != "default" {
+			t.Errorf("%s comes from %s, want default", setting.Key, setting.Source)
+		}
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		profile string
+		flags   []string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "missing context",
+			config:  prodConfig,
+			profile: "staging",
+			wantErr: "context 'staging' not found in {path}; create it with config set --profile staging KEY VALUE",
+		},
+		{
+			name:    "missing context without a file",
+			profile: "staging",
+			wantErr: "context 'staging' not found in {path}",
+		},
+		{
+			name:    "unknown key",
+			config:  "contexts:\n  default:\n    api_url: https://api.example.com\n",
+			wantErr: `context 'default' in {path}: unknown key "api_url"; valid keys: api_base_url, timeout, retry_max_attempts, retry_backoff, retry_max_backoff`,
+		},
+		{
+			name:    "invalid value in the context",
+			config:  "contexts:\n  default:\n    timeout: soon\n",
+			wantErr: `invalid value "soon" for timeout (from context 'default' in {path}): must be a duration such as 30s or a number of seconds`,
+		},
+		{
+			name:    "invalid value in the environment",
+			env:     map[string]string{"TEEMO_RETRY_MAX_ATTEMPTS": "0"},
+			wantErr: `invalid value "0" for retry_max_attempts (from TEEMO_RETRY_MAX_ATTEMPTS): must be at least 1`,
+		},
+		{
+			name:    "invalid value in a flag",
+			flags:   []string{"--api-url", "ftp://api.example.com"},
+			wantErr: `invalid value "ftp://api.example.com" for api_base_url (from --api-url): must be an http or https URL`,
+		},
+		{
+			name:    "malformed file",
+			config:  "contexts: [prod",
+			wantErr: "error parsing {path}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if tt.config != "" {
+				path = writeConfig(t, tt.config)
+			}
+
+			_, err := LoadConfig(LoadOptions{Path: path, Profile: tt.profile, Flags: settingFlags(t, tt.flags...)})
+			want := strings.ReplaceAll(tt.wantErr, "{path}", path)
+			if err == nil || !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("error = %v, want %q", err, want)
+			}
+		})
+	}
+}
+
+func source(cfg *Config, key string) string {
+	for _, setting := range cfg.Settings {
+		if setting.Key == key {
+			return setting.Source
+		}
+	}
+	return ""
+}
@@ -0,0 +1,260 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/pkg/client"
+)
+
+// Authentication types stored by login.
+const (
+	AuthBearer = "bearer"
+	AuthAPIKey = "api-key"
+	AuthBasic  = "basic"
+	AuthOAuth2 = "oauth2"
+)
+
+// DefaultProfile is used when no profile is selected.
+const DefaultProfile = "default"
+
+// Credentials are what login stores for one profile. Only the fields of Type
+// are set; Token and TokenExpiry also cache the last OAuth2 access token.
+// APIURL is the API they were stored for, the only one they are sent to.
+type Credentials struct {
+	Type         string    `yaml:"type"`
+	APIURL       string    `yaml:"api_url"`
+	Token        string    `yaml:"token,omitempty"`
+	TokenExpiry  time.Time `yaml:"token_expiry,omitempty"`
+	APIKey       string    `yaml:"api_key,omitempty"`
+	APIKeyHeader string    `yaml:"api_key_header,omitempty"`
+	Username     string    `yaml:"username,omitempty"`
+	Password     string    `yaml:"password,omitempty"`
+	ClientID     string    `yaml:"client_id,omitempty"`
+	ClientSecret string    `yaml:"client_secret,omitempty"`
+	TokenURL     string    `yaml:"token_url,omitempty"`
+	Scopes       []string  `yaml:"scopes,omitempty"`
+}
+
+// Validate checks that the fields Type needs are set.
+func (c Credentials) Validate() error {
+	var missing string
+	switch c.Type {
+	case AuthBearer:
+		if c.Token == "" {
+			missing = "token"
+		}
+	case AuthAPIKey:
+		if c.APIKey == "" {
+			missing = "api key"
+		}
+	case AuthBasic:
+		if c.Username == "" {
+			missing = "username"
+		}
+	case AuthOAuth2:
+		switch {
+		case c.TokenURL == "":
+			missing = "token URL"
+		case c.ClientID == "":
+			missing = "client ID"
+		case c.ClientSecret == "":
+			missing = "client secret"
+		}
+	default:
+		return fmt.Errorf("unsupported authentication type '%s' (valid: %s, %s, %s, %s)", c.Type, AuthBearer, AuthAPIKey, AuthBasic, AuthOAuth2)
+	}
+	if missing != "" {
+		return fmt.Errorf("%s authentication needs a %s", c.Type, missing)
+	}
+	return nil
+}
+
+// CheckAPIURL refuses to send the credentials to an API other than the one
+// they were stored for, so pointing --api-url or a context at another host
+// cannot leak them to it.
+func (c Credentials) CheckAPIURL(apiURL string) error {
+	if c.APIURL == "" {
+		return fmt.Errorf("the credentials are not bound to an API; run login again")
+	}
+	if normalizeAPIURL(c.APIURL) != normalizeAPIURL(apiURL) {
+		return fmt.Errorf("the credentials were stored for %s, not %s; run login again for this API or select another profile", c.APIURL, apiURL)
+	}
+	return nil
+}
+
+// normalizeAPIURL drops what does not change where requests go: the case of
+// the scheme and host, default ports and trailing slashes.
+func normalizeAPIURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil {
+		return value
+	}
+	scheme, host, port := strings.ToLower(u.Scheme), strings.ToLower(u.Hostname()), u.Port()
+	if port != "" && !(scheme == "http" && port == "80" || scheme == "https" && port == "443") {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return scheme + "://" + host + strings.TrimRight(u.EscapedPath(), "/")
+}
+
+// Authenticator builds the client authenticator for the credentials. OAuth2
+// tokens are requested through httpClient and passed to onToken when renewed.
+func (c Credentials) Authenticator(httpClient client.Doer, onToken func(token string, expiry time.Time)) (client.Authenticator, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	switch c.Type {
+	case AuthBearer:
+		return client.BearerToken{Token: c.Token}, nil
+	case AuthAPIKey:
+		return client.APIKey{Header: c.APIKeyHeader, Key: c.APIKey}, nil
+	case AuthBasic:
+		return client.BasicAuth{Username: c.Username, Password: c.Password}, nil
+	}
+	return &client.ClientCredentials{
+		TokenURL:     c.TokenURL,
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		Scopes:       c.Scopes,
+		HTTPClient:   httpClient,
+		OnToken:      onToken,
+		Token:        c.Token,
+		Expiry:       c.TokenExpiry,
+	}, nil
+}
+
+// CredentialStore keeps the credentials of every profile in one YAML file
+// that only its owner can read.
+type CredentialStore struct {
+	Path string
+}
+
+// DefaultCredentialsPath is credentials.yaml in the teemo directory of the
+// user config dir, e.g. ~/.config/teemo/credentials.yaml.
+func DefaultCredentialsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate the user config directory: %w", err)
+	}
+	return filepath.Join(dir, "teemo", "credentials.yaml"), nil
+}
+
+// Load returns the credentials of profile, or nil when it has none.
+func (s *CredentialStore) Load(profile string) (*Credentials, error) {
+	all, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	creds, ok := all[profile]
+	if !ok {
+		return nil, nil
+	}
+	return &creds, nil
+}
+
+// Save stores the credentials of profile, replacing any it had.
+func (s *CredentialStore) Save(profile string, creds Credentials) error {
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	all[profile] = creds
+	return s.write(all)
+}
+
+// SaveToken caches an OAuth2 access token for profile.
+func (s *CredentialStore) SaveToken(profile, token string, expiry time.Time) error {
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	creds, ok := all[profile]
+	if !ok {
+		return nil
+	}
+	creds.Token, creds.TokenExpiry = token, expiry
+	all[profile] = creds
+	return s.write(all)
+}
+
+// Delete removes the credentials of profile and reports whether it had any.
+func (s *CredentialStore) Delete(profile string) (bool, error) {
+	all, err := s.read()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := all[profile]; !ok {
+		return false, nil
+	}
+	delete(all, profile)
+	return true, s.write(all)
+}
+
+func (s *CredentialStore) read() (map[string]Credentials, error) {
+	all := make(map[string]Credentials)
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading credentials: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", s.Path, err)
+	}
+	if all == nil {
+		all = make(map[string]Credentials)
+	}
+	return all, nil
+}
+
+// write replaces the file atomically. The temporary file is created 0600, so
+// the credentials are never readable by others, not even briefly.
+func (s *CredentialStore) write(all map[string]Credentials) error {
+	data, err := yaml.Marshal(all)
+	if err != nil {
+		return fmt.Errorf("error encoding credentials: %w", err)
+	}
+	if err := writeFileAtomic(s.Path, data, 0o600); err != nil {
+		return fmt.Errorf("error writing credentials: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the
+// same directory, so a crash cannot leave it half written. The directory is
+// created 0700 when missing.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/pkg/client"
+)
+
+func TestCredentialStoreWritesOwnerOnlyFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "teemo")
+	store := &CredentialStore{Path: filepath.Join(dir, "credentials.yaml")}
+
+	if err := store.Save("default", Credentials{Type: AuthBearer, Token: "secret", APIURL: "https://api.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	assertMode(t, dir, 0o700|os.ModeDir)
+	assertMode(t, store.Path, 0o600)
+
+	// A file others could read is tightened on the next write.
+	if err := os.Chmod(store.Path, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save("staging", Credentials{Type: AuthAPIKey, APIKey: "key", APIURL: "https://staging.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	assertMode(t, store.Path, 0o600)
+
+	// The temporary file is renamed over the old one, never left behind.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "credentials.yaml" {
+		t.Errorf("directory holds %v, want only credentials.yaml", entries)
+	}
+
+	creds, err := store.Load("default")
+	if err != nil || creds == nil || creds.Token != "secret" || creds.APIURL != "https://api.example.com" {
+		t.Errorf("Load(default) = %+v, %v", creds, err)
+	}
+	creds, err = store.Load("staging")
+	if err != nil || creds == nil || creds.APIKey != "key" {
+		t.Errorf("Load(staging) = %+v, %v", creds, err)
+	}
+}
+
+func TestWriteFileAtomicKeepsOldFileOnFailure(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "credentials.yaml")
+	if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dir, 0o500); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(dir, 0o700)
+
+	if err := writeFileAtomic(path, []byte("new"), 0o600); err == nil {
+		t.Fatal("expected an error writing to a read-only directory")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("file = %q, want the old content", data)
+	}
+}
+
+func TestCredentialStoreLogout(t *testing.T) {
+	store := &CredentialStore{Path: filepath.Join(t.TempDir(), "credentials.yaml")}
+
+	if removed, err := store.Delete("default"); err != nil || removed {
+		t.Errorf("Delete without a file = %v, %v; want false", removed, err)
+	}
+
+	for _, profile := range []string{"default", "staging"} {
+		if err := store.Save(profile, Credentials{Type: AuthBearer, Token: profile, APIURL: "https://api.example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if removed, err := store.Delete("staging"); err != nil || !removed {
+		t.Fatalf("Delete(staging) = %v, %v; want true", removed, err)
+	}
+	if removed, err := store.Delete("staging"); err != nil || removed {
+		t.Errorf("second Delete(staging) = %v, %v; want false", removed, err)
+	}
+
+	if creds, err := store.Load("staging"); err != nil || creds != nil {
+		t.Errorf("Load(staging) after logout = %+v, %v; want nil", creds, err)
+	}
+	if creds, err := store.Load("default"); err != nil || creds == nil || creds.Token != "default" {
+		t.Errorf("Load(default) = %+v, %v; want it untouched", creds, err)
+	}
+	data, err := os.ReadFile(store.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "staging") {
+		t.Errorf("credentials file still mentions staging:\n%s", data)
+	}
+	assertMode(t, store.Path, 0o600)
+}
+
+func TestCredentialStoreSaveToken(t *testing.T) {
+	store := &CredentialStore{Path: filepath.Join(t.TempDir(), "credentials.yaml")}
+	creds := Credentials{Type: AuthOAuth2, APIURL: "https://api.example.com", ClientID: "cli", ClientSecret: "s3cret", TokenURL: "https://auth.example.com/token"}
+	if err := store.Save("prod", creds); err != nil {
+		t.Fatal(err)
+	}
+
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := store.SaveToken("prod", "tok", expiry); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Load("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Token != "tok" || !got.TokenExpiry.Equal(expiry) || got.ClientSecret != "s3cret" || got.APIURL != creds.APIURL {
+		t.Errorf("after SaveToken = %+v", got)
+	}
+
+	// A token renewed after logout does not bring the profile back.
+	if err := store.SaveToken("gone", "tok", expiry); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Load("gone"); got != nil {
+		t.Errorf("SaveToken created profile gone: %+v", got)
+	}
+}
+
+func TestCredentialStoreMalformedFile(t *testing.T) {
+	store := &CredentialStore{Path: filepath.Join(t.TempDir(), "credentials.yaml")}
+	if err := os.WriteFile(store.Path, []byte("default: [not, a, map"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load("default"); err == nil || !strings.Contains(err.Error(), "error parsing "+store.Path) {
+		t.Errorf("error = %v, want a parse error naming the file", err)
+	}
+	if err := store.Save("default", Credentials{Type: AuthBearer, Token: "x"}); err == nil {
+		t.Error("Save overwrote a file it could not parse")
+	}
+}
+
+func TestCredentialsValidate(t *testing.T) {
+	tests := []struct {
+		creds Credentials
+		err   string
+	}{
+		{Credentials{Type: AuthBearer, Token: "t"}, ""},
+		{Credentials{Type: AuthBearer}, "bearer authentication needs a token"},
+		{Credentials{Type: AuthAPIKey}, "api-key authentication needs a api key"},
+		{Credentials{Type: AuthBasic, Username: "admin"}, ""},
+		{Credentials{Type: AuthBasic, Password: "pw"}, "basic authentication needs a username"},
+		{Credentials{Type: AuthOAuth2, ClientID: "cli", ClientSecret: "s"}, "oauth2 authentication needs a token URL"},
+		{Credentials{Type: AuthOAuth2, TokenURL: "https://auth.example.com/token", ClientSecret: "s"}, "oauth2 authentication needs a client ID"},
+		{Credentials{Type: AuthOAuth2, TokenURL: "https://auth.example.com/token", ClientID: "cli"}, "oauth2 authentication needs a client secret"},
+		{Credentials{Type: "digest"}, "unsupported authentication type 'digest'"},
+	}
+
+	for _, tt := range tests {
+		err := tt.creds.Validate()
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.err)) {
+			t.Errorf("Validate(%+v) = %v, want %q", tt.creds, err, tt.err)
+		}
+	}
+}
+
+func TestCredentialsAuthenticator(t *testing.T) {
+	expiry := time.Now().Add(time.Hour)
+	tests := []struct {
+		creds Credentials
+		want  client.Authenticator
+	}{
+		{Credentials{Type: AuthBearer, Token: "t"}, client.BearerToken{Token: "t"}},
+		{Credentials{Type: AuthAPIKey, APIKey: "k", APIKeyHeader: "X-Key"}, client.APIKey{Header: "X-Key", Key: "k"}},
+		{Credentials{Type: AuthBasic, Username: "u", Password: "p"}, client.BasicAuth{Username: "u", Password: "p"}},
+	}
+	for _, tt := range tests {
+		auth, err := tt.creds.Authenticator(nil, nil)
+		if err != nil || auth != tt.want {
+			t.Errorf("Authenticator(%s) = %#v, %v; want %#v", tt.creds.Type, auth, err, tt.want)
+		}
+	}
+
+	// The cached OAuth2 token is handed over, so the next command reuses it.
+	creds := Credentials{Type: AuthOAuth2, TokenURL: "https://auth.example.com/token", ClientID: "cli", ClientSecret: "s", Scopes: []string{"read"}, Token: "cached", TokenExpiry: expiry}
+	auth, err := creds.Authenticator(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oauth, ok := auth.(*client.ClientCredentials)
+	if !ok || oauth.Token != "cached" || !oauth.Expiry.Equal(expiry) || oauth.ClientID != "cli" || len(oauth.Scopes) != 1 {
+		t.Errorf("oauth2 authenticator = %#v", auth)
+	}
+
+	if _, err := (Credentials{Type: AuthBearer}).Authenticator(nil, nil); err == nil {
+		t.Error("expected invalid credentials to be rejected")
+	}
+}
+
+func TestCredentialsCheckAPIURL(t *testing.T) {
+	tests := []struct {
+		stored string
+		apiURL string
+		ok     bool
+	}{
+		{"https://api.example.com/api/v1", "https://api.example.com/api/v1", true},
+		{"https://api.example.com/api/v1", "https://api.example.com/api/v1/", true},
+		{"https://API.example.com/api/v1", "HTTPS://api.example.com/api/v1", true},
+		{"https://api.example.com:443/api/v1", "https://api.example.com/api/v1", true},
+		{"http://localhost:80", "http://localhost", true},
+		{"http://[::1]:80/api", "http://[::1]/api", true},
+		{"http://[::1]:8080/api", "http://[::1]:8080/api/", true},
+		{"https://api.example.com/api/v1", "http://api.example.com/api/v1", false},
+		{"https://api.example.com/api/v1", "https://api.example.com.evil.test/api/v1", false},
+		{"https://api.example.com/api/v1", "https://api.example.com:8443/api/v1", false},
+		{"https://api.example.com/api/v1", "https://api.example.com/api/v2", false},
+		{"https://api.example.com/api/v1", "https://user@evil.test/api/v1", false},
+		{"", "https://api.example.com/api/v1", false},
+	}
+
+	for _, tt := range tests {
+		err := Credentials{Type: AuthBearer, Token: "t", APIURL: tt.stored}.CheckAPIURL(tt.apiURL)
+		if (err == nil) != tt.ok {
+			t.Errorf("stored for %q, sending to %q: %v, want ok=%v", tt.stored, tt.apiURL, err, tt.ok)
+		}
+	}
+}
+
+func assertMode(t *testing.T, path string, want os.FileMode) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode() != want {
+		t.Errorf("%s has mode %s, want %s", path, info.Mode(), want)
+	}
+}
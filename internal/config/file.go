@@ -0,0 +1,134 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables that locate the config file and select the profile
+// when --config and --profile are not given.
+const (
+	ConfigPathEnv = "TEEMO_CONFIG"
+	ProfileEnv    = "TEEMO_PROFILE"
+)
+
+// File is the YAML config file: the settings of each named context, e.g.
+//
+//	current-context: prod
+//	contexts:
+//	  prod:
+//	    api_base_url: https://api.example.com/api/v1
+//	    timeout: 30s
+//
+// A context is the profile of the same name, so it also picks the
+// credentials login stored for it.
+type File struct {
+	CurrentContext string                       `yaml:"current-context,omitempty" json:"current_context,omitempty"`
+	Contexts       map[string]map[string]string `yaml:"contexts,omitempty" json:"contexts,omitempty"`
+}
+
+// DefaultConfigPath is config.yaml in the teemo directory of the user config
+// dir, e.g. ~/.config/teemo/config.yaml ($XDG_CONFIG_HOME is honoured).
+func DefaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate the user config directory: %w", err)
+	}
+	return filepath.Join(dir, "teemo", "config.yaml"), nil
+}
+
+// ConfigPath returns path when set, else $TEEMO_CONFIG, else DefaultConfigPath.
+func ConfigPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	if path := os.Getenv(ConfigPathEnv); path != "" {
+		return path, nil
+	}
+	return DefaultConfigPath()
+}
+
+// ReadFile parses the config file at path. A missing file is an empty one.
+func ReadFile(path string) (*File, error) {
+	file := &File{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// Write saves the file to path atomically.
+func (f *File) Write(path string) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("error encoding config: %w", err)
+	}
+	if err := writeFileAtomic(path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing config: %w", err)
+	}
+	return nil
+}
+
+// Profile returns the profile in use: selected when set (the --profile
+// flag), else $TEEMO_PROFILE, else the current context, else DefaultProfile.
+func (f *File) Profile(selected string) string {
+	for _, profile := range []string{selected, os.Getenv(ProfileEnv), f.CurrentContext} {
+		if profile != "" {
+			return profile
+		}
+	}
+	return DefaultProfile
+}
+
+// ContextNames returns the names of the contexts in the file, sorted.
+func (f *File) ContextNames() []string {
+	names := make([]string, 0, len(f.Contexts))
+	for name := range f.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UseContext makes name the current context. It must exist in the file.
+func (f *File) UseContext(name string) error {
+	if _, ok := f.Contexts[name]; !ok {
+		return fmt.Errorf("context '%s' not found; create it with config set --profile %s <key> <value>", name, name)
+	}
+	f.CurrentContext = name
+	return nil
+}
+
+// Set stores value for key in context, creating the context when missing.
+// Both key and value are validated, so the file cannot hold a setting that
+// would fail to load.
+func (f *File) Set(context, key, value string) error {
+	setting, err := LookupSetting(key)
+	if err != nil {
+		return err
+	}
+	if err := setting.validate(value); err != nil {
+		return fmt.Errorf("invalid value %q for %s: %w", value, key, err)
+	}
+	if f.Contexts == nil {
+		f.Contexts = make(map[string]map[string]string)
+	}
+	if f.Contexts[context] == nil {
+		f.Contexts[context] = make(map[string]string)
+	}
+	f.Contexts[context][key] = value
+	return nil
+}
@@ -1,72 +1,484 @@
 package models
 
+import (
+    "fmt"
+    "time"
+)
+
 type DNSRecord struct {
-    Type string
-    IP   string
+    Name  string `json:"name" yaml:"name"`
+    Type  string `json:"type" yaml:"type"`
+    Class string `json:"class" yaml:"class"`
+    TTL   uint32 `json:"ttl" yaml:"ttl"`
+    // Value is the record data in zone-file presentation, e.g. "10 mail.example.com." for MX.
+    Value string `json:"value" yaml:"value"`
+    // IP is set for A and AAAA records only.
+    IP string `json:"ip,omitempty" yaml:"ip,omitempty"`
+}
+
+// DNSQuery describes one question sent while looking up a domain.
+type DNSQuery struct {
+    Type    string        `json:"type" yaml:"type"`
+    Rcode   string        `json:"rcode,omitempty" yaml:"rcode,omitempty"`
+    Answers int           `json:"answers" yaml:"answers"`
+    Latency time.Duration `json:"latency" yaml:"latency"`
+    Error   string        `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
 type DNSLookupResult struct {
-    Records []DNSRecord
+    // Server is the resolver that answered, as host:port.
+    Server     string      `json:"server" yaml:"server"`
+    Records    []DNSRecord `json:"records" yaml:"records"`
+    CNAMEChain []string    `json:"cname_chain,omitempty" yaml:"cname_chain,omitempty"`
+    Queries    []DNSQuery  `json:"queries" yaml:"queries"`
+}
+
+// ResolverAnswer is one resolver's view of a domain in a DNS comparison.
+type ResolverAnswer struct {
+    // Resolver is the server queried, as host:port.
+    Resolver string `json:"resolver" yaml:"resolver"`
+    // Source is "system", "custom" or "authoritative".
+    Source string `json:"source" yaml:"source"`
+    // NameServer is the NS host name for authoritative servers.
+    NameServer string          `json:"name_server,omitempty" yaml:"name_server,omitempty"`
+    Lookup     DNSLookupResult `json:"lookup" yaml:"lookup"`
+    // Answers are the sorted values of the records owned by the queried name.
+    Answers  []string      `json:"answers" yaml:"answers"`
+    Rcode    string        `json:"rcode,omitempty" yaml:"rcode,omitempty"`
+    TTL      uint32        `json:"ttl" yaml:"ttl"`
+    Latency  time.Duration `json:"latency" yaml:"latency"`
+    Error    string        `json:"error,omitempty" yaml:"error,omitempty"`
+    Agrees   bool          `json:"agrees" yaml:"agrees"`
+    StaleTTL bool          `json:"stale_ttl" yaml:"stale_ttl"`
+}
+
+// DNSComparisonResult compares the answers several resolvers give for one domain.
+type DNSComparisonResult struct {
+    Domain     string `json:"domain" yaml:"domain"`
+    RecordType string `json:"record_type" yaml:"record_type"`
+    // Zone is the zone the authoritative nameservers were found for.
+    Zone string `json:"zone,omitempty" yaml:"zone,omitempty"`
+    // Expected is the answer the others are compared against: the authoritative
+    // consensus when authoritative servers were queried, otherwise the majority.
+    Expected   []string         `json:"expected" yaml:"expected"`
+    Resolvers  []ResolverAnswer `json:"resolvers" yaml:"resolvers"`
+    Consistent bool             `json:"consistent" yaml:"consistent"`
+    Issues     []string         `json:"issues" yaml:"issues"`
 }
 
 type NSLookupResult struct {
-    IP string
+    IP        string   `json:"ip" yaml:"ip"`
+    Addresses []string `json:"addresses" yaml:"addresses"`
+}
+
+// TracerouteProbe is one probe sent to a hop; Address is empty when it timed out.
+type TracerouteProbe struct {
+    Address string        `json:"address,omitempty" yaml:"address,omitempty"`
+    RTT     time.Duration `json:"rtt" yaml:"rtt"`
+    Timeout bool          `json:"timeout" yaml:"timeout"`
+    Error   string        `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// TracerouteResponder is a router that answered probes for a hop. Load-balanced
+// paths can have several per hop.
+type TracerouteResponder struct {
+    Address  string `json:"address" yaml:"address"`
+    Hostname string `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+    ASN      string `json:"asn,omitempty" yaml:"asn,omitempty"`
+    ASName   string `json:"as_name,omitempty" yaml:"as_name,omitempty"`
+    Replies  int    `json:"replies" yaml:"replies"`
 }
 
 type TracerouteHop struct {
-    HopNumber    int
-    Address      string
-    ResponseTime string
+    HopNumber int `json:"hop" yaml:"hop"`
+    // Address, Hostname and ASN describe the first responder; Address is
+    // empty when every probe timed out.
+    Address     string                `json:"address" yaml:"address"`
+    Hostname    string                `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+    ASN         string                `json:"asn,omitempty" yaml:"asn,omitempty"`
+    Responders  []TracerouteResponder `json:"responders" yaml:"responders"`
+    Probes      []TracerouteProbe     `json:"probes" yaml:"probes"`
+    Sent        int                   `json:"sent" yaml:"sent"`
+    Received    int                   `json:"received" yaml:"received"`
+    LossPercent float64               `json:"loss_percent" yaml:"loss_percent"`
+    MinRTT      time.Duration         `json:"min_rtt" yaml:"min_rtt"`
+    AvgRTT      time.Duration         `json:"avg_rtt" yaml:"avg_rtt"`
+    MaxRTT      time.Duration         `json:"max_rtt" yaml:"max_rtt"`
+    // Reached is set when the target itself answered at this hop.
+    Reached bool `json:"reached" yaml:"reached"`
 }
 
 type TracerouteResult struct {
-    Hops []TracerouteHop
+    Target   string          `json:"target" yaml:"target"`
+    Protocol string          `json:"protocol" yaml:"protocol"`
+    Port     int             `json:"port,omitempty" yaml:"port,omitempty"`
+    Reached  bool            `json:"reached" yaml:"reached"`
+    Note     string          `json:"note,omitempty" yaml:"note,omitempty"`
+    Hops     []TracerouteHop `json:"hops" yaml:"hops"`
+}
+
+// PathHop holds the running statistics of one hop while watching a path.
+type PathHop struct {
+    HopNumber   int           `json:"hop" yaml:"hop"`
+    Address     string        `json:"address" yaml:"address"`
+    Hostname    string        `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+    Responders  []string      `json:"responders" yaml:"responders"`
+    Sent        int           `json:"sent" yaml:"sent"`
+    Received    int           `json:"received" yaml:"received"`
+    LossPercent float64       `json:"loss_percent" yaml:"loss_percent"`
+    Last        time.Duration `json:"last" yaml:"last"`
+    Avg         time.Duration `json:"avg" yaml:"avg"`
+    Best        time.Duration `json:"best" yaml:"best"`
+    Worst       time.Duration `json:"worst" yaml:"worst"`
+    StdDev      time.Duration `json:"stddev" yaml:"stddev"`
+}
+
+// PathReport summarises a debug path run.
+type PathReport struct {
+    Domain    string    `json:"domain" yaml:"domain"`
+    Target    string    `json:"target" yaml:"target"`
+    Protocol  string    `json:"protocol" yaml:"protocol"`
+    Port      int       `json:"port,omitempty" yaml:"port,omitempty"`
+    StartedAt time.Time `json:"started_at" yaml:"started_at"`
+    EndedAt   time.Time `json:"ended_at" yaml:"ended_at"`
+    Rounds    int       `json:"rounds" yaml:"rounds"`
+    Reached   bool      `json:"reached" yaml:"reached"`
+    Hops      []PathHop `json:"hops" yaml:"hops"`
+}
+
+// HTTPTimings breaks a request down the way httptrace sees it. TimeToFirstByte
+// is the wait between writing the request and the first response byte; Total
+// covers the whole probe, redirects included.
+type HTTPTimings struct {
+    DNSLookup       time.Duration `json:"dns_lookup" yaml:"dns_lookup"`
+    TCPConnect      time.Duration `json:"tcp_connect" yaml:"tcp_connect"`
+    TLSHandshake    time.Duration `json:"tls_handshake" yaml:"tls_handshake"`
+    TimeToFirstByte time.Duration `json:"time_to_first_byte" yaml:"time_to_first_byte"`
+    Transfer        time.Duration `json:"transfer" yaml:"transfer"`
+    Total           time.Duration `json:"total" yaml:"total"`
+}
+
+type HTTPRedirect struct {
+    URL        string        `json:"url" yaml:"url"`
+    StatusCode int           `json:"status_code" yaml:"status_code"`
+    Location   string        `json:"location" yaml:"location"`
+    Duration   time.Duration `json:"duration" yaml:"duration"`
+}
+
+type HTTPExpectation struct {
+    Description string `json:"description" yaml:"description"`
+    Actual      string `json:"actual,omitempty" yaml:"actual,omitempty"`
+    Passed      bool   `json:"passed" yaml:"passed"`
 }
 
 type HTTPRequestResult struct {
-    Status       string
-    ResponseTime string
-    ContentType  string
+    URL           string            `json:"url" yaml:"url"`
+    Method        string            `json:"method" yaml:"method"`
+    Status        string            `json:"status" yaml:"status"`
+    StatusCode    int               `json:"status_code" yaml:"status_code"`
+    Proto         string            `json:"proto" yaml:"proto"`
+    ContentType   string            `json:"content_type" yaml:"content_type"`
+    ContentLength int64             `json:"content_length" yaml:"content_length"`
+    Timings       HTTPTimings       `json:"timings" yaml:"timings"`
+    Redirects     []HTTPRedirect    `json:"redirects" yaml:"redirects"`
+    Headers       map[string]string `json:"headers" yaml:"headers"`
+    Expectations  []HTTPExpectation `json:"expectations,omitempty" yaml:"expectations,omitempty"`
+}
+
+type TLSCertificate struct {
+    Subject      string    `json:"subject" yaml:"subject"`
+    Issuer       string    `json:"issuer" yaml:"issuer"`
+    SANs         []string  `json:"sans" yaml:"sans"`
+    SerialNumber string    `json:"serial_number" yaml:"serial_number"`
+    NotBefore    time.Time `json:"not_before" yaml:"not_before"`
+    NotAfter     time.Time `json:"not_after" yaml:"not_after"`
+    DaysToExpiry int       `json:"days_to_expiry" yaml:"days_to_expiry"`
+    IsCA         bool      `json:"is_ca" yaml:"is_ca"`
+}
+
+type TLSResult struct {
+    Address       string        `json:"address" yaml:"address"`
+    ServerName    string        `json:"server_name" yaml:"server_name"`
+    Version       string        `json:"version" yaml:"version"`
+    CipherSuite   string        `json:"cipher_suite" yaml:"cipher_suite"`
+    ALPN          string        `json:"alpn" yaml:"alpn"`
+    HandshakeTime time.Duration `json:"handshake_time" yaml:"handshake_time"`
+    // Chain is the certificate chain as presented by the server, leaf first.
+    Chain            []TLSCertificate `json:"chain" yaml:"chain"`
+    HostnameVerified bool             `json:"hostname_verified" yaml:"hostname_verified"`
+    ChainVerified    bool             `json:"chain_verified" yaml:"chain_verified"`
+    VerifyError      string           `json:"verify_error,omitempty" yaml:"verify_error,omitempty"`
+    OCSPStapled      bool             `json:"ocsp_stapled" yaml:"ocsp_stapled"`
+    ExpiryWarnDays   int              `json:"expiry_warn_days" yaml:"expiry_warn_days"`
+    Warnings         []string         `json:"warnings" yaml:"warnings"`
+}
+
+type PingProbe struct {
+    Seq     int           `json:"seq" yaml:"seq"`
+    Success bool          `json:"success" yaml:"success"`
+    RTT     time.Duration `json:"rtt" yaml:"rtt"`
+    Error   string        `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
 type PingResult struct {
-    Sent        int
-    Received    int
-    Lost        int
-    LossPercent float64
-    AvgLatency  int
+    Target      string        `json:"target" yaml:"target"`
+    Protocol    string        `json:"protocol" yaml:"protocol"`
+    Port        int           `json:"port,omitempty" yaml:"port,omitempty"`
+    Note        string        `json:"note,omitempty" yaml:"note,omitempty"`
+    Sent        int           `json:"sent" yaml:"sent"`
+    Received    int           `json:"received" yaml:"received"`
+    Lost        int           `json:"lost" yaml:"lost"`
+    LossPercent float64       `json:"loss_percent" yaml:"loss_percent"`
+    MinLatency  time.Duration `json:"min_latency" yaml:"min_latency"`
+    AvgLatency  time.Duration `json:"avg_latency" yaml:"avg_latency"`
+    MaxLatency  time.Duration `json:"max_latency" yaml:"max_latency"`
+    StdDev      time.Duration `json:"stddev" yaml:"stddev"`
+    Jitter      time.Duration `json:"jitter" yaml:"jitter"`
+    Probes      []PingProbe   `json:"probes" yaml:"probes"`
+}
+
+// Port states reported by the ports check.
+const (
+    PortOpen     = "open"
+    PortRefused  = "refused"
+    PortFiltered = "filtered"
+    // PortOpenFiltered is a UDP port that neither answered nor was refused,
+    // which is what both a silent service and a firewall look like.
+    PortOpenFiltered = "open|filtered"
+    PortError        = "error"
+)
+
+type PortProbe struct {
+    Port     int           `json:"port" yaml:"port"`
+    Protocol string        `json:"protocol" yaml:"protocol"`
+    State    string        `json:"state" yaml:"state"`
+    Latency  time.Duration `json:"latency" yaml:"latency"`
+    Banner   string        `json:"banner,omitempty" yaml:"banner,omitempty"`
+    Error    string        `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type PortsResult struct {
+    Target   string      `json:"target" yaml:"target"`
+    Open     int         `json:"open" yaml:"open"`
+    Refused  int         `json:"refused" yaml:"refused"`
+    Filtered int         `json:"filtered" yaml:"filtered"`
+    Probes   []PortProbe `json:"probes" yaml:"probes"`
 }
 
 type NetstatConnection struct {
-    Protocol      string
-    LocalAddress  string
-    RemoteAddress string
-    Status        string
+    Protocol      string `json:"protocol" yaml:"protocol"`
+    LocalAddress  string `json:"local_address" yaml:"local_address"`
+    RemoteAddress string `json:"remote_address" yaml:"remote_address"`
+    Status        string `json:"status" yaml:"status"`
+    PID           int    `json:"pid,omitempty" yaml:"pid,omitempty"`
+    Process       string `json:"process,omitempty" yaml:"process,omitempty"`
 }
 
 type NetstatResult struct {
-    Connections []NetstatConnection
+    // Filter holds the target addresses connections were matched against;
+    // empty when every socket was listed.
+    Filter      []string            `json:"filter" yaml:"filter"`
+    Connections []NetstatConnection `json:"connections" yaml:"connections"`
+    // States counts the listed connections per state.
+    States map[string]int `json:"states" yaml:"states"`
+    // Total is the number of sockets on the host before filtering.
+    Total int `json:"total" yaml:"total"`
+}
+
+type InterfaceCounters struct {
+    RxBytes   uint64 `json:"rx_bytes" yaml:"rx_bytes"`
+    RxPackets uint64 `json:"rx_packets" yaml:"rx_packets"`
+    RxErrors  uint64 `json:"rx_errors" yaml:"rx_errors"`
+    RxDropped uint64 `json:"rx_dropped" yaml:"rx_dropped"`
+    TxBytes   uint64 `json:"tx_bytes" yaml:"tx_bytes"`
+    TxPackets uint64 `json:"tx_packets" yaml:"tx_packets"`
+    TxErrors  uint64 `json:"tx_errors" yaml:"tx_errors"`
+    TxDropped uint64 `json:"tx_dropped" yaml:"tx_dropped"`
+}
+
+type InterfaceResult struct {
+    Name         string `json:"name" yaml:"name"`
+    AutoDetected bool   `json:"auto_detected" yaml:"auto_detected"`
+    OperState    string `json:"oper_state" yaml:"oper_state"`
+    MAC          string `json:"mac" yaml:"mac"`
+    MTU          int    `json:"mtu" yaml:"mtu"`
+    SpeedMbps    int    `json:"speed_mbps,omitempty" yaml:"speed_mbps,omitempty"`
+    // Window is how long the counters were sampled.
+    Window          time.Duration `json:"window" yaml:"window"`
+    RxBytesPerSec   float64       `json:"rx_bytes_per_sec" yaml:"rx_bytes_per_sec"`
+    TxBytesPerSec   float64       `json:"tx_bytes_per_sec" yaml:"tx_bytes_per_sec"`
+    RxPacketsPerSec float64       `json:"rx_packets_per_sec" yaml:"rx_packets_per_sec"`
+    TxPacketsPerSec float64       `json:"tx_packets_per_sec" yaml:"tx_packets_per_sec"`
+    // Delta holds the counter increase during the window, Totals the counters since boot.
+    Delta  InterfaceCounters `json:"delta" yaml:"delta"`
+    Totals InterfaceCounters `json:"totals" yaml:"totals"`
+}
+
+// ToolError records a diagnostic tool that failed during a network debug run.
+type ToolError struct {
+    Tool    string `json:"tool" yaml:"tool"`
+    Message string `json:"message" yaml:"message"`
+}
+
+func (e ToolError) Error() string {
+    return fmt.Sprintf("%s error: %s", e.Tool, e.Message)
+}
+
+// HostInfo describes the machine the diagnostics ran from.
+type HostInfo struct {
+    Hostname string `json:"hostname" yaml:"hostname"`
+    OS       string `json:"os" yaml:"os"`
+    Arch     string `json:"arch" yaml:"arch"`
+    // Kernel is the kernel release, when procfs is available.
+    Kernel string `json:"kernel,omitempty" yaml:"kernel,omitempty"`
+    // Addresses are the non-loopback addresses of the host's interfaces, in CIDR notation.
+    Addresses []string `json:"addresses" yaml:"addresses"`
 }
 
-type IftopConnection struct {
-    Source        string
-    Destination   string
-    SentKBps      string
-    ReceivedKBps  string
+// RawCommand is an external tool run by a check, with everything it printed.
+type RawCommand struct {
+    Command  string        `json:"command" yaml:"command"`
+    Stdout   string        `json:"stdout" yaml:"stdout"`
+    Stderr   string        `json:"stderr" yaml:"stderr"`
+    ExitCode int           `json:"exit_code" yaml:"exit_code"`
+    Duration time.Duration `json:"duration" yaml:"duration"`
 }
 
-type IftopResult struct {
-    SendingKBps    string
-    ReceivingKBps  string
-    TopConnections []IftopConnection
+// CheckRun records how one check ran. Built-in checks that speak the
+// protocols themselves run no commands.
+type CheckRun struct {
+    Check    string        `json:"check" yaml:"check"`
+    Duration time.Duration `json:"duration" yaml:"duration"`
+    Error    string        `json:"error,omitempty" yaml:"error,omitempty"`
+    Commands []RawCommand  `json:"commands" yaml:"commands"`
 }
 
 type NetworkDebugResult struct {
-    DNSLookup   DNSLookupResult
-    NSLookup    NSLookupResult
-    Traceroute  TracerouteResult
-    HTTPRequest HTTPRequestResult
-    Ping        PingResult
-    Netstat     NetstatResult
-    Iftop       IftopResult
+    Domain      string            `json:"domain" yaml:"domain"`
+    Timestamp   time.Time         `json:"timestamp" yaml:"timestamp"`
+    Host        HostInfo          `json:"host" yaml:"host"`
+    DNSLookup   DNSLookupResult   `json:"dns_lookup" yaml:"dns_lookup"`
+    NSLookup    NSLookupResult    `json:"ns_lookup" yaml:"ns_lookup"`
+    Traceroute  TracerouteResult  `json:"traceroute" yaml:"traceroute"`
+    HTTPRequest HTTPRequestResult `json:"http_request" yaml:"http_request"`
+    TLS         TLSResult         `json:"tls" yaml:"tls"`
+    Ping        PingResult        `json:"ping" yaml:"ping"`
+    Ports       PortsResult       `json:"ports" yaml:"ports"`
+    Netstat     NetstatResult     `json:"netstat" yaml:"netstat"`
+    Interface   InterfaceResult   `json:"interface" yaml:"interface"`
+    Skipped     []string          `json:"skipped" yaml:"skipped"`
+    Errors      []ToolError       `json:"errors" yaml:"errors"`
+    Verdict     Verdict           `json:"verdict" yaml:"verdict"`
+    // Raw is kept only when requested, in check order.
+    Raw []CheckRun `json:"raw,omitempty" yaml:"raw,omitempty"`
+}
+
+// Verdict statuses, from best to worst.
+const (
+    VerdictPass = "pass"
+    VerdictWarn = "warn"
+    VerdictFail = "fail"
+)
+
+// CheckVerdict is the health of one check; Reasons explain a warn or fail.
+type CheckVerdict struct {
+    Check   string   `json:"check" yaml:"check"`
+    Status  string   `json:"status" yaml:"status"`
+    Reasons []string `json:"reasons" yaml:"reasons"`
+}
+
+// Verdict rates a diagnosis; Status is the worst status of its checks.
+type Verdict struct {
+    Status string         `json:"status" yaml:"status"`
+    Checks []CheckVerdict `json:"checks" yaml:"checks"`
+}
+
+// WorseVerdict returns the worse of two verdict statuses.
+func WorseVerdict(a, b string) string {
+    rank := map[string]int{VerdictPass: 0, VerdictWarn: 1, VerdictFail: 2}
+    if rank[b] > rank[a] {
+        return b
+    }
+    return a
+}
+
+// IsSkipped reports whether the named check was left out of the run.
+func (r *NetworkDebugResult) IsSkipped(check string) bool {
+    for _, name := range r.Skipped {
+        if name == check {
+            return true
+        }
+    }
+    return false
+}
+
+// DebugChange is a difference between two consecutive runs of a watched diagnosis.
+type DebugChange struct {
+    Check   string `json:"check" yaml:"check"`
+    Message string `json:"message" yaml:"message"`
+}
+
+// DebugWatchRun is one run of debug --watch along with what changed since the previous one.
+type DebugWatchRun struct {
+    Run     int                 `json:"run" yaml:"run"`
+    Result  *NetworkDebugResult `json:"result" yaml:"result"`
+    Changes []DebugChange       `json:"changes" yaml:"changes"`
+}
+
+// DebugWatchSummary counts, per check, how many runs of debug --watch it failed.
+type DebugWatchSummary struct {
+    Domain    string         `json:"domain" yaml:"domain"`
+    StartedAt time.Time      `json:"started_at" yaml:"started_at"`
+    EndedAt   time.Time      `json:"ended_at" yaml:"ended_at"`
+    Runs      int            `json:"runs" yaml:"runs"`
+    Failures  map[string]int `json:"failures" yaml:"failures"`
+}
+
+// TargetSummary is the one-line outcome of debugging a target among many.
+type TargetSummary struct {
+    Target     string `json:"target" yaml:"target"`
+    DNSOK      bool   `json:"dns_ok" yaml:"dns_ok"`
+    HTTPStatus int    `json:"http_status,omitempty" yaml:"http_status,omitempty"`
+    // Latency is the total HTTP response time, or the average ping round trip
+    // when the http check did not run.
+    Latency     time.Duration `json:"latency" yaml:"latency"`
+    LossPercent float64       `json:"loss_percent" yaml:"loss_percent"`
+    // TLSExpiry is when the leaf certificate expires; nil when no certificate was seen.
+    TLSExpiry *time.Time `json:"tls_expiry,omitempty" yaml:"tls_expiry,omitempty"`
+    Failed    bool       `json:"failed" yaml:"failed"`
+    Errors    []ToolError `json:"errors" yaml:"errors"`
+    Verdict   string      `json:"verdict" yaml:"verdict"`
+}
+
+// MultiDebugResult holds the diagnostics of several targets, in the order given.
+type MultiDebugResult struct {
+    Timestamp time.Time             `json:"timestamp" yaml:"timestamp"`
+    Summary   []TargetSummary       `json:"summary" yaml:"summary"`
+    Results   []*NetworkDebugResult `json:"results" yaml:"results"`
+}
+
+// Kinds of SnapshotChange.
+const (
+    ChangeAdded   = "added"
+    ChangeRemoved = "removed"
+    ChangeChanged = "changed"
+)
+
+// SnapshotChange is one field that differs between two saved diagnoses.
+// Before is empty for added values and After for removed ones.
+type SnapshotChange struct {
+    Section string `json:"section" yaml:"section"`
+    Field   string `json:"field" yaml:"field"`
+    Kind    string `json:"kind" yaml:"kind"`
+    Before  string `json:"before,omitempty" yaml:"before,omitempty"`
+    After   string `json:"after,omitempty" yaml:"after,omitempty"`
+}
+
+// SnapshotDiff compares two NetworkDebugResult snapshots field by field.
+type SnapshotDiff struct {
+    BeforeDomain    string           `json:"before_domain" yaml:"before_domain"`
+    BeforeTimestamp time.Time        `json:"before_timestamp" yaml:"before_timestamp"`
+    AfterDomain     string           `json:"after_domain" yaml:"after_domain"`
+    AfterTimestamp  time.Time        `json:"after_timestamp" yaml:"after_timestamp"`
+    Changes         []SnapshotChange `json:"changes" yaml:"changes"`
 }
@@ -1,12 +1,12 @@
 package models
 
 type Resource struct {
-    ID        int     `json:"ID"`
-    Name      string  `json:"name"`
-    Dns       string  `json:"dns"`
-    CreatedAt string  `json:"CreatedAt"`
-    UpdatedAt string  `json:"UpdatedAt"`
-    DeletedAt *string `json:"DeletedAt,omitempty"`
+    ID        int     `json:"ID" yaml:"ID"`
+    Name      string  `json:"name" yaml:"name"`
+    Dns       string  `json:"dns" yaml:"dns"`
+    CreatedAt string  `json:"CreatedAt" yaml:"CreatedAt"`
+    UpdatedAt string  `json:"UpdatedAt" yaml:"UpdatedAt"`
+    DeletedAt *string `json:"DeletedAt,omitempty" yaml:"DeletedAt,omitempty"`
 }
 
 type CreateRequest struct {
@@ -0,0 +1,459 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The jsonpath format supports the subset of kubectl-style expressions that
+// field selection needs:
+//
+//	{.name}                   a field of the root object
+//	{[*].dns}                 a field of every element of a list
+//	{.items[0].name}          indexing
+//	{[?(@.dns=="a.com")].ID}  filtering a list with ==, !=, <, <=, > or >=
+//	{[?(@.DeletedAt)].name}   filtering on a field being set
+//	{range [*]}{.name}{"\n"}{end}
+//
+// Text outside braces is copied verbatim and multiple matches of one
+// expression are separated by spaces. Wildcards over an object visit its
+// fields in key order.
+
+type jsonPathNode struct {
+	literal  string
+	path     []pathStep
+	isRange  bool
+	children []jsonPathNode
+}
+
+type pathStep struct {
+	field    string
+	index    int
+	wildcard bool
+	isIndex  bool
+	filter   *pathFilter
+}
+
+// pathFilter keeps the list elements whose path compares to value with op.
+// Without op the elements where path is set, and not null or false, are kept.
+type pathFilter struct {
+	path  []pathStep
+	op    string
+	value any
+}
+
+func writeJSONPath(w io.Writer, expression string, data any) error {
+	nodes, err := parseJSONPath(expression)
+	if err != nil {
+		return fmt.Errorf("error parsing jsonpath '%s': %w", expression, err)
+	}
+
+	generic, err := toGeneric(data)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	if err := evalJSONPath(&sb, nodes, generic); err != nil {
+		return fmt.Errorf("error evaluating jsonpath '%s': %w", expression, err)
+	}
+
+	out := sb.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err = io.WriteString(w, out)
+	return err
+}
+
+func parseJSONPath(expression string) ([]jsonPathNode, error) {
+	nodes, _, closed, err := parseJSONPathNodes(expression)
+	if err != nil {
+		return nil, err
+	}
+	if closed {
+		return nil, fmt.Errorf("unexpected {end}")
+	}
+	return nodes, nil
+}
+
+// parseJSONPathNodes parses until the end of input or the next {end}. It
+// reports whether an {end} was consumed and returns the input that follows it.
+func parseJSONPathNodes(input string) ([]jsonPathNode, string, bool, error) {
+	var nodes []jsonPathNode
+
+	for input != "" {
+		open := strings.Index(input, "{")
+		if open < 0 {
+			nodes = append(nodes, jsonPathNode{literal: input})
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, jsonPathNode{literal: input[:open]})
+		}
+
+		closing := strings.Index(input[open:], "}")
+		if closing < 0 {
+			return nil, "", false, fmt.Errorf("unclosed '{'")
+		}
+		expr := strings.TrimSpace(input[open+1 : open+closing])
+		input = input[open+closing+1:]
+
+		switch {
+		case expr == "end":
+			return nodes, input, true, nil
+		case strings.HasPrefix(expr, "range "):
+			path, err := parsePath(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, "", false, err
+			}
+			children, rest, closed, err := parseJSONPathNodes(input)
+			if err != nil {
+				return nil, "", false, err
+			}
+			if !closed {
+				return nil, "", false, fmt.Errorf("{range} without {end}")
+			}
+			input = rest
+			nodes = append(nodes, jsonPathNode{path: path, isRange: true, children: children})
+		case strings.HasPrefix(expr, `"`):
+			literal, err := strconv.Unquote(expr)
+			if err != nil {
+				return nil, "", false, fmt.Errorf("invalid string literal %s", expr)
+			}
+			nodes = append(nodes, jsonPathNode{literal: literal})
+		default:
+			path, err := parsePath(expr)
+			if err != nil {
+				return nil, "", false, err
+			}
+			nodes = append(nodes, jsonPathNode{path: path})
+		}
+	}
+
+	return nodes, "", false, nil
+}
+
+func parsePath(expr string) ([]pathStep, error) {
+	expr = strings.TrimPrefix(expr, "$")
+	// Never nil, so "{.}" is told apart from literal text.
+	steps := []pathStep{}
+
+	for expr != "" {
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+			end := strings.IndexAny(expr, ".[")
+			if end < 0 {
+				end = len(expr)
+			}
+			name := expr[:end]
+			expr = expr[end:]
+			switch name {
+			case "":
+				// A bare "." refers to the current value.
+			case "*":
+				steps = append(steps, pathStep{wildcard: true})
+			default:
+				steps = append(steps, pathStep{field: name})
+			}
+		case '[':
+			end := strings.Index(expr, "]")
+			if strings.HasPrefix(expr, "[?(") {
+				// The filter value may contain ']'
+				if end = strings.Index(expr, ")]"); end >= 0 {
+					end++
+				}
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed '[' in %s", expr)
+			}
+			inner := strings.TrimSpace(expr[1:end])
+			expr = expr[end+1:]
+			switch {
+			case strings.HasPrefix(inner, "?"):
+				filter, err := parseFilter(inner)
+				if err != nil {
+					return nil, err
+				}
+				steps = append(steps, pathStep{filter: filter})
+			case inner == "*":
+				steps = append(steps, pathStep{wildcard: true})
+			case strings.HasPrefix(inner, "'") || strings.HasPrefix(inner, `"`):
+				steps = append(steps, pathStep{field: strings.Trim(inner, `'"`)})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index [%s]", inner)
+				}
+				steps = append(steps, pathStep{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("unexpected '%c' in path, expressions start with '.' or '['", expr[0])
+		}
+	}
+
+	return steps, nil
+}
+
+// parseFilter parses "?(@.path)" or "?(@.path op value)", where value is a
+// quoted string, a number, true, false or null.
+func parseFilter(inner string) (*pathFilter, error) {
+	if !strings.HasPrefix(inner, "?(") || !strings.HasSuffix(inner, ")") {
+		return nil, fmt.Errorf("invalid filter [%s], expected [?(@.field op value)]", inner)
+	}
+	condition := strings.TrimSpace(inner[2 : len(inner)-1])
+
+	left, op, right := condition, "", ""
+	if i := strings.IndexAny(condition, "=!<>"); i >= 0 {
+		left, op = strings.TrimSpace(condition[:i]), condition[i:i+1]
+		if i+1 < len(condition) && condition[i+1] == '=' {
+			op += "="
+		}
+		right = strings.TrimSpace(condition[i+len(op):])
+		switch op {
+		case "==", "!=", "<", "<=", ">", ">=":
+		default:
+			return nil, fmt.Errorf("invalid operator '%s' in filter [%s]", op, inner)
+		}
+	}
+
+	if !strings.HasPrefix(left, "@") {
+		return nil, fmt.Errorf("filter [%s] must start with @", inner)
+	}
+	path, err := parsePath(left[1:])
+	if err != nil {
+		return nil, err
+	}
+	filter := &pathFilter{path: path, op: op}
+	if op == "" {
+		return filter, nil
+	}
+
+	switch {
+	case right == "":
+		return nil, fmt.Errorf("missing value in filter [%s]", inner)
+	case right[0] == '\'' || right[0] == '"':
+		if len(right) < 2 || right[len(right)-1] != right[0] {
+			return nil, fmt.Errorf("unterminated string in filter [%s]", inner)
+		}
+		filter.value = right[1 : len(right)-1]
+	case right == "true" || right == "false":
+		filter.value = right == "true"
+	case right == "null":
+		filter.value = nil
+	default:
+		number, err := strconv.ParseFloat(right, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %s in filter [%s]", right, inner)
+		}
+		filter.value = number
+	}
+	return filter, nil
+}
+
+// matches reports whether item passes the filter. Values of different types
+// are only ever unequal.
+func (f *pathFilter) matches(item any) bool {
+	values, err := selectPath(item, f.path)
+	if err != nil || len(values) == 0 {
+		return false
+	}
+	value := values[0]
+
+	if f.op == "" {
+		return value != nil && value != false
+	}
+
+	var cmp int
+	switch v := value.(type) {
+	case float64:
+		want, ok := f.value.(float64)
+		if !ok {
+			return f.op == "!="
+		}
+		cmp = compareOrdered(v, want)
+	case string:
+		want, ok := f.value.(string)
+		if !ok {
+			return f.op == "!="
+		}
+		cmp = strings.Compare(v, want)
+	default:
+		equal := value == f.value
+		switch f.op {
+		case "==":
+			return equal
+		case "!=":
+			return !equal
+		}
+		return false
+	}
+
+	switch f.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	}
+	return cmp >= 0
+}
+
+func compareOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func evalJSONPath(sb *strings.Builder, nodes []jsonPathNode, data any) error {
+	for _, node := range nodes {
+		if node.path == nil && !node.isRange {
+			sb.WriteString(node.literal)
+			continue
+		}
+
+		values, err := selectPath(data, node.path)
+		if err != nil {
+			return err
+		}
+
+		if node.isRange {
+			// Ranging over a path that names a list visits its elements,
+			// as {range .items} does in kubectl.
+			if len(values) == 1 && !selectsMany(node.path) {
+				if list, ok := values[0].([]any); ok {
+					values = list
+				}
+			}
+			for _, value := range values {
+				if err := evalJSONPath(sb, node.children, value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		for i, value := range values {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			text, err := formatJSONPathValue(value)
+			if err != nil {
+				return err
+			}
+			sb.WriteString(text)
+		}
+	}
+	return nil
+}
+
+// selectsMany reports whether the last step of path can match several values.
+func selectsMany(path []pathStep) bool {
+	if len(path) == 0 {
+		return false
+	}
+	last := path[len(path)-1]
+	return last.wildcard || last.filter != nil
+}
+
+func selectPath(data any, steps []pathStep) ([]any, error) {
+	current := []any{data}
+
+	for _, step := range steps {
+		var next []any
+		for _, value := range current {
+			switch v := value.(type) {
+			case map[string]any:
+				if step.filter != nil {
+					return nil, fmt.Errorf("cannot filter an object, filters apply to lists")
+				}
+				if step.wildcard {
+					keys := make([]string, 0, len(v))
+					for key := range v {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, v[key])
+					}
+					continue
+				}
+				if step.isIndex {
+					return nil, fmt.Errorf("cannot index an object with [%d]", step.index)
+				}
+				item, ok := v[step.field]
+				if !ok {
+					return nil, fmt.Errorf("field '%s' not found", step.field)
+				}
+				next = append(next, item)
+			case []any:
+				switch {
+				case step.filter != nil:
+					for _, item := range v {
+						if step.filter.matches(item) {
+							next = append(next, item)
+						}
+					}
+				case step.wildcard:
+					next = append(next, v...)
+				case step.isIndex:
+					index := step.index
+					if index < 0 {
+						index += len(v)
+					}
+					if index < 0 || index >= len(v) {
+						return nil, fmt.Errorf("index [%d] out of range", step.index)
+					}
+					next = append(next, v[index])
+				default:
+					// Selecting a field on a list applies it to every element.
+					for _, item := range v {
+						if obj, ok := item.(map[string]any); ok {
+							if field, ok := obj[step.field]; ok {
+								next = append(next, field)
+							}
+						}
+					}
+				}
+			default:
+				return nil, fmt.Errorf("cannot select into scalar value %v", v)
+			}
+		}
+		current = next
+	}
+
+	return current, nil
+}
+
+func formatJSONPathValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case map[string]any, []any:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(raw), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
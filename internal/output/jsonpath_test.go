@@ -0,0 +1,150 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type jsonPathResource struct {
+	ID        int     `json:"ID"`
+	Name      string  `json:"name"`
+	Dns       string  `json:"dns"`
+	DeletedAt *string `json:"DeletedAt,omitempty"`
+}
+
+func jsonPathData() []jsonPathResource {
+	deleted := "2024-10-01T00:00:00Z"
+	return []jsonPathResource{
+		{ID: 1, Name: "api", Dns: "api.example.com"},
+		{ID: 2, Name: "web", Dns: "web.example.com", DeletedAt: &deleted},
+		{ID: 3, Name: "db", Dns: "db.example.com"},
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		data       any
+		want       string
+	}{
+		{"field", "{.name}", jsonPathData()[0], "api\n"},
+		{"root", "{.}", "plain", "plain\n"},
+		{"dollar root", "{$.ID}", jsonPathData()[0], "1\n"},
+		{"literal text", "name={.name}!", jsonPathData()[0], "name=api!\n"},
+		{"list wildcard", "{[*].dns}", jsonPathData(), "api.example.com web.example.com db.example.com\n"},
+		{"field on a list", "{.name}", jsonPathData(), "api web db\n"},
+		{"dot wildcard", "{.*}", map[string]any{"b": 2, "a": 1, "c": 3}, "1 2 3\n"},
+		{"object wildcard in key order", "{[*]}", map[string]any{"z": "last", "a": "first"}, "first last\n"},
+		{"index", "{[1].name}", jsonPathData(), "web\n"},
+		{"negative index", "{[-1].name}", jsonPathData(), "db\n"},
+		{"quoted field", "{['name']}", jsonPathData()[0], "api\n"},
+		{"nested object", "{.a.b}", map[string]any{"a": map[string]any{"b": []int{1, 2}}}, "[1,2]\n"},
+		{"null", "{.a}", map[string]any{"a": nil}, "\n"},
+		{"range", `{range [*]}{.ID}{"\t"}{.name}{"\n"}{end}`, jsonPathData(), "1\tapi\n2\tweb\n3\tdb\n"},
+		{"nested range", `{range [*]}{range .tags}{.}{","}{end}{"|"}{end}`, []map[string]any{{"tags": []string{"a", "b"}}, {"tags": []string{"c"}}}, "a,b,|c,|\n"},
+		{"filter string equal", `{[?(@.dns=="web.example.com")].ID}`, jsonPathData(), "2\n"},
+		{"filter single quotes", `{[?(@.name=='db')].dns}`, jsonPathData(), "db.example.com\n"},
+		{"filter not equal", `{[?(@.name != "web")].name}`, jsonPathData(), "api db\n"},
+		{"filter number", `{[?(@.ID>=2)].name}`, jsonPathData(), "web db\n"},
+		{"filter less than", `{[?(@.ID<2)].name}`, jsonPathData(), "api\n"},
+		{"filter existence", `{[?(@.DeletedAt)].name}`, jsonPathData(), "web\n"},
+		{"filter bool", `{[?(@.up==true)].n}`, []map[string]any{{"n": "a", "up": true}, {"n": "b", "up": false}}, "a\n"},
+		{"filter null", `{[?(@.v==null)].n}`, []map[string]any{{"n": "a", "v": nil}, {"n": "b", "v": 1}}, "a\n"},
+		{"filter type mismatch", `{[?(@.ID=="1")].name}`, jsonPathData(), "\n"},
+		{"filter value with bracket", `{[?(@.name=="a]b")].ID}`, []map[string]any{{"ID": 9, "name": "a]b"}}, "9\n"},
+		{"filter current value", `{[?(@ > 1)]}`, []int{1, 2, 3}, "2 3\n"},
+		{"filter no match", `{[?(@.name=="nope")].ID}`, jsonPathData(), "\n"},
+		{"filter in range", `{range [?(@.ID!=2)]}{.name}{"\n"}{end}`, jsonPathData(), "api\ndb\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeJSONPath(&buf, tt.expression, tt.data); err != nil {
+				t.Fatalf("writeJSONPath(%q) error: %v", tt.expression, err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("writeJSONPath(%q) = %q, want %q", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPathErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		err        string
+	}{
+		{"index out of range", "{[3].name}", "index [3] out of range"},
+		{"negative index out of range", "{[-4].name}", "index [-4] out of range"},
+		{"missing field", "{[0].owner}", "field 'owner' not found"},
+		{"index into object", "{[0][1]}", "cannot index an object with [1]"},
+		{"select into scalar", "{[0].name.first}", "cannot select into scalar value api"},
+		{"filter on object", `{[0][?(@.ID==1)]}`, "cannot filter an object"},
+		{"unclosed brace", "{.name", "unclosed '{'"},
+		{"unclosed bracket", "{[0.name}", "unclosed '['"},
+		{"invalid index", "{[x]}", "invalid index [x]"},
+		{"missing dot", "{name}", "unexpected 'n' in path"},
+		{"range without end", "{range [*]}{.name}", "{range} without {end}"},
+		{"end without range", "{.name}{end}", "unexpected {end}"},
+		{"invalid string literal", `{"\q"}`, "invalid string literal"},
+		{"filter without @", `{[?(.ID==1)]}`, "must start with @"},
+		{"filter without parentheses", `{[?@.ID==1]}`, "invalid filter"},
+		{"filter bad operator", `{[?(@.ID=1)]}`, "invalid operator '='"},
+		{"filter bang", `{[?(@.ID!1)]}`, "invalid operator '!'"},
+		{"filter missing value", `{[?(@.ID==)]}`, "missing value"},
+		{"filter unterminated string", `{[?(@.name=="api)]}`, "unterminated string"},
+		{"filter bad value", `{[?(@.name==api)]}`, "invalid value api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := writeJSONPath(&buf, tt.expression, jsonPathData())
+			if err == nil {
+				t.Fatalf("writeJSONPath(%q) = %q, want error containing %q", tt.expression, buf.String(), tt.err)
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("writeJSONPath(%q) error = %q, want it to contain %q", tt.expression, err, tt.err)
+			}
+		})
+	}
+}
+
+// Every prefix of these expressions is malformed in some way; none may panic.
+func TestJSONPathTruncatedExpressionsDoNotPanic(t *testing.T) {
+	expressions := []string{
+		`{range [?(@.ID>=2)]}{.name}{"\t"}{['dns']}{"\n"}{end}`,
+		`{$[-1].DeletedAt}{[*].*}{[?(@.name=="a]b")]}`,
+		`{[?(@)]}{[?(@ != null)]}{[?(@.x<='z')]}`,
+	}
+	for _, expression := range expressions {
+		for i := 0; i <= len(expression); i++ {
+			jsonPathNoPanic(t, expression[:i])
+			jsonPathNoPanic(t, expression[i:])
+		}
+	}
+}
+
+func FuzzJSONPath(f *testing.F) {
+	for _, seed := range []string{"{.name}", "{[*].dns}", `{range [*]}{.ID}{end}`, `{[?(@.ID>1)].name}`, "{[", "{[?(", "{}"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, expression string) {
+		jsonPathNoPanic(t, expression)
+	})
+}
+
+func jsonPathNoPanic(t *testing.T, expression string) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("writeJSONPath(%q) panicked: %v", expression, r)
+		}
+	}()
+	var buf bytes.Buffer
+	_ = writeJSONPath(&buf, expression, jsonPathData())
+}
@@ -0,0 +1,160 @@
+// Package output renders command results in the formats selected with --output.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatTable    Format = "table"
+	FormatWide     Format = "wide"
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatCSV      Format = "csv"
+	FormatTemplate Format = "go-template"
+	FormatJSONPath Format = "jsonpath"
+)
+
+// FlagUsage is the help text shared by every command that accepts --output.
+const FlagUsage = "Output format: table, wide, json, yaml, csv, go-template=<template> or jsonpath=<expression>"
+
+// Options is a parsed --output value.
+type Options struct {
+	Format Format
+	// Expression holds the template or jsonpath for the go-template and jsonpath formats.
+	Expression string
+}
+
+// Parse validates the value passed to --output.
+func Parse(value string) (Options, error) {
+	name, expression, _ := strings.Cut(strings.TrimSpace(value), "=")
+
+	switch format := Format(strings.ToLower(name)); format {
+	case "", "text", FormatTable:
+		return Options{Format: FormatTable}, nil
+	case FormatWide, FormatJSON, FormatYAML, FormatCSV:
+		return Options{Format: format}, nil
+	case FormatTemplate, "template", FormatJSONPath:
+		if format == "template" {
+			format = FormatTemplate
+		}
+		if expression == "" {
+			return Options{}, fmt.Errorf("output format '%s' requires an expression, e.g. %s=<expression>", format, format)
+		}
+		return Options{Format: format, Expression: expression}, nil
+	default:
+		return Options{}, fmt.Errorf("unsupported output format '%s' (valid: table, wide, json, yaml, csv, go-template=..., jsonpath=...)", value)
+	}
+}
+
+// IsHuman reports whether the format is meant for people rather than scripts.
+func (o Options) IsHuman() bool {
+	return o.Format == FormatTable || o.Format == FormatWide
+}
+
+// Render writes data to w. Table, wide and csv output are laid out from table;
+// every other format is derived from data itself, so field names in templates
+// and jsonpath expressions are the JSON field names.
+func Render(w io.Writer, opts Options, data any, table *Table) error {
+	switch opts.Format {
+	case FormatTable, FormatWide, FormatCSV:
+		if table == nil {
+			return fmt.Errorf("output format '%s' is not supported here", opts.Format)
+		}
+		if opts.Format == FormatCSV {
+			return table.writeCSV(w)
+		}
+		return table.write(w, opts.Format == FormatWide)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(data); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case FormatTemplate:
+		return writeTemplate(w, opts.Expression, data)
+	case FormatJSONPath:
+		return writeJSONPath(w, opts.Expression, data)
+	default:
+		return fmt.Errorf("unsupported output format '%s'", opts.Format)
+	}
+}
+
+func writeTemplate(w io.Writer, text string, data any) error {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("error parsing template: %w", err)
+	}
+
+	generic, err := toGeneric(data)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, generic); err != nil {
+		return fmt.Errorf("error executing template: %w", err)
+	}
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func (t *Table) writeCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	columns := t.visibleColumns(true)
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Header
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range t.Rows {
+		record := make([]string, len(columns))
+		for i, col := range columns {
+			record[i] = cell(row, col.index)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// toGeneric round-trips data through JSON so templates and jsonpath see the
+// same field names and shapes as the json output.
+func toGeneric(data any) (any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling JSON: %w", err)
+	}
+
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, fmt.Errorf("error decoding JSON: %w", err)
+	}
+	return generic, nil
+}
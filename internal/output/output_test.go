@@ -0,0 +1,117 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Options
+		wantErr string
+	}{
+		{value: "", want: Options{Format: FormatTable}},
+		{value: "text", want: Options{Format: FormatTable}},
+		{value: "table", want: Options{Format: FormatTable}},
+		{value: " JSON ", want: Options{Format: FormatJSON}},
+		{value: "wide", want: Options{Format: FormatWide}},
+		{value: "yaml", want: Options{Format: FormatYAML}},
+		{value: "csv", want: Options{Format: FormatCSV}},
+		{value: "go-template={{.name}}", want: Options{Format: FormatTemplate, Expression: "{{.name}}"}},
+		{value: "template={{.name}}", want: Options{Format: FormatTemplate, Expression: "{{.name}}"}},
+		{value: "jsonpath={[?(@.ID==1)].name}", want: Options{Format: FormatJSONPath, Expression: "{[?(@.ID==1)].name}"}},
+		{value: "jsonpath", wantErr: "output format 'jsonpath' requires an expression, e.g. jsonpath=<expression>"},
+		{value: "template=", wantErr: "output format 'go-template' requires an expression, e.g. go-template=<expression>"},
+		{value: "xml", wantErr: "unsupported output format 'xml' (valid: table, wide, json, yaml, csv, go-template=..., jsonpath=...)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Parse(%q) = %+v, %v; want %+v", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func testTable() *Table {
+	table := &Table{Columns: []Column{{Header: "ID"}, {Header: "Name"}, {Header: "Note", Wide: true}}}
+	table.AddRow("1", "api", "primary")
+	table.AddRow("10", "a-much-longer-name")
+	table.AddRow("3", "名前", `say "hi", then leave`)
+	return table
+}
+
+func TestTableColumnWidths(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, Options{Format: FormatTable}, nil, testTable()); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want a header and 3 rows:\n%s", len(lines), buf.String())
+	}
+
+	// Every column is as wide as its widest cell, wide characters counting
+	// twice, so the Name column starts at the same offset on every line and
+	// the long name is not truncated.
+	want := []string{
+		" ID Name               ",
+		" 1  api                ",
+		" 10 a-much-longer-name ",
+		" 3  名前               ",
+	}
+	for i, line := range lines {
+		if line != want[i] {
+			t.Errorf("line %d = %q, want %q", i, line, want[i])
+		}
+	}
+	if strings.Contains(buf.String(), "Note") {
+		t.Error("table shows the wide column")
+	}
+
+	buf.Reset()
+	if err := Render(&buf, Options{Format: FormatWide}, nil, testTable()); err != nil {
+		t.Fatal(err)
+	}
+	if header := strings.SplitN(buf.String(), "\n", 2)[0]; header != " ID Name               Note"+strings.Repeat(" ", 17) {
+		t.Errorf("wide header = %q", header)
+	}
+}
+
+func TestRenderCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, Options{Format: FormatCSV}, nil, testTable()); err != nil {
+		t.Fatal(err)
+	}
+	// csv includes the wide columns and quotes cells with commas and quotes.
+	want := "ID,Name,Note\n" +
+		"1,api,primary\n" +
+		"10,a-much-longer-name,\n" +
+		"3,名前,\"say \"\"hi\"\", then leave\"\n"
+	if buf.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestRenderWithoutTable(t *testing.T) {
+	var buf bytes.Buffer
+	err := Render(&buf, Options{Format: FormatCSV}, map[string]string{"a": "b"}, nil)
+	if err == nil || err.Error() != "output format 'csv' is not supported here" {
+		t.Errorf("error = %v", err)
+	}
+
+	buf.Reset()
+	if err := Render(&buf, Options{Format: FormatTemplate, Expression: "{{.a}}"}, map[string]string{"a": "b"}, nil); err != nil || buf.String() != "b\n" {
+		t.Errorf("template = %q, %v", buf.String(), err)
+	}
+}
@@ -0,0 +1,101 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Column describes one table column. Wide columns only appear with -o wide and csv.
+type Column struct {
+	Header string
+	Wide   bool
+
+	index int
+}
+
+// Table is the tabular view of a result used by the table, wide and csv formats.
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+}
+
+var (
+	headerStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#FAFAFA")).
+			Background(lipgloss.Color("#7D56F4")).
+			Padding(0, 1)
+
+	rowStyle = lipgloss.NewStyle().
+			Padding(0, 1)
+)
+
+// AddRow appends a row; cells are matched to Columns by position.
+func (t *Table) AddRow(cells ...string) {
+	t.Rows = append(t.Rows, cells)
+}
+
+func (t *Table) visibleColumns(wide bool) []Column {
+	var columns []Column
+	for i, col := range t.Columns {
+		if col.Wide && !wide {
+			continue
+		}
+		col.index = i
+		columns = append(columns, col)
+	}
+	return columns
+}
+
+// write renders the table with column widths computed from the data, so long
+// values such as DNS names are never truncated.
+func (t *Table) write(w io.Writer, wide bool) error {
+	columns := t.visibleColumns(wide)
+
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		widths[i] = lipgloss.Width(col.Header)
+		for _, row := range t.Rows {
+			if width := lipgloss.Width(cell(row, col.index)); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = pad(col.Header, widths[i])
+	}
+	if _, err := fmt.Fprintln(w, headerStyle.Render(strings.Join(header, " "))); err != nil {
+		return err
+	}
+
+	for _, row := range t.Rows {
+		cells := make([]string, len(columns))
+		for i, col := range columns {
+			cells[i] = pad(cell(row, col.index), widths[i])
+		}
+		if _, err := fmt.Fprintln(w, rowStyle.Render(strings.Join(cells, " "))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func cell(row []string, index int) string {
+	if index < len(row) {
+		return row[index]
+	}
+	return ""
+}
+
+func pad(value string, width int) string {
+	if gap := width - lipgloss.Width(value); gap > 0 {
+		return value + strings.Repeat(" ", gap)
+	}
+	return value
+}
@@ -0,0 +1,228 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// Check is a named diagnostic that can be selected with --checks and --skip.
+type Check struct {
+	Name        string
+	Aliases     []string
+	Description string
+	// Tools lists the external binaries the check needs on PATH.
+	Tools []string
+	// needsTools, when set, reports whether Tools are needed on this host;
+	// checks that only fall back to a binary set it.
+	needsTools func() bool
+
+	// run performs the check and returns a function that stores its outcome
+	// in the shared result; NetworkDebug calls it while holding the lock. The
+	// function may accompany an error when there is a partial result to keep.
+	run func(ctx context.Context, u *NetworkDebugUsecase, domain string, opts DebugOptions) (func(*models.NetworkDebugResult), error)
+}
+
+// registry holds every known check in the order they appear in the report.
+var registry = []Check{
+	{
+		Name:        "dns",
+		Aliases:     []string{"dig"},
+		Description: "DNS records, TTLs and CNAME chain for the domain",
+		run: func(ctx context.Context, u *NetworkDebugUsecase, domain string, opts DebugOptions) (func(*models.NetworkDebugResult), error) {
+			dns, err := u.runDNS(ctx, domain, opts.DNSServer)
+			if err != nil {
+				return nil, err
+			}
+			return func(r *models.NetworkDebugResult) { r.DNSLookup = dns }, nil
+		},
+	},
+	{
+		Name:        "nslookup",
+		Description: "Addresses the system resolver returns",
+		run: func(ctx context.Context, u *NetworkDebugUsecase, domain string, opts DebugOptions) (func(*models.NetworkDebugResult), error) {
+			ns, err := u.runNSLookup(ctx, domain)
+			if err != nil {
+				return nil, err
+			}
+			return func(r *models.NetworkDebugResult) { r.NSLookup = ns }, nil
+		},
+	},
+	{
+		Name:        "traceroute",
+		Description: "Route packets take to the domain, with per-hop loss and latency",
+		// The binary is the fallback when raw sockets are not permitted.
+		Tools:      []string{"traceroute"},
+		needsTools: rawSocketsDenied,
+		run: func(ctx context.Context, u *NetworkDebugUsecase, domain string, opts DebugOptions) (func(*models.NetworkDebugResult), error) {
+			tr, err := u.runTraceroute(ctx, domain, opts.Traceroute)
+			if err != nil {
+				return nil, err
+			}
+			return func(r *models.NetworkDebugResult) { r.Traceroute = tr }, nil
+		},
+	},
+	{
+		Name:        "http",
+		Aliases:     []string{"curl"},
+		Description: "HTTP status, timing breakdown and redirect chain",
+		run: func(ctx context.Context, u *NetworkDebugUsecase, domain string, opts DebugOptions) (func(*models.NetworkDebugResult), error) {
+			httpResult, err := u.runHTTP(ctx, domain, opts.HTTP)
+			if err != nil && httpResult.StatusCode == 0 {
+				return nil, err
+			}
+			return func(r *models.NetworkDebugResult) { r.HTTPRequest = httpResult }, err
+		},
+	},
+	{
+		Name:        "tls",
+		Description: "TLS handshake and certificate chain",
+		run: func(ctx context.Context, u *NetworkDebugUsecase, domain string, opts DebugOptions) (func(*models.NetworkDebugResult), error) {
+			tlsResult, err := u.runTLS(ctx, domain, opts.TLSExpiryWarnDays)
+			if err != nil {
+				return nil, err
+			}
+			return func(r *models.NetworkDebugResult) { r.TLS = tlsResult }, nil
+		},
+	},
+	{
+		Name:        "ping",
+		Description: "ICMP (or TCP/UDP) reachability, latency and jitter",
+		run: func(ctx context.Context, u *NetworkDebugUsecase, domain string, opts DebugOptions) (func(*models.NetworkDebugResult), error) {
+			ping, err := u.runPing(ctx, domain, opts.Ping)
+			if err != nil && ping.Sent == 0 {
+				return nil, err
+			}
+			return func(r *models.NetworkDebugResult) { r.Ping = ping }, err
+		},
+	},
+	{
+		Name:        "ports",
+		Description: "TCP and UDP port reachability, latency and banners",
+		run: func(ctx context.Context, u *NetworkDebugUsecase, domain string, opts DebugOptions) (func(*models.NetworkDebugResult), error) {
+			ports, err := u.runPorts(ctx, domain, opts.Ports)
+			if err != nil && len(ports.Probes) == 0 {
+				return nil, err
+			}
+			return func(r *models.NetworkDebugResult) { r.Ports = ports }, err
+		},
+	},
+	{
+		Name:        "netstat",
+		Aliases:     []string{"sockets"},
+		Description: "Sockets on this host that involve the target",
+		run: func(ctx context.Context, u *NetworkDebugUsecase, domain string, opts DebugOptions) (func(*models.NetworkDebugResult), error) {
+			netstat, err := u.runSockets(ctx, domain, opts.AllSockets)
+			if err != nil {
+				return nil, err
+			}
+			return func(r *models.NetworkDebugResult) { r.Netstat = netstat }, nil
+		},
+	},
+	{
+		Name:        "interface",
+		Aliases:     []string{"iftop"},
+		Description: "Throughput, errors and drops on the network interface",
+		run: func(ctx context.Context, u *NetworkDebugUsecase, domain string, opts DebugOptions) (func(*models.NetworkDebugResult), error) {
+			iface, err := u.runInterface(ctx, opts.Interface, opts.InterfaceWindow)
+			if err != nil {
+				return nil, err
+			}
+			return func(r *models.NetworkDebugResult) { r.Interface = iface }, nil
+		},
+	},
+}
+
+// Checks returns every registered check in report order.
+func Checks() []Check {
+	return append([]Check(nil), registry...)
+}
+
+// CheckNames returns the names accepted by --checks and --skip.
+func CheckNames() []string {
+	names := make([]string, len(registry))
+	for i, check := range registry {
+		names[i] = check.Name
+	}
+	return names
+}
+
+// SelectChecks resolves the --checks and --skip flags into the checks to run.
+// An empty only list selects every check.
+func SelectChecks(only, skip []string) ([]Check, error) {
+	selected := make(map[string]bool)
+	if len(only) == 0 {
+		for _, check := range registry {
+			selected[check.Name] = true
+		}
+	}
+
+	for _, name := range only {
+		check, err := lookupCheck(name)
+		if err != nil {
+			return nil, err
+		}
+		selected[check.Name] = true
+	}
+
+	for _, name := range skip {
+		check, err := lookupCheck(name)
+		if err != nil {
+			return nil, err
+		}
+		delete(selected, check.Name)
+	}
+
+	var checks []Check
+	for _, check := range registry {
+		if selected[check.Name] {
+			checks = append(checks, check)
+		}
+	}
+
+	if len(checks) == 0 {
+		return nil, fmt.Errorf("no checks selected")
+	}
+
+	return checks, nil
+}
+
+// MissingTools returns the external binaries the given checks need on this
+// host that are not available on PATH.
+func MissingTools(checks []Check) []string {
+	seen := make(map[string]bool)
+	var missing []string
+	for _, check := range checks {
+		if check.needsTools != nil && !check.needsTools() {
+			continue
+		}
+		for _, tool := range check.Tools {
+			if seen[tool] {
+				continue
+			}
+			seen[tool] = true
+			if _, err := exec.LookPath(tool); err != nil {
+				missing = append(missing, tool)
+			}
+		}
+	}
+	return missing
+}
+
+func lookupCheck(name string) (Check, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, check := range registry {
+		if check.Name == name {
+			return check, nil
+		}
+		for _, alias := range check.Aliases {
+			if alias == name {
+				return check, nil
+			}
+		}
+	}
+	return Check{}, fmt.Errorf("unknown check '%s' (available: %s)", name, strings.Join(CheckNames(), ", "))
+}
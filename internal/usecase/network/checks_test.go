@@ -0,0 +1,71 @@
+package network
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMissingTools(t *testing.T) {
+	needed := func() bool { return true }
+	notNeeded := func() bool { return false }
+	checks := []Check{
+		{Name: "a", Tools: []string{"teemo-missing-tool", "sh"}},
+		{Name: "b", Tools: []string{"teemo-missing-tool"}},
+		{Name: "c", Tools: []string{"teemo-fallback-tool"}, needsTools: needed},
+		{Name: "d", Tools: []string{"teemo-unneeded-tool"}, needsTools: notNeeded},
+	}
+
+	got := MissingTools(checks)
+	want := []string{"teemo-missing-tool", "teemo-fallback-tool"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingTools = %q, want %q", got, want)
+	}
+}
+
+func TestTracerouteCheckDeclaresItsFallbackTool(t *testing.T) {
+	check, err := lookupCheck("traceroute")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(check.Tools, []string{"traceroute"}) || check.needsTools == nil {
+		t.Errorf("traceroute check Tools = %q, needsTools set = %v; want the traceroute binary, needed only without raw sockets",
+			check.Tools, check.needsTools != nil)
+	}
+}
+
+func TestSelectChecks(t *testing.T) {
+	tests := []struct {
+		name       string
+		only, skip []string
+		want       []string
+		err        string
+	}{
+		{name: "all", want: CheckNames()},
+		{name: "only with alias", only: []string{"curl", " DIG "}, want: []string{"dns", "http"}},
+		{name: "skip", only: []string{"dns", "http"}, skip: []string{"http"}, want: []string{"dns"}},
+		{name: "nothing left", only: []string{"dns"}, skip: []string{"dns"}, err: "no checks selected"},
+		{name: "unknown", only: []string{"bogus"}, err: "unknown check 'bogus'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, err := SelectChecks(tt.only, tt.skip)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, check := range checks {
+				names = append(names, check.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("checks = %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// LoadSnapshot reads a NetworkDebugResult saved with debug --save.
+func LoadSnapshot(r io.Reader) (*models.NetworkDebugResult, error) {
+	var result models.NetworkDebugResult
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding snapshot: %w", err)
+	}
+	if result.Domain == "" || result.Timestamp.IsZero() {
+		return nil, fmt.Errorf("not a debug snapshot: domain or timestamp missing")
+	}
+	return &result, nil
+}
+
+// DiffSnapshots compares two diagnoses field by field: DNS answers, route
+// hops, HTTP status and timings, TLS certificate, ping statistics, port
+// states, listening sockets, errors and verdicts. TTLs and per-probe samples
+// are left out since they differ on every run. A check that ran in only one
+// of the snapshots is reported as such instead of field by field.
+func DiffSnapshots(before, after *models.NetworkDebugResult) *models.SnapshotDiff {
+	d := &differ{}
+
+	for _, check := range CheckNames() {
+		skippedBefore, skippedAfter := before.IsSkipped(check), after.IsSkipped(check)
+		switch {
+		case skippedBefore && skippedAfter:
+			continue
+		case skippedBefore || skippedAfter:
+			d.value(check, "check", ranOrSkipped(skippedBefore), ranOrSkipped(skippedAfter))
+			continue
+		}
+
+		switch check {
+		case "dns":
+			d.value(check, "server", before.DNSLookup.Server, after.DNSLookup.Server)
+			d.set(check, "record", dnsRecordList(before.DNSLookup.Records), dnsRecordList(after.DNSLookup.Records))
+			d.value(check, "cname_chain", strings.Join(before.DNSLookup.CNAMEChain, " -> "), strings.Join(after.DNSLookup.CNAMEChain, " -> "))
+		case "nslookup":
+			d.set(check, "address", before.NSLookup.Addresses, after.NSLookup.Addresses)
+		case "traceroute":
+			diffTraceroute(d, before.Traceroute, after.Traceroute)
+		case "http":
+			diffHTTP(d, before.HTTPRequest, after.HTTPRequest)
+		case "tls":
+			diffTLS(d, before.TLS, after.TLS)
+		case "ping":
+			b, a := before.Ping, after.Ping
+			d.value(check, "protocol", b.Protocol, a.Protocol)
+			d.value(check, "received", fmt.Sprintf("%d/%d", b.Received, b.Sent), fmt.Sprintf("%d/%d", a.Received, a.Sent))
+			d.value(check, "loss_percent", formatPercent(b.LossPercent), formatPercent(a.LossPercent))
+			d.duration(check, "min_latency", b.MinLatency, a.MinLatency)
+			d.duration(check, "avg_latency", b.AvgLatency, a.AvgLatency)
+			d.duration(check, "max_latency", b.MaxLatency, a.MaxLatency)
+			d.duration(check, "stddev", b.StdDev, a.StdDev)
+			d.duration(check, "jitter", b.Jitter, a.Jitter)
+		case "ports":
+			d.keyed(check, portStates(before.Ports), portStates(after.Ports))
+		case "netstat":
+			d.set(check, "listening", listeningSockets(before.Netstat), listeningSockets(after.Netstat))
+			beforeStates, afterStates := make(map[string]string), make(map[string]string)
+			for state, count := range before.Netstat.States {
+				beforeStates["state "+state] = strconv.Itoa(count)
+			}
+			for state, count := range after.Netstat.States {
+				afterStates["state "+state] = strconv.Itoa(count)
+			}
+			d.keyed(check, beforeStates, afterStates)
+		case "interface":
+			d.value(check, "name", before.Interface.Name, after.Interface.Name)
+			d.value(check, "oper_state", before.Interface.OperState, after.Interface.OperState)
+		}
+	}
+
+	d.set("errors", "error", toolErrorList(before.Errors), toolErrorList(after.Errors))
+
+	d.value("verdict", "status", before.Verdict.Status, after.Verdict.Status)
+	beforeVerdicts, afterVerdicts := make(map[string]string), make(map[string]string)
+	for _, check := range before.Verdict.Checks {
+		beforeVerdicts[check.Check] = check.Status
+	}
+	for _, check := range after.Verdict.Checks {
+		afterVerdicts[check.Check] = check.Status
+	}
+	d.keyed("verdict", beforeVerdicts, afterVerdicts)
+
+	return &models.SnapshotDiff{
+		BeforeDomain:    before.Domain,
+		BeforeTimestamp: before.Timestamp,
+		AfterDomain:     after.Domain,
+		AfterTimestamp:  after.Timestamp,
+		Changes:         d.changes,
+	}
+}
+
+func diffTraceroute(d *differ, before, after models.TracerouteResult) {
+	const section = "traceroute"
+	d.value(section, "target", before.Target, after.Target)
+	d.value(section, "reached", strconv.FormatBool(before.Reached), strconv.FormatBool(after.Reached))
+	d.value(section, "hops", strconv.Itoa(len(before.Hops)), strconv.Itoa(len(after.Hops)))
+
+	hops := func(result models.TracerouteResult) map[string]string {
+		m := make(map[string]string)
+		for _, hop := range result.Hops {
+			var addresses []string
+			for _, responder := range hop.Responders {
+				addresses = append(addresses, responder.Address)
+			}
+			if len(addresses) == 0 {
+				addresses = []string{"*"}
+			}
+			sort.Strings(addresses)
+			m[fmt.Sprintf("hop %02d", hop.HopNumber)] = strings.Join(addresses, ", ")
+		}
+		return m
+	}
+	d.keyed(section, hops(before), hops(after))
+}
+
+func diffHTTP(d *differ, before, after models.HTTPRequestResult) {
+	const section = "http"
+	d.value(section, "url", before.URL, after.URL)
+	d.value(section, "status", before.Status, after.Status)
+	d.value(section, "proto", before.Proto, after.Proto)
+	d.value(section, "content_type", before.ContentType, after.ContentType)
+
+	redirects := func(result models.HTTPRequestResult) string {
+		var hops []string
+		for _, redirect := range result.Redirects {
+			hops = append(hops, fmt.Sprintf("%d %s", redirect.StatusCode, redirect.Location))
+		}
+		return strings.Join(hops, " -> ")
+	}
+	d.value(section, "redirects", redirects(before), redirects(after))
+
+	d.duration(section, "dns_lookup", before.Timings.DNSLookup, after.Timings.DNSLookup)
+	d.duration(section, "tcp_connect", before.Timings.TCPConnect, after.Timings.TCPConnect)
+	d.duration(section, "tls_handshake", before.Timings.TLSHandshake, after.Timings.TLSHandshake)
+	d.duration(section, "time_to_first_byte", before.Timings.TimeToFirstByte, after.Timings.TimeToFirstByte)
+	d.duration(section, "transfer", before.Timings.Transfer, after.Timings.Transfer)
+	d.duration(section, "total", before.Timings.Total, after.Timings.Total)
+
+	expectations := func(result models.HTTPRequestResult) map[string]string {
+		m := make(map[string]string)
+		for _, expectation := range result.Expectations {
+			m["expect "+expectation.Description] = strconv.FormatBool(expectation.Passed)
+		}
+		return m
+	}
+	d.keyed(section, expectations(before), expectations(after))
+}
+
+func diffTLS(d *differ, before, after models.TLSResult) {
+	const section = "tls"
+	d.value(section, "version", before.Version, after.Version)
+	d.value(section, "cipher_suite", before.CipherSuite, after.CipherSuite)
+	d.value(section, "chain_verified", strconv.FormatBool(before.ChainVerified), strconv.FormatBool(after.ChainVerified))
+	d.value(section, "hostname_verified", strconv.FormatBool(before.HostnameVerified), strconv.FormatBool(after.HostnameVerified))
+
+	leaf := func(result models.TLSResult) models.TLSCertificate {
+		if len(result.Chain) == 0 {
+			return models.TLSCertificate{}
+		}
+		return result.Chain[0]
+	}
+	b, a := leaf(before), leaf(after)
+	d.value(section, "subject", b.Subject, a.Subject)
+	d.value(section, "issuer", b.Issuer, a.Issuer)
+	d.value(section, "serial_number", b.SerialNumber, a.SerialNumber)
+	d.value(section, "not_after", formatDate(b.NotAfter), formatDate(a.NotAfter))
+}
+
+// differ accumulates changes in the order fields are compared.
+type differ struct {
+	changes []models.SnapshotChange
+}
+
+// value records a field whose value differs; an empty side means the value
+// was added or removed.
+func (d *differ) value(section, field, before, after string) {
+	if before == after {
+		return
+	}
+	kind := models.ChangeChanged
+	switch {
+	case before == "":
+		kind = models.ChangeAdded
+	case after == "":
+		kind = models.ChangeRemoved
+	}
+	d.changes = append(d.changes, models.SnapshotChange{Section: section, Field: field, Kind: kind, Before: before, After: after})
+}
+
+// duration records a changed duration with the difference appended. Changes
+// lost to the display rounding are not reported.
+func (d *differ) duration(section, field string, before, after time.Duration) {
+	if formatDuration(before) == formatDuration(after) {
+		return
+	}
+	if before == 0 || after == 0 {
+		d.value(section, field, formatDuration(before), formatDuration(after))
+		return
+	}
+	delta := (after - before).Round(10 * time.Microsecond)
+	sign := "+"
+	if delta < 0 {
+		sign = ""
+	}
+	d.changes = append(d.changes, models.SnapshotChange{
+		Section: section,
+		Field:   field,
+		Kind:    models.ChangeChanged,
+		Before:  formatDuration(before),
+		After:   fmt.Sprintf("%s (%s%s)", formatDuration(after), sign, delta),
+	})
+}
+
+// set records the items present in only one of the lists.
+func (d *differ) set(section, field string, before, after []string) {
+	inBefore, inAfter := make(map[string]bool), make(map[string]bool)
+	for _, item := range before {
+		inBefore[item] = true
+	}
+	for _, item := range after {
+		inAfter[item] = true
+	}
+	for _, item := range sortedSetKeys(inBefore) {
+		if !inAfter[item] {
+			d.value(section, field, item, "")
+		}
+	}
+	for _, item := range sortedSetKeys(inAfter) {
+		if !inBefore[item] {
+			d.value(section, field, "", item)
+		}
+	}
+}
+
+// keyed compares two maps of field to value, in field order.
+func (d *differ) keyed(section string, before, after map[string]string) {
+	fields := make(map[string]bool)
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+	for _, field := range sortedSetKeys(fields) {
+		d.value(section, field, before[field], after[field])
+	}
+}
+
+func ranOrSkipped(skipped bool) string {
+	if skipped {
+		return "skipped"
+	}
+	return "ran"
+}
+
+func dnsRecordList(records []models.DNSRecord) []string {
+	return sortedSetKeys(dnsRecordSet(records))
+}
+
+func portStates(result models.PortsResult) map[string]string {
+	states := make(map[string]string)
+	for _, probe := range result.Probes {
+		states[fmt.Sprintf("%d/%s", probe.Port, probe.Protocol)] = probe.State
+	}
+	return states
+}
+
+// listeningSockets lists the TCP listeners and unconnected UDP sockets with
+// their owners.
+func listeningSockets(result models.NetstatResult) []string {
+	var sockets []string
+	for _, conn := range result.Connections {
+		if conn.Status != "LISTEN" && conn.Status != "UNCONN" {
+			continue
+		}
+		socket := fmt.Sprintf("%s %s", conn.Protocol, conn.LocalAddress)
+		if conn.Process != "" {
+			socket += fmt.Sprintf(" (%s)", conn.Process)
+		}
+		sockets = append(sockets, socket)
+	}
+	return sockets
+}
+
+func toolErrorList(errs []models.ToolError) []string {
+	var list []string
+	for _, toolErr := range errs {
+		list = append(list, toolErr.Error())
+	}
+	return list
+}
+
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.Round(10 * time.Microsecond).String()
+}
+
+func formatPercent(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64) + "%"
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
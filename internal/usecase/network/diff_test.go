@@ -0,0 +1,207 @@
+package network
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// debugSnapshot builds a diagnosis in which every check ran and passed.
+func debugSnapshot() *models.NetworkDebugResult {
+	hop := func(number int, address string) models.TracerouteHop {
+		return models.TracerouteHop{HopNumber: number, Address: address, Responders: []models.TracerouteResponder{{Address: address, Replies: 3}}}
+	}
+	return &models.NetworkDebugResult{
+		Domain:    "example.com",
+		Timestamp: time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC),
+		DNSLookup: models.DNSLookupResult{
+			Server:  "1.1.1.1:53",
+			Records: []models.DNSRecord{{Name: "example.com.", Type: "A", TTL: 300, Value: "93.184.216.34"}},
+		},
+		NSLookup: models.NSLookupResult{Addresses: []string{"93.184.216.34"}},
+		Traceroute: models.TracerouteResult{
+			Target:  "93.184.216.34",
+			Reached: true,
+			Hops:    []models.TracerouteHop{hop(1, "192.168.1.1"), hop(2, "100.64.0.1"), hop(3, "93.184.216.34")},
+		},
+		HTTPRequest: models.HTTPRequestResult{
+			URL:        "https://example.com/",
+			Status:     "200 OK",
+			StatusCode: 200,
+			Proto:      "HTTP/2.0",
+			Timings:    models.HTTPTimings{TCPConnect: 10 * time.Millisecond, Total: 120 * time.Millisecond},
+		},
+		TLS: models.TLSResult{
+			Version:          "TLS 1.3",
+			ChainVerified:    true,
+			HostnameVerified: true,
+			Chain:            []models.TLSCertificate{{Subject: "CN=example.com", SerialNumber: "01", NotAfter: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		},
+		Ping: models.PingResult{Protocol: "icmp", Sent: 4, Received: 4, AvgLatency: 20 * time.Millisecond},
+		Ports: models.PortsResult{Open: 2, Probes: []models.PortProbe{
+			{Port: 80, Protocol: "tcp", State: models.PortOpen},
+			{Port: 443, Protocol: "tcp", State: models.PortOpen},
+		}},
+		Netstat: models.NetstatResult{
+			Connections: []models.NetstatConnection{{Protocol: "tcp", LocalAddress: "0.0.0.0:443", Status: "LISTEN", Process: "nginx"}},
+			States:      map[string]int{"LISTEN": 1},
+		},
+		Interface: models.InterfaceResult{Name: "eth0", OperState: "up"},
+		Skipped:   []string{},
+		Errors:    []models.ToolError{},
+		Verdict: models.Verdict{Status: models.VerdictPass, Checks: []models.CheckVerdict{
+			{Check: "http", Status: models.VerdictPass},
+			{Check: "ping", Status: models.VerdictPass},
+		}},
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	tests := []struct {
+		name   string
+		before func(*models.NetworkDebugResult)
+		after  func(*models.NetworkDebugResult)
+		want   []string
+	}{
+		{name: "identical"},
+		{
+			name:  "only TTLs and samples differ",
+			after: func(r *models.NetworkDebugResult) { r.DNSLookup.Records[0].TTL = 12; r.Ping.MinLatency = 0 },
+		},
+		{
+			name: "check skipped afterwards",
+			after: func(r *models.NetworkDebugResult) {
+				r.Skipped = []string{"traceroute"}
+				r.Traceroute = models.TracerouteResult{}
+			},
+			want: []string{"traceroute check changed ran -> skipped"},
+		},
+		{
+			name: "check ran only afterwards",
+			before: func(r *models.NetworkDebugResult) {
+				r.Skipped = []string{"ping", "ports"}
+				r.Ping = models.PingResult{}
+			},
+			want: []string{"ping check changed skipped -> ran", "ports check changed skipped -> ran"},
+		},
+		{
+			name:   "check skipped in both",
+			before: func(r *models.NetworkDebugResult) { r.Skipped = []string{"http"} },
+			after:  func(r *models.NetworkDebugResult) { r.Skipped = []string{"http"}; r.HTTPRequest.StatusCode = 500 },
+		},
+		{
+			name: "dns records",
+			after: func(r *models.NetworkDebugResult) {
+				r.DNSLookup.Records = append(r.DNSLookup.Records, models.DNSRecord{Name: "example.com.", Type: "A", Value: "93.184.216.35"})
+				r.DNSLookup.Records[0].Value = "93.184.216.36"
+			},
+			want: []string{
+				"dns record removed example.com. A 93.184.216.34 -> ",
+				"dns record added  -> example.com. A 93.184.216.35",
+				"dns record added  -> example.com. A 93.184.216.36",
+			},
+		},
+		{
+			name: "traceroute path",
+			after: func(r *models.NetworkDebugResult) {
+				r.Traceroute.Hops[1].Responders = []models.TracerouteResponder{{Address: "100.64.0.2"}, {Address: "100.64.0.1"}}
+				r.Traceroute.Hops[2].Responders = nil
+				r.Traceroute.Reached = false
+			},
+			want: []string{
+				"traceroute reached changed true -> false",
+				"traceroute hop 02 changed 100.64.0.1 -> 100.64.0.1, 100.64.0.2",
+				"traceroute hop 03 changed 93.184.216.34 -> *",
+			},
+		},
+		{
+			name: "http status and timings",
+			after: func(r *models.NetworkDebugResult) {
+				r.HTTPRequest.Status, r.HTTPRequest.StatusCode = "503 Service Unavailable", 503
+				r.HTTPRequest.Timings.Total = 170 * time.Millisecond
+				r.HTTPRequest.Timings.TCPConnect = 10*time.Millisecond + 4*time.Microsecond
+				r.HTTPRequest.Timings.TLSHandshake = 30 * time.Millisecond
+			},
+			want: []string{
+				"http status changed 200 OK -> 503 Service Unavailable",
+				"http tls_handshake added  -> 30ms",
+				"http total changed 120ms -> 170ms (+50ms)",
+			},
+		},
+		{
+			name:  "tls certificate",
+			after: func(r *models.NetworkDebugResult) { r.TLS.Chain[0].SerialNumber = "02"; r.TLS.ChainVerified = false },
+			want:  []string{"tls chain_verified changed true -> false", "tls serial_number changed 01 -> 02"},
+		},
+		{
+			name: "ping",
+			after: func(r *models.NetworkDebugResult) {
+				r.Ping.Received, r.Ping.LossPercent = 3, 25
+				r.Ping.AvgLatency = 15 * time.Millisecond
+			},
+			want: []string{
+				"ping received changed 4/4 -> 3/4",
+				"ping loss_percent changed 0% -> 25%",
+				"ping avg_latency changed 20ms -> 15ms (-5ms)",
+			},
+		},
+		{
+			name: "ports",
+			after: func(r *models.NetworkDebugResult) {
+				r.Ports.Probes[1].State = models.PortFiltered
+				r.Ports.Probes = append(r.Ports.Probes, models.PortProbe{Port: 53, Protocol: "udp", State: models.PortOpenFiltered})
+			},
+			want: []string{"ports 443/tcp changed open -> filtered", "ports 53/udp added  -> open|filtered"},
+		},
+		{
+			name: "netstat",
+			after: func(r *models.NetworkDebugResult) {
+				r.Netstat.Connections = append(r.Netstat.Connections,
+					models.NetstatConnection{Protocol: "udp", LocalAddress: "0.0.0.0:53", Status: "UNCONN"},
+					models.NetstatConnection{Protocol: "tcp", LocalAddress: "10.0.0.2:443", RemoteAddress: "10.0.0.9:50000", Status: "ESTABLISHED"})
+				r.Netstat.States = map[string]int{"LISTEN": 1, "ESTABLISHED": 1}
+			},
+			want: []string{"netstat listening added  -> udp 0.0.0.0:53", "netstat state ESTABLISHED added  -> 1"},
+		},
+		{
+			name: "errors and verdict",
+			after: func(r *models.NetworkDebugResult) {
+				r.Errors = []models.ToolError{{Tool: "ping", Message: "no replies"}}
+				r.Verdict.Status = models.VerdictFail
+				r.Verdict.Checks[1].Status = models.VerdictFail
+			},
+			want: []string{
+				"errors error added  -> ping error: no replies",
+				"verdict status changed pass -> fail",
+				"verdict ping changed pass -> fail",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after := debugSnapshot(), debugSnapshot()
+			if tt.before != nil {
+				tt.before(before)
+			}
+			if tt.after != nil {
+				tt.after(after)
+			}
+
+			diff := DiffSnapshots(before, after)
+			var got []string
+			for _, change := range diff.Changes {
+				got = append(got, strings.Join([]string{change.Section, change.Field, change.Kind, change.Before, "->", change.After}, " "))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if diff.BeforeDomain != "example.com" || !diff.AfterTimestamp.Equal(after.Timestamp) {
+				t.Errorf("diff header = %s %s", diff.BeforeDomain, diff.AfterTimestamp)
+			}
+		})
+	}
+}
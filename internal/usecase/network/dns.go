@@ -0,0 +1,537 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+const (
+	// maxCNAMEHops bounds how far a CNAME chain is followed.
+	maxCNAMEHops = 8
+	// ednsBufferSize is the UDP payload size advertised to avoid truncation.
+	ednsBufferSize = 1232
+
+	typeCAA dnsmessage.Type = 257
+)
+
+// dnsQueryTypes are the record types queried for every domain, in report order.
+var dnsQueryTypes = []dnsmessage.Type{
+	dnsmessage.TypeA,
+	dnsmessage.TypeAAAA,
+	dnsmessage.TypeCNAME,
+	dnsmessage.TypeMX,
+	dnsmessage.TypeNS,
+	dnsmessage.TypeTXT,
+	dnsmessage.TypeSOA,
+	typeCAA,
+	dnsmessage.TypeSRV,
+}
+
+var dnsTypeNames = map[dnsmessage.Type]string{
+	dnsmessage.TypeA:     "A",
+	dnsmessage.TypeAAAA:  "AAAA",
+	dnsmessage.TypeCNAME: "CNAME",
+	dnsmessage.TypeMX:    "MX",
+	dnsmessage.TypeNS:    "NS",
+	dnsmessage.TypeTXT:   "TXT",
+	dnsmessage.TypeSOA:   "SOA",
+	dnsmessage.TypePTR:   "PTR",
+	dnsmessage.TypeSRV:   "SRV",
+	typeCAA:              "CAA",
+}
+
+// DNSClient queries a DNS server directly instead of going through dig, so
+// TTLs, classes and per-query timings are preserved.
+type DNSClient struct {
+	// Server is a host:port; when empty the first nameserver from
+	// /etc/resolv.conf is used.
+	Server  string
+	Timeout time.Duration
+}
+
+// dnsResponse is a single answered query.
+type dnsResponse struct {
+	Server  string
+	Rcode   dnsmessage.RCode
+	Answers []models.DNSRecord
+	Latency time.Duration
+}
+
+// Lookup queries every supported record type for domain concurrently and
+// follows the CNAME chain until it reaches an address. A port in domain is
+// ignored.
+func (c *DNSClient) Lookup(ctx context.Context, domain string) (models.DNSLookupResult, error) {
+	domain = targetHost(domain)
+	server, err := c.server()
+	if err != nil {
+		return models.DNSLookupResult{}, err
+	}
+
+	name, err := dnsmessage.NewName(fqdn(domain))
+	if err != nil {
+		return models.DNSLookupResult{}, fmt.Errorf("invalid domain '%s': %w", domain, err)
+	}
+
+	queries := make([]models.DNSQuery, len(dnsQueryTypes))
+	responses := make([]*dnsResponse, len(dnsQueryTypes))
+
+	var wg sync.WaitGroup
+	for i, qtype := range dnsQueryTypes {
+		wg.Add(1)
+		go func(i int, qtype dnsmessage.Type) {
+			defer wg.Done()
+			query := models.DNSQuery{Type: dnsTypeName(qtype)}
+			resp, err := c.exchange(ctx, server, name, qtype)
+			if err != nil {
+				query.Error = err.Error()
+			} else {
+				query.Rcode = rcodeName(resp.Rcode)
+				query.Answers = len(resp.Answers)
+				query.Latency = resp.Latency
+				responses[i] = resp
+			}
+			queries[i] = query
+		}(i, qtype)
+	}
+	wg.Wait()
+
+	result := models.DNSLookupResult{
+		Server:  server,
+		Queries: queries,
+	}
+
+	var errs []error
+	seen := make(map[string]bool)
+	for i, resp := range responses {
+		if resp == nil {
+			errs = append(errs, fmt.Errorf("%s query: %s", queries[i].Type, queries[i].Error))
+			continue
+		}
+		for _, record := range resp.Answers {
+			key := record.Name + " " + record.Type + " " + record.Value
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			result.Records = append(result.Records, record)
+		}
+	}
+
+	if len(errs) == len(responses) {
+		return result, errors.Join(errs...)
+	}
+
+	chain, extra, err := c.followCNAMEs(ctx, server, fqdn(domain), result.Records)
+	if err != nil {
+		return result, err
+	}
+	result.CNAMEChain = chain
+	for _, record := range extra {
+		key := record.Name + " " + record.Type + " " + record.Value
+		if !seen[key] {
+			seen[key] = true
+			result.Records = append(result.Records, record)
+		}
+	}
+
+	return result, nil
+}
+
+// LookupType sends a single question for qtype (e.g. "A" or "MX") without
+// following CNAMEs, which is what comparing resolvers needs.
+func (c *DNSClient) LookupType(ctx context.Context, domain, qtype string) (models.DNSLookupResult, error) {
+	domain = targetHost(domain)
+	server, err := c.server()
+	if err != nil {
+		return models.DNSLookupResult{}, err
+	}
+
+	t, ok := parseDNSType(qtype)
+	if !ok {
+		return models.DNSLookupResult{}, fmt.Errorf("unsupported record type '%s'", qtype)
+	}
+
+	name, err := dnsmessage.NewName(fqdn(domain))
+	if err != nil {
+		return models.DNSLookupResult{}, fmt.Errorf("invalid domain '%s': %w", domain, err)
+	}
+
+	result := models.DNSLookupResult{Server: server}
+	query := models.DNSQuery{Type: dnsTypeName(t)}
+
+	resp, err := c.exchange(ctx, server, name, t)
+	if err != nil {
+		query.Error = err.Error()
+		result.Queries = []models.DNSQuery{query}
+		return result, err
+	}
+
+	query.Rcode = rcodeName(resp.Rcode)
+	query.Answers = len(resp.Answers)
+	query.Latency = resp.Latency
+	result.Queries = []models.DNSQuery{query}
+	result.Records = resp.Answers
+
+	return result, nil
+}
+
+// followCNAMEs walks the CNAME records starting at name. When the server did
+// not include the target's addresses in its answer, the target is queried
+// directly so the chain always ends at an address or a dead end. It returns
+// the chain (empty when name is not an alias) and any records it fetched.
+func (c *DNSClient) followCNAMEs(ctx context.Context, server, name string, records []models.DNSRecord) ([]string, []models.DNSRecord, error) {
+	known := append([]models.DNSRecord(nil), records...)
+	var extra []models.DNSRecord
+	chain := []string{name}
+	current := name
+	queried := false
+
+	for {
+		target := cnameTarget(known, current)
+		if target == "" {
+			if len(chain) == 1 || hasAddress(known, current) || queried {
+				break
+			}
+			qname, err := dnsmessage.NewName(current)
+			if err != nil {
+				return chain, extra, err
+			}
+			resp, err := c.exchange(ctx, server, qname, dnsmessage.TypeA)
+			if err != nil {
+				return chain, extra, fmt.Errorf("following CNAME to %s: %w", current, err)
+			}
+			known = append(known, resp.Answers...)
+			extra = append(extra, resp.Answers...)
+			queried = true
+			continue
+		}
+
+		for _, link := range chain {
+			if strings.EqualFold(link, target) {
+				return chain, extra, fmt.Errorf("CNAME loop detected at %s", target)
+			}
+		}
+		if len(chain) > maxCNAMEHops {
+			return chain, extra, fmt.Errorf("CNAME chain longer than %d hops", maxCNAMEHops)
+		}
+		chain = append(chain, target)
+		current = target
+		queried = false
+	}
+
+	if len(chain) == 1 {
+		return nil, extra, nil
+	}
+	return chain, extra, nil
+}
+
+// exchange sends one question over UDP and retries over TCP when the answer
+// was truncated.
+func (c *DNSClient) exchange(ctx context.Context, server string, name dnsmessage.Name, qtype dnsmessage.Type) (*dnsResponse, error) {
+	id, err := dnsQueryID()
+	if err != nil {
+		return nil, err
+	}
+	query, err := buildQuery(id, name, qtype)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	msg, err := c.roundTrip(ctx, "udp", server, query)
+	if err == nil && msg.Truncated {
+		msg, err = c.roundTrip(ctx, "tcp", server, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	latency := time.Since(start)
+
+	if msg.ID != id {
+		return nil, fmt.Errorf("response ID %d does not match query ID %d", msg.ID, id)
+	}
+	if len(msg.Questions) > 0 && (msg.Questions[0].Type != qtype || !strings.EqualFold(msg.Questions[0].Name.String(), name.String())) {
+		return nil, fmt.Errorf("response question does not match the query")
+	}
+
+	resp := &dnsResponse{
+		Server:  server,
+		Rcode:   msg.RCode,
+		Latency: latency,
+	}
+	for _, answer := range msg.Answers {
+		resp.Answers = append(resp.Answers, recordFromResource(answer))
+	}
+
+	return resp, nil
+}
+
+func (c *DNSClient) roundTrip(ctx context.Context, network, server string, query []byte) (*dnsmessage.Message, error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	// Unblock reads as soon as the caller gives up.
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	var buf []byte
+	if network == "tcp" {
+		framed := make([]byte, 2+len(query))
+		binary.BigEndian.PutUint16(framed, uint16(len(query)))
+		copy(framed[2:], query)
+		if _, err := conn.Write(framed); err != nil {
+			return nil, err
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		buf = make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := conn.Write(query); err != nil {
+			return nil, err
+		}
+		buf = make([]byte, 65535)
+		n, err := conn.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+		buf = buf[:n]
+	}
+
+	var msg dnsmessage.Message
+	if err := msg.Unpack(buf); err != nil {
+		return nil, fmt.Errorf("error decoding DNS response: %w", err)
+	}
+	return &msg, nil
+}
+
+func (c *DNSClient) server() (string, error) {
+	if c.Server != "" {
+		return withDNSPort(c.Server), nil
+	}
+	servers, err := systemNameservers("/etc/resolv.conf")
+	if err != nil {
+		return "", err
+	}
+	return servers[0], nil
+}
+
+// dnsQueryID returns an unpredictable query ID, so off-path spoofed answers
+// are unlikely to match.
+func dnsQueryID() (uint16, error) {
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return 0, fmt.Errorf("error generating DNS query ID: %w", err)
+	}
+	return binary.BigEndian.Uint16(id[:]), nil
+}
+
+func buildQuery(id uint16, name dnsmessage.Name, qtype dnsmessage.Type) ([]byte, error) {
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  name,
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+
+	var opt dnsmessage.ResourceHeader
+	if err := opt.SetEDNS0(ednsBufferSize, dnsmessage.RCodeSuccess, false); err != nil {
+		return nil, err
+	}
+	msg.Additionals = []dnsmessage.Resource{{Header: opt, Body: &dnsmessage.OPTResource{}}}
+
+	return msg.Pack()
+}
+
+func recordFromResource(r dnsmessage.Resource) models.DNSRecord {
+	record := models.DNSRecord{
+		Name:  r.Header.Name.String(),
+		Type:  dnsTypeName(r.Header.Type),
+		Class: className(r.Header.Class),
+		TTL:   r.Header.TTL,
+	}
+
+	switch body := r.Body.(type) {
+	case *dnsmessage.AResource:
+		record.IP = net.IP(body.A[:]).String()
+		record.Value = record.IP
+	case *dnsmessage.AAAAResource:
+		record.IP = net.IP(body.AAAA[:]).String()
+		record.Value = record.IP
+	case *dnsmessage.CNAMEResource:
+		record.Value = body.CNAME.String()
+	case *dnsmessage.NSResource:
+		record.Value = body.NS.String()
+	case *dnsmessage.PTRResource:
+		record.Value = body.PTR.String()
+	case *dnsmessage.MXResource:
+		record.Value = fmt.Sprintf("%d %s", body.Pref, body.MX.String())
+	case *dnsmessage.TXTResource:
+		quoted := make([]string, len(body.TXT))
+		for i, txt := range body.TXT {
+			quoted[i] = strconv.Quote(txt)
+		}
+		record.Value = strings.Join(quoted, " ")
+	case *dnsmessage.SOAResource:
+		record.Value = fmt.Sprintf("%s %s %d %d %d %d %d",
+			body.NS.String(), body.MBox.String(), body.Serial, body.Refresh, body.Retry, body.Expire, body.MinTTL)
+	case *dnsmessage.SRVResource:
+		record.Value = fmt.Sprintf("%d %d %d %s", body.Priority, body.Weight, body.Port, body.Target.String())
+	case *dnsmessage.UnknownResource:
+		if body.Type == typeCAA {
+			record.Value = formatCAA(body.Data)
+		} else {
+			record.Value = fmt.Sprintf("\\# %d %x", len(body.Data), body.Data)
+		}
+	}
+
+	return record
+}
+
+// formatCAA renders CAA rdata (RFC 8659) as "flags tag \"value\"".
+func formatCAA(data []byte) string {
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return fmt.Sprintf("\\# %d %x", len(data), data)
+	}
+	tagLen := int(data[1])
+	return fmt.Sprintf("%d %s %s", data[0], data[2:2+tagLen], strconv.Quote(string(data[2+tagLen:])))
+}
+
+func cnameTarget(records []models.DNSRecord, name string) string {
+	for _, record := range records {
+		if record.Type == "CNAME" && strings.EqualFold(record.Name, name) {
+			return record.Value
+		}
+	}
+	return ""
+}
+
+func hasAddress(records []models.DNSRecord, name string) bool {
+	for _, record := range records {
+		if (record.Type == "A" || record.Type == "AAAA") && strings.EqualFold(record.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// systemNameservers returns the nameservers configured in resolv.conf as host:port.
+func systemNameservers(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading nameservers: %w", err)
+	}
+	defer f.Close()
+
+	var servers []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, withDNSPort(fields[1]))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading nameservers: %w", err)
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no nameservers found in %s", path)
+	}
+	return servers, nil
+}
+
+// withDNSPort adds the default DNS port to a bare IPv4/IPv6 address or host.
+func withDNSPort(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(strings.Trim(server, "[]"), "53")
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+func dnsTypeName(t dnsmessage.Type) string {
+	if name, ok := dnsTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("TYPE%d", t)
+}
+
+func parseDNSType(name string) (dnsmessage.Type, bool) {
+	for t, n := range dnsTypeNames {
+		if strings.EqualFold(n, name) {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+func className(c dnsmessage.Class) string {
+	if c == dnsmessage.ClassINET {
+		return "IN"
+	}
+	return fmt.Sprintf("CLASS%d", c)
+}
+
+func rcodeName(rcode dnsmessage.RCode) string {
+	switch rcode {
+	case dnsmessage.RCodeSuccess:
+		return "NOERROR"
+	case dnsmessage.RCodeFormatError:
+		return "FORMERR"
+	case dnsmessage.RCodeServerFailure:
+		return "SERVFAIL"
+	case dnsmessage.RCodeNameError:
+		return "NXDOMAIN"
+	case dnsmessage.RCodeNotImplemented:
+		return "NOTIMP"
+	case dnsmessage.RCodeRefused:
+		return "REFUSED"
+	default:
+		return fmt.Sprintf("RCODE%d", rcode)
+	}
+}
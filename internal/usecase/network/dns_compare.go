@@ -0,0 +1,302 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+const (
+	ResolverSourceSystem        = "system"
+	ResolverSourceCustom        = "custom"
+	ResolverSourceAuthoritative = "authoritative"
+)
+
+// DNSCompareOptions configures CompareResolvers.
+type DNSCompareOptions struct {
+	// Resolvers are extra servers to query besides the system nameservers.
+	Resolvers []string
+	// Authoritative adds the nameservers of the domain's zone.
+	Authoritative bool
+	// RecordType is the type compared, "A" when empty.
+	RecordType string
+}
+
+type resolverTarget struct {
+	server     string
+	source     string
+	nameServer string
+}
+
+// CompareResolvers asks the system resolvers, any extra resolvers and
+// optionally the zone's authoritative nameservers the same question
+// concurrently, then flags answers that disagree and cached TTLs that exceed
+// what the authoritative servers hand out.
+func (u *NetworkDebugUsecase) CompareResolvers(ctx context.Context, domain string, opts DNSCompareOptions) (*models.DNSComparisonResult, error) {
+	recordType := strings.ToUpper(opts.RecordType)
+	if recordType == "" {
+		recordType = "A"
+	}
+	if _, ok := parseDNSType(recordType); !ok {
+		return nil, fmt.Errorf("unsupported record type '%s'", opts.RecordType)
+	}
+
+	result := &models.DNSComparisonResult{
+		Domain:     domain,
+		RecordType: recordType,
+		Issues:     []string{},
+	}
+
+	var targets []resolverTarget
+	seen := make(map[string]bool)
+	addTarget := func(t resolverTarget) {
+		if !seen[t.server] {
+			seen[t.server] = true
+			targets = append(targets, t)
+		}
+	}
+
+	system, err := systemNameservers("/etc/resolv.conf")
+	if err != nil {
+		result.Issues = append(result.Issues, err.Error())
+	}
+	for _, server := range system {
+		addTarget(resolverTarget{server: server, source: ResolverSourceSystem})
+	}
+	for _, server := range opts.Resolvers {
+		addTarget(resolverTarget{server: withDNSPort(strings.TrimSpace(server)), source: ResolverSourceCustom})
+	}
+
+	if opts.Authoritative {
+		zone, servers, err := u.authoritativeServers(ctx, domain)
+		if err != nil {
+			result.Issues = append(result.Issues, fmt.Sprintf("could not find authoritative nameservers: %v", err))
+		}
+		result.Zone = zone
+		for _, t := range servers {
+			addTarget(t)
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no resolvers to query")
+	}
+
+	result.Resolvers = make([]models.ResolverAnswer, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target resolverTarget) {
+			defer wg.Done()
+			result.Resolvers[i] = queryResolver(ctx, target, domain, recordType)
+		}(i, target)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	compareAnswers(result)
+
+	return result, nil
+}
+
+func queryResolver(ctx context.Context, target resolverTarget, domain, recordType string) models.ResolverAnswer {
+	answer := models.ResolverAnswer{
+		Resolver:   target.server,
+		Source:     target.source,
+		NameServer: target.nameServer,
+		Answers:    []string{},
+	}
+
+	client := &DNSClient{Server: target.server}
+	lookup, err := client.LookupType(ctx, domain, recordType)
+	answer.Lookup = lookup
+	if err != nil {
+		answer.Error = err.Error()
+		return answer
+	}
+
+	query := lookup.Queries[0]
+	answer.Rcode = query.Rcode
+	answer.Latency = query.Latency
+
+	// Only records owned by the queried name are compared: recursive resolvers
+	// also return the targets of a CNAME, authoritative servers may not.
+	for _, record := range lookup.Records {
+		if !strings.EqualFold(record.Name, fqdn(domain)) {
+			continue
+		}
+		answer.Answers = append(answer.Answers, record.Type+" "+record.Value)
+		if answer.TTL == 0 || record.TTL < answer.TTL {
+			answer.TTL = record.TTL
+		}
+	}
+	sort.Strings(answer.Answers)
+
+	return answer
+}
+
+// authoritativeServers finds the closest enclosing zone that has NS records
+// and resolves each nameserver to the addresses that should be queried.
+func (u *NetworkDebugUsecase) authoritativeServers(ctx context.Context, domain string) (string, []resolverTarget, error) {
+	client := &DNSClient{}
+	labels := strings.Split(strings.TrimSuffix(domain, "."), ".")
+
+	for i := range labels {
+		zone := fqdn(strings.Join(labels[i:], "."))
+
+		lookup, err := client.LookupType(ctx, zone, "NS")
+		if err != nil {
+			return "", nil, err
+		}
+
+		var hosts []string
+		for _, record := range lookup.Records {
+			if record.Type == "NS" && strings.EqualFold(record.Name, zone) {
+				hosts = append(hosts, record.Value)
+			}
+		}
+		if len(hosts) == 0 {
+			continue
+		}
+		sort.Strings(hosts)
+
+		var targets []resolverTarget
+		for _, host := range hosts {
+			addrs, err := u.Resolver.LookupIPAddr(ctx, host)
+			if err != nil {
+				continue
+			}
+			for _, addr := range preferIPv4(addrs) {
+				targets = append(targets, resolverTarget{
+					server:     net.JoinHostPort(addr.IP.String(), "53"),
+					source:     ResolverSourceAuthoritative,
+					nameServer: host,
+				})
+			}
+		}
+		if len(targets) == 0 {
+			return zone, nil, fmt.Errorf("none of the nameservers of %s resolved", zone)
+		}
+		return zone, targets, nil
+	}
+
+	return "", nil, fmt.Errorf("no NS records found for %s or any parent zone", domain)
+}
+
+// compareAnswers picks the expected answer and marks each resolver that
+// disagrees with it or caches a TTL above the authoritative one.
+func compareAnswers(result *models.DNSComparisonResult) {
+	answerKey := func(a models.ResolverAnswer) string {
+		return a.Rcode + "|" + strings.Join(a.Answers, ",")
+	}
+
+	hasAuthoritative := false
+	for _, a := range result.Resolvers {
+		if a.Source == ResolverSourceAuthoritative && a.Error == "" {
+			hasAuthoritative = true
+			break
+		}
+	}
+
+	// Count how many reference resolvers return each distinct answer.
+	counts := make(map[string]int)
+	var order []string
+	for _, a := range result.Resolvers {
+		if a.Error != "" || (hasAuthoritative && a.Source != ResolverSourceAuthoritative) {
+			continue
+		}
+		key := answerKey(a)
+		if counts[key] == 0 {
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+
+	expectedKey := ""
+	for _, key := range order {
+		if counts[key] > counts[expectedKey] {
+			expectedKey = key
+		}
+	}
+	if len(order) > 1 {
+		if hasAuthoritative {
+			result.Issues = append(result.Issues, "authoritative nameservers disagree with each other")
+		} else {
+			result.Issues = append(result.Issues, "resolvers disagree and no authoritative answer was available to break the tie")
+		}
+	}
+
+	var authTTL uint32
+	for i := range result.Resolvers {
+		a := &result.Resolvers[i]
+		if a.Error != "" {
+			continue
+		}
+		if answerKey(*a) == expectedKey {
+			a.Agrees = true
+			if len(result.Expected) == 0 {
+				result.Expected = a.Answers
+			}
+			if a.Source == ResolverSourceAuthoritative && a.TTL > authTTL {
+				authTTL = a.TTL
+			}
+		}
+	}
+	if result.Expected == nil {
+		result.Expected = []string{}
+	}
+
+	result.Consistent = true
+	for i := range result.Resolvers {
+		a := &result.Resolvers[i]
+		if a.Error != "" {
+			result.Consistent = false
+			result.Issues = append(result.Issues, fmt.Sprintf("%s (%s) failed: %s", a.Resolver, a.Source, a.Error))
+			continue
+		}
+		if !a.Agrees {
+			result.Consistent = false
+			result.Issues = append(result.Issues, fmt.Sprintf("%s (%s) answers %s, expected %s",
+				a.Resolver, a.Source, describeAnswers(a.Rcode, a.Answers), describeAnswers("", result.Expected)))
+		}
+		// A cache can never legitimately hold a record longer than the
+		// authoritative TTL, so a higher value means stale or overridden data.
+		if authTTL > 0 && a.Source != ResolverSourceAuthoritative && a.TTL > authTTL {
+			a.StaleTTL = true
+			result.Consistent = false
+			result.Issues = append(result.Issues, fmt.Sprintf("%s (%s) reports TTL %ds, above the authoritative %ds",
+				a.Resolver, a.Source, a.TTL, authTTL))
+		}
+	}
+}
+
+func describeAnswers(rcode string, answers []string) string {
+	if len(answers) == 0 {
+		if rcode != "" && rcode != "NOERROR" {
+			return rcode
+		}
+		return "no records"
+	}
+	return strings.Join(answers, ", ")
+}
+
+func preferIPv4(addrs []net.IPAddr) []net.IPAddr {
+	var v4 []net.IPAddr
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			v4 = append(v4, addr)
+		}
+	}
+	if len(v4) > 0 {
+		return v4
+	}
+	return addrs
+}
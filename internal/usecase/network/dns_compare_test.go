@@ -0,0 +1,159 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+func answer(resolver, source string, ttl uint32, answers ...string) models.ResolverAnswer {
+	return models.ResolverAnswer{Resolver: resolver, Source: source, Rcode: "NOERROR", TTL: ttl, Answers: answers}
+}
+
+func failed(resolver, source, err string) models.ResolverAnswer {
+	return models.ResolverAnswer{Resolver: resolver, Source: source, Error: err}
+}
+
+func TestCompareAnswers(t *testing.T) {
+	nxdomain := models.ResolverAnswer{Resolver: "10.0.0.3:53", Source: ResolverSourceCustom, Rcode: "NXDOMAIN"}
+
+	tests := []struct {
+		name       string
+		resolvers  []models.ResolverAnswer
+		consistent bool
+		expected   []string
+		agrees     []bool
+		stale      []bool
+		issues     []string
+	}{
+		{
+			name: "agreeing",
+			resolvers: []models.ResolverAnswer{
+				answer("10.0.0.1:53", ResolverSourceSystem, 300, "192.0.2.1", "192.0.2.2"),
+				answer("10.0.0.2:53", ResolverSourceCustom, 120, "192.0.2.1", "192.0.2.2"),
+			},
+			consistent: true,
+			expected:   []string{"192.0.2.1", "192.0.2.2"},
+			agrees:     []bool{true, true},
+			stale:      []bool{false, false},
+		},
+		{
+			name: "majority without authoritative",
+			resolvers: []models.ResolverAnswer{
+				answer("10.0.0.1:53", ResolverSourceSystem, 300, "192.0.2.1"),
+				answer("10.0.0.2:53", ResolverSourceCustom, 300, "192.0.2.9"),
+				answer("10.0.0.3:53", ResolverSourceCustom, 300, "192.0.2.1"),
+			},
+			expected: []string{"192.0.2.1"},
+			agrees:   []bool{true, false, true},
+			stale:    []bool{false, false, false},
+			issues: []string{
+				"resolvers disagree and no authoritative answer was available to break the tie",
+				"10.0.0.2:53 (custom) answers 192.0.2.9, expected 192.0.2.1",
+			},
+		},
+		{
+			name: "authoritative wins over the majority",
+			resolvers: []models.ResolverAnswer{
+				answer("10.0.0.1:53", ResolverSourceSystem, 60, "192.0.2.1"),
+				answer("10.0.0.2:53", ResolverSourceCustom, 60, "192.0.2.1"),
+				answer("198.51.100.1:53", ResolverSourceAuthoritative, 300, "192.0.2.5"),
+			},
+			expected: []string{"192.0.2.5"},
+			agrees:   []bool{false, false, true},
+			stale:    []bool{false, false, false},
+			issues: []string{
+				"10.0.0.1:53 (system) answers 192.0.2.1, expected 192.0.2.5",
+				"10.0.0.2:53 (custom) answers 192.0.2.1, expected 192.0.2.5",
+			},
+		},
+		{
+			name: "stale TTL",
+			resolvers: []models.ResolverAnswer{
+				answer("10.0.0.1:53", ResolverSourceSystem, 86400, "192.0.2.5"),
+				answer("198.51.100.1:53", ResolverSourceAuthoritative, 300, "192.0.2.5"),
+			},
+			expected: []string{"192.0.2.5"},
+			agrees:   []bool{true, true},
+			stale:    []bool{true, false},
+			issues:   []string{"10.0.0.1:53 (system) reports TTL 86400s, above the authoritative 300s"},
+		},
+		{
+			name: "authoritative servers disagree",
+			resolvers: []models.ResolverAnswer{
+				answer("198.51.100.1:53", ResolverSourceAuthoritative, 300, "192.0.2.5"),
+				answer("198.51.100.2:53", ResolverSourceAuthoritative, 300, "192.0.2.6"),
+			},
+			expected: []string{"192.0.2.5"},
+			agrees:   []bool{true, false},
+			stale:    []bool{false, false},
+			issues: []string{
+				"authoritative nameservers disagree with each other",
+				"198.51.100.2:53 (authoritative) answers 192.0.2.6, expected 192.0.2.5",
+			},
+		},
+		{
+			name: "rcode instead of records",
+			resolvers: []models.ResolverAnswer{
+				answer("10.0.0.1:53", ResolverSourceSystem, 300, "192.0.2.1"),
+				answer("10.0.0.2:53", ResolverSourceCustom, 300, "192.0.2.1"),
+				nxdomain,
+			},
+			expected: []string{"192.0.2.1"},
+			agrees:   []bool{true, true, false},
+			stale:    []bool{false, false, false},
+			issues: []string{
+				"resolvers disagree and no authoritative answer was available to break the tie",
+				"10.0.0.3:53 (custom) answers NXDOMAIN, expected 192.0.2.1",
+			},
+		},
+		{
+			name: "per-resolver error",
+			resolvers: []models.ResolverAnswer{
+				answer("10.0.0.1:53", ResolverSourceSystem, 300, "192.0.2.1"),
+				failed("10.0.0.2:53", ResolverSourceCustom, "i/o timeout"),
+				failed("198.51.100.1:53", ResolverSourceAuthoritative, "connection refused"),
+			},
+			expected: []string{"192.0.2.1"},
+			agrees:   []bool{true, false, false},
+			stale:    []bool{false, false, false},
+			issues: []string{
+				"10.0.0.2:53 (custom) failed: i/o timeout",
+				"198.51.100.1:53 (authoritative) failed: connection refused",
+			},
+		},
+		{
+			name: "every resolver failed",
+			resolvers: []models.ResolverAnswer{
+				failed("10.0.0.1:53", ResolverSourceSystem, "i/o timeout"),
+			},
+			expected: []string{},
+			agrees:   []bool{false},
+			stale:    []bool{false},
+			issues:   []string{"10.0.0.1:53 (system) failed: i/o timeout"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &models.DNSComparisonResult{Domain: "example.com", RecordType: "A", Resolvers: tt.resolvers}
+			compareAnswers(result)
+
+			if result.Consistent != tt.consistent {
+				t.Errorf("Consistent = %v, want %v", result.Consistent, tt.consistent)
+			}
+			if !reflect.DeepEqual(result.Expected, tt.expected) {
+				t.Errorf("Expected = %q, want %q", result.Expected, tt.expected)
+			}
+			for i, a := range result.Resolvers {
+				if a.Agrees != tt.agrees[i] || a.StaleTTL != tt.stale[i] {
+					t.Errorf("%s: agrees %v, stale %v; want %v, %v", a.Resolver, a.Agrees, a.StaleTTL, tt.agrees[i], tt.stale[i])
+				}
+			}
+			if !reflect.DeepEqual(result.Issues, tt.issues) {
+				t.Errorf("Issues = %q, want %q", result.Issues, tt.issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,413 @@
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsAnswer is what the stand-in server replies to one question.
+type dnsAnswer struct {
+	rcode   dnsmessage.RCode
+	answers []dnsmessage.Resource
+	// truncate sends an empty, truncated reply over UDP so the client has to
+	// retry over TCP, where the answers are sent.
+	truncate bool
+}
+
+// dnsServer is an in-process DNS server listening on the same port over UDP
+// and TCP, answering through handle and recording the questions it saw.
+type dnsServer struct {
+	addr   string
+	handle func(q dnsmessage.Question) dnsAnswer
+
+	mu        sync.Mutex
+	questions []string
+}
+
+func startDNSServer(t *testing.T, handle func(q dnsmessage.Question) dnsAnswer) *dnsServer {
+	t.Helper()
+	s := &dnsServer{handle: handle}
+
+	var udp net.PacketConn
+	var tcp net.Listener
+	for attempt := 0; ; attempt++ {
+		var err error
+		udp, err = net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tcp, err = net.Listen("tcp", udp.LocalAddr().String())
+		if err == nil {
+			break
+		}
+		udp.Close()
+		if attempt == 10 {
+			t.Fatalf("no port free for both UDP and TCP: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		udp.Close()
+		tcp.Close()
+	})
+	s.addr = udp.LocalAddr().String()
+
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := udp.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply := s.reply(buf[:n], "udp"); reply != nil {
+				udp.WriteTo(reply, addr)
+			}
+		}
+	}()
+	go func() {
+		for {
+			conn, err := tcp.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				var length [2]byte
+				if _, err := io.ReadFull(conn, length[:]); err != nil {
+					return
+				}
+				query := make([]byte, binary.BigEndian.Uint16(length[:]))
+				if _, err := io.ReadFull(conn, query); err != nil {
+					return
+				}
+				reply := s.reply(query, "tcp")
+				framed := binary.BigEndian.AppendUint16(nil, uint16(len(reply)))
+				conn.Write(append(framed, reply...))
+			}()
+		}
+	}()
+
+	return s
+}
+
+func (s *dnsServer) reply(query []byte, network string) []byte {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil || len(msg.Questions) != 1 {
+		return nil
+	}
+	q := msg.Questions[0]
+
+	s.mu.Lock()
+	s.questions = append(s.questions, network+" "+dnsTypeName(q.Type)+" "+q.Name.String())
+	s.mu.Unlock()
+
+	answer := s.handle(q)
+	reply := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 msg.ID,
+			Response:           true,
+			RecursionDesired:   true,
+			RecursionAvailable: true,
+			RCode:              answer.rcode,
+		},
+		Questions: msg.Questions,
+	}
+	if answer.truncate && network == "udp" {
+		reply.Truncated = true
+	} else {
+		reply.Answers = answer.answers
+	}
+	packed, err := reply.Pack()
+	if err != nil {
+		panic(err)
+	}
+	return packed
+}
+
+func (s *dnsServer) seen(question string) bool {
+	for _, q := range s.seenQuestions() {
+		if q == question {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *dnsServer) seenQuestions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.questions...)
+}
+
+func rrHeader(name string, t dnsmessage.Type) dnsmessage.ResourceHeader {
+	return dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Type: t, Class: dnsmessage.ClassINET, TTL: 300}
+}
+
+func rrA(name string, ip string) dnsmessage.Resource {
+	var a [4]byte
+	copy(a[:], net.ParseIP(ip).To4())
+	return dnsmessage.Resource{Header: rrHeader(name, dnsmessage.TypeA), Body: &dnsmessage.AResource{A: a}}
+}
+
+func rrCNAME(name, target string) dnsmessage.Resource {
+	return dnsmessage.Resource{Header: rrHeader(name, dnsmessage.TypeCNAME), Body: &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName(target)}}
+}
+
+func rrCAA(name string, flags byte, tag, value string) dnsmessage.Resource {
+	data := append([]byte{flags, byte(len(tag))}, tag...)
+	data = append(data, value...)
+	return dnsmessage.Resource{Header: rrHeader(name, typeCAA), Body: &dnsmessage.UnknownResource{Type: typeCAA, Data: data}}
+}
+
+// cnameZone answers every question for an alias with its CNAME, as real
+// servers do, and A questions for the other names with their address.
+func cnameZone(aliases map[string]string, addresses map[string]string) func(q dnsmessage.Question) dnsAnswer {
+	return func(q dnsmessage.Question) dnsAnswer {
+		name := q.Name.String()
+		if target, ok := aliases[name]; ok {
+			return dnsAnswer{answers: []dnsmessage.Resource{rrCNAME(name, target)}}
+		}
+		if ip, ok := addresses[name]; ok && q.Type == dnsmessage.TypeA {
+			return dnsAnswer{answers: []dnsmessage.Resource{rrA(name, ip)}}
+		}
+		return dnsAnswer{}
+	}
+}
+
+func lookupWithServer(t *testing.T, server *dnsServer, domain string) (*dnsResultView, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := &DNSClient{Server: server.addr, Timeout: 2 * time.Second}
+	result, err := client.Lookup(ctx, domain)
+	view := &dnsResultView{chain: result.CNAMEChain, rcodes: make(map[string]string)}
+	for _, record := range result.Records {
+		view.records = append(view.records, record.Name+" "+record.Type+" "+record.Value)
+	}
+	for _, query := range result.Queries {
+		view.rcodes[query.Type] = query.Rcode
+	}
+	return view, err
+}
+
+type dnsResultView struct {
+	records []string
+	chain   []string
+	rcodes  map[string]string
+}
+
+func (v *dnsResultView) has(record string) bool {
+	for _, r := range v.records {
+		if r == record {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDNSLookupFallsBackToTCPWhenTruncated(t *testing.T) {
+	server := startDNSServer(t, func(q dnsmessage.Question) dnsAnswer {
+		if q.Type != dnsmessage.TypeA {
+			return dnsAnswer{}
+		}
+		return dnsAnswer{truncate: true, answers: []dnsmessage.Resource{rrA("big.test.", "192.0.2.10"), rrA("big.test.", "192.0.2.11")}}
+	})
+
+	result, err := lookupWithServer(t, server, "big.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !server.seen("udp A big.test.") || !server.seen("tcp A big.test.") {
+		t.Errorf("expected the A question over UDP then TCP, saw %q", server.seenQuestions())
+	}
+	if server.seen("tcp AAAA big.test.") {
+		t.Error("untruncated answers must not be retried over TCP")
+	}
+	for _, record := range []string{"big.test. A 192.0.2.10", "big.test. A 192.0.2.11"} {
+		if !result.has(record) {
+			t.Errorf("records %q lack %q", result.records, record)
+		}
+	}
+}
+
+func TestDNSLookupFollowsCNAMEChain(t *testing.T) {
+	server := startDNSServer(t, cnameZone(
+		map[string]string{"www.test.": "edge.test.", "edge.test.": "origin.test."},
+		map[string]string{"origin.test.": "192.0.2.20"},
+	))
+
+	result, err := lookupWithServer(t, server, "www.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(result.chain, " -> "); got != "www.test. -> edge.test. -> origin.test." {
+		t.Errorf("chain = %s", got)
+	}
+	if !result.has("origin.test. A 192.0.2.20") {
+		t.Errorf("records %q lack the address at the end of the chain", result.records)
+	}
+}
+
+func TestDNSLookupCNAMELimits(t *testing.T) {
+	long := make(map[string]string)
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"}
+	for i := 0; i+1 < len(names); i++ {
+		long[names[i]+".test."] = names[i+1] + ".test."
+	}
+
+	tests := []struct {
+		name    string
+		aliases map[string]string
+		err     string
+	}{
+		{"too long", long, "CNAME chain longer than 8 hops"},
+		{"loop", map[string]string{"a.test.": "b.test.", "b.test.": "a.test."}, "CNAME loop detected at a.test."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startDNSServer(t, cnameZone(tt.aliases, nil))
+			_, err := lookupWithServer(t, server, "a.test")
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDNSLookupRcodes(t *testing.T) {
+	for _, tt := range []struct {
+		rcode dnsmessage.RCode
+		name  string
+	}{
+		{dnsmessage.RCodeNameError, "NXDOMAIN"},
+		{dnsmessage.RCodeServerFailure, "SERVFAIL"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startDNSServer(t, func(q dnsmessage.Question) dnsAnswer {
+				return dnsAnswer{rcode: tt.rcode}
+			})
+
+			result, err := lookupWithServer(t, server, "missing.test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.records) != 0 || result.chain != nil {
+				t.Errorf("records = %q, chain = %q; want none", result.records, result.chain)
+			}
+			for _, qtype := range dnsQueryTypes {
+				if got := result.rcodes[dnsTypeName(qtype)]; got != tt.name {
+					t.Errorf("%s rcode = %q, want %s", dnsTypeName(qtype), got, tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestDNSLookupCAA(t *testing.T) {
+	server := startDNSServer(t, func(q dnsmessage.Question) dnsAnswer {
+		if q.Type != typeCAA {
+			return dnsAnswer{}
+		}
+		return dnsAnswer{answers: []dnsmessage.Resource{
+			rrCAA("ca.test.", 0, "issue", "letsencrypt.org"),
+			rrCAA("ca.test.", 128, "iodef", `mailto:"sec"@ca.test`),
+		}}
+	})
+
+	result, err := lookupWithServer(t, server, "ca.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range []string{`ca.test. CAA 0 issue "letsencrypt.org"`, `ca.test. CAA 128 iodef "mailto:\"sec\"@ca.test"`} {
+		if !result.has(record) {
+			t.Errorf("records %q lack %s", result.records, record)
+		}
+	}
+}
+
+func TestFormatCAAMalformed(t *testing.T) {
+	for data, want := range map[string]string{
+		"":                `\# 0 `,
+		"\x00":            `\# 1 00`,
+		"\x00\x09issue":   `\# 7 00096973737565`,
+		"\x00\x05issue;x": `0 issue ";x"`,
+	} {
+		if got := formatCAA([]byte(data)); got != want {
+			t.Errorf("formatCAA(%q) = %s, want %s", data, got, want)
+		}
+	}
+}
+
+func TestDNSLookupStripsPort(t *testing.T) {
+	server := startDNSServer(t, cnameZone(nil, map[string]string{"web.test.": "192.0.2.30"}))
+
+	result, err := lookupWithServer(t, server, "web.test:8443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !server.seen("udp A web.test.") || !result.has("web.test. A 192.0.2.30") {
+		t.Errorf("questions = %q, records = %q; want web.test. without the port", server.seenQuestions(), result.records)
+	}
+}
+
+func TestNSLookupStripsPort(t *testing.T) {
+	server := startDNSServer(t, cnameZone(nil, map[string]string{"web.test.": "192.0.2.30"}))
+	u := NewNetworkDebugUsecase(zap.NewNop(), nil)
+	u.Resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.addr)
+		},
+	}
+
+	for _, domain := range []string{"web.test", "web.test:8443"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		result, err := u.runNSLookup(ctx, domain)
+		cancel()
+		if err != nil || result.IP != "192.0.2.30" {
+			t.Errorf("runNSLookup(%s) = %+v, %v; want 192.0.2.30", domain, result, err)
+		}
+	}
+}
+
+func TestDNSLookupServerDown(t *testing.T) {
+	// A TCP listener's port has no UDP socket, so queries are refused.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	client := &DNSClient{Server: l.Addr().String(), Timeout: time.Second}
+	result, err := client.Lookup(context.Background(), "down.test")
+	if err == nil {
+		t.Fatal("expected an error when every query fails")
+	}
+	for _, query := range result.Queries {
+		if query.Error == "" {
+			t.Errorf("%s query has no error", query.Type)
+		}
+	}
+}
+
+func TestDNSQueryIDsVary(t *testing.T) {
+	seen := make(map[uint16]bool)
+	for i := 0; i < 64; i++ {
+		id, err := dnsQueryID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[id] = true
+	}
+	if len(seen) < 32 {
+		t.Errorf("64 query IDs had only %d distinct values", len(seen))
+	}
+}
@@ -0,0 +1,36 @@
+package network
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// hostInfo describes this machine, so a saved result or report tells where
+// the diagnostics ran from. Every field is best effort.
+func hostInfo(procFS string) models.HostInfo {
+	info := models.HostInfo{
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		Addresses: []string{},
+	}
+	info.Hostname, _ = os.Hostname()
+	if release, err := os.ReadFile(filepath.Join(procFS, "sys", "kernel", "osrelease")); err == nil {
+		info.Kernel = strings.TrimSpace(string(release))
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return info
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+			info.Addresses = append(info.Addresses, ipNet.String())
+		}
+	}
+	return info
+}
@@ -0,0 +1,345 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+const (
+	maxHTTPRedirects = 10
+	// maxBodyMatchBytes bounds how much of the body is kept for --expect-body-contains.
+	maxBodyMatchBytes = 1 << 20
+	// maxBodyReadBytes bounds how much of each body is downloaded, so a huge
+	// or endless response cannot stall the check; the rest is left unread.
+	maxBodyReadBytes = 32 << 20
+)
+
+// capturedHTTPHeaders are the response headers kept in the report.
+var capturedHTTPHeaders = []string{
+	"Server",
+	"Content-Type",
+	"Content-Length",
+	"Cache-Control",
+	"Age",
+	"Via",
+	"X-Cache",
+	"Strict-Transport-Security",
+	"Retry-After",
+	"Date",
+}
+
+// HTTPProbeOptions configures the http check.
+type HTTPProbeOptions struct {
+	// URL to request; defaults to http://<domain>/.
+	URL    string
+	Method string
+	Header http.Header
+	// ExpectStatus lists the acceptable status codes; empty accepts anything.
+	ExpectStatus       []StatusRange
+	ExpectBodyContains string
+}
+
+// StatusRange is an inclusive range of HTTP status codes.
+type StatusRange struct {
+	Min, Max int
+}
+
+func (r StatusRange) Contains(code int) bool {
+	return code >= r.Min && code <= r.Max
+}
+
+func (r StatusRange) String() string {
+	if r.Min == r.Max {
+		return strconv.Itoa(r.Min)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// ParseStatusRanges parses values such as "200", "200-299" or "2xx".
+func ParseStatusRanges(values []string) ([]StatusRange, error) {
+	var ranges []StatusRange
+	for _, value := range values {
+		value = strings.TrimSpace(strings.ToLower(value))
+		if value == "" {
+			continue
+		}
+
+		if len(value) == 3 && strings.HasSuffix(value, "xx") {
+			class, err := strconv.Atoi(value[:1])
+			if err != nil || class < 1 || class > 5 {
+				return nil, fmt.Errorf("invalid status class '%s'", value)
+			}
+			ranges = append(ranges, StatusRange{Min: class * 100, Max: class*100 + 99})
+			continue
+		}
+
+		low, high, isRange := strings.Cut(value, "-")
+		min, err := strconv.Atoi(low)
+		if err != nil || !validStatus(min) {
+			return nil, fmt.Errorf("invalid status code '%s'", value)
+		}
+		max := min
+		if isRange {
+			if max, err = strconv.Atoi(high); err != nil || max < min || !validStatus(max) {
+				return nil, fmt.Errorf("invalid status range '%s'", value)
+			}
+		}
+		ranges = append(ranges, StatusRange{Min: min, Max: max})
+	}
+	return ranges, nil
+}
+
+func validStatus(code int) bool {
+	return code >= 100 && code <= 599
+}
+
+// ParseHeaders parses "Name: value" pairs as given to --header.
+func ParseHeaders(values []string) (http.Header, error) {
+	header := make(http.Header)
+	for _, value := range values {
+		name, v, ok := strings.Cut(value, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header '%s', expected 'Name: value'", value)
+		}
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(v))
+	}
+	return header, nil
+}
+
+// runHTTP requests the target, following redirects one hop at a time so each
+// hop is recorded, and breaks the final request down into phases with
+// httptrace. Failed expectations are returned as an error alongside the result.
+func (u *NetworkDebugUsecase) runHTTP(ctx context.Context, domain string, opts HTTPProbeOptions) (models.HTTPRequestResult, error) {
+	target := opts.URL
+	if target == "" {
+		target = "http://" + domain + "/"
+	}
+	method := strings.ToUpper(opts.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	// A fresh transport per probe so every hop pays (and reports) its own
+	// DNS, connect and handshake costs.
+	transport := &http.Transport{
+		Proxy:             http.ProxyFromEnvironment,
+		DisableKeepAlives: true,
+		ForceAttemptHTTP2: true,
+		TLSClientConfig:   &tls.Config{},
+	}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   30 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	result := models.HTTPRequestResult{
+		Method:    method,
+		Redirects: []models.HTTPRedirect{},
+		Headers:   map[string]string{},
+	}
+
+	start := time.Now()
+	var body []byte
+	for hop := 0; ; hop++ {
+		resp, timings, hopBody, err := doTracedRequest(ctx, client, method, target, opts.Header)
+		if err != nil {
+			result.URL = target
+			result.Timings.Total = time.Since(start)
+			return result, err
+		}
+
+		location := resp.Header.Get("Location")
+		if isRedirect(resp.StatusCode) && location != "" {
+			next, err := url.Parse(target)
+			if err == nil {
+				next, err = next.Parse(location)
+			}
+			if err != nil {
+				return result, fmt.Errorf("invalid redirect location '%s': %w", location, err)
+			}
+			result.Redirects = append(result.Redirects, models.HTTPRedirect{
+				URL:        target,
+				StatusCode: resp.StatusCode,
+				Location:   next.String(),
+				Duration:   timings.Total,
+			})
+			if hop+1 >= maxHTTPRedirects {
+				return result, fmt.Errorf("stopped after %d redirects", maxHTTPRedirects)
+			}
+			// Browsers and curl -L turn POSTs into GETs on these codes.
+			if resp.StatusCode != http.StatusTemporaryRedirect && resp.StatusCode != http.StatusPermanentRedirect && method != http.MethodHead {
+				method = http.MethodGet
+			}
+			target = next.String()
+			continue
+		}
+
+		result.URL = target
+		result.StatusCode = resp.StatusCode
+		result.Status = fmt.Sprintf("HTTP %s", resp.Status)
+		result.Proto = resp.Proto
+		result.ContentType = resp.Header.Get("Content-Type")
+		result.ContentLength = timings.bodyBytes
+		result.Timings = timings.HTTPTimings
+		for _, name := range capturedHTTPHeaders {
+			if value := resp.Header.Get(name); value != "" {
+				result.Headers[name] = value
+			}
+		}
+		body = hopBody
+		break
+	}
+	result.Timings.Total = time.Since(start)
+
+	var failed []string
+	if len(opts.ExpectStatus) > 0 {
+		expectation := models.HTTPExpectation{
+			Description: "status in " + joinStatusRanges(opts.ExpectStatus),
+			Actual:      strconv.Itoa(result.StatusCode),
+		}
+		for _, r := range opts.ExpectStatus {
+			if r.Contains(result.StatusCode) {
+				expectation.Passed = true
+				break
+			}
+		}
+		result.Expectations = append(result.Expectations, expectation)
+		if !expectation.Passed {
+			failed = append(failed, fmt.Sprintf("expected status %s, got %d", joinStatusRanges(opts.ExpectStatus), result.StatusCode))
+		}
+	}
+	if opts.ExpectBodyContains != "" {
+		expectation := models.HTTPExpectation{
+			Description: fmt.Sprintf("body contains %q", opts.ExpectBodyContains),
+			Passed:      bytes.Contains(body, []byte(opts.ExpectBodyContains)),
+		}
+		result.Expectations = append(result.Expectations, expectation)
+		if !expectation.Passed {
+			failed = append(failed, fmt.Sprintf("body does not contain %q", opts.ExpectBodyContains))
+		}
+	}
+
+	if len(failed) > 0 {
+		return result, errors.New(strings.Join(failed, "; "))
+	}
+	return result, nil
+}
+
+type hopTimings struct {
+	models.HTTPTimings
+	bodyBytes int64
+}
+
+func doTracedRequest(ctx context.Context, client *http.Client, method, target string, header http.Header) (*http.Response, hopTimings, []byte, error) {
+	var timings hopTimings
+	var dnsStart, connectStart, tlsStart, wroteRequest, firstByte time.Time
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			if !dnsStart.IsZero() {
+				timings.DNSLookup = time.Since(dnsStart)
+			}
+		},
+		ConnectStart: func(string, string) { connectStart = time.Now() },
+		ConnectDone: func(string, string, error) {
+			if !connectStart.IsZero() {
+				timings.TCPConnect = time.Since(connectStart)
+			}
+		},
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			if !tlsStart.IsZero() {
+				timings.TLSHandshake = time.Since(tlsStart)
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { wroteRequest = time.Now() },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), method, target, nil)
+	if err != nil {
+		return nil, timings, nil, fmt.Errorf("error creating HTTP request: %w", err)
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if host := header.Get("Host"); host != "" {
+		req.Host = host
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, timings, nil, err
+	}
+	defer resp.Body.Close()
+
+	var body bytes.Buffer
+	n, err := io.Copy(io.Discard, io.TeeReader(io.LimitReader(resp.Body, maxBodyReadBytes), &limitedBuffer{buf: &body, limit: maxBodyMatchBytes}))
+	if err != nil {
+		return nil, timings, nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	done := time.Now()
+
+	timings.bodyBytes = n
+	if !wroteRequest.IsZero() && !firstByte.IsZero() {
+		timings.TimeToFirstByte = firstByte.Sub(wroteRequest)
+		timings.Transfer = done.Sub(firstByte)
+	}
+	timings.Total = done.Sub(start)
+
+	return resp, timings, body.Bytes(), nil
+}
+
+// limitedBuffer keeps the first limit bytes written and silently drops the rest.
+type limitedBuffer struct {
+	buf   *bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func isRedirect(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+func joinStatusRanges(ranges []StatusRange) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,290 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+func TestParseStatusRanges(t *testing.T) {
+	tests := []struct {
+		values  []string
+		want    []StatusRange
+		wantErr string
+	}{
+		{values: []string{"200"}, want: []StatusRange{{200, 200}}},
+		{values: []string{"200-299", " 3XX ", ""}, want: []StatusRange{{200, 299}, {300, 399}}},
+		{values: []string{"1xx", "5xx"}, want: []StatusRange{{100, 199}, {500, 599}}},
+		{values: []string{"204-204"}, want: []StatusRange{{204, 204}}},
+		{values: nil, want: nil},
+		{values: []string{"ok"}, wantErr: "invalid status code 'ok'"},
+		{values: []string{"6xx"}, wantErr: "invalid status class '6xx'"},
+		{values: []string{"axx"}, wantErr: "invalid status class 'axx'"},
+		{values: []string{"99"}, wantErr: "invalid status code '99'"},
+		{values: []string{"200-"}, wantErr: "invalid status range '200-'"},
+		{values: []string{"-200"}, wantErr: "invalid status code '-200'"},
+		{values: []string{"299-200"}, wantErr: "invalid status range '299-200'"},
+		{values: []string{"200-600"}, wantErr: "invalid status range '200-600'"},
+		{values: []string{"2xx-3xx"}, wantErr: "invalid status code '2xx-3xx'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.values, ","), func(t *testing.T) {
+			got, err := ParseStatusRanges(tt.values)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStatusRanges(%q) = %v, %v; want %v", tt.values, got, err, tt.want)
+			}
+		})
+	}
+
+	ranges := []StatusRange{{200, 299}, {304, 304}}
+	if joinStatusRanges(ranges) != "200-299,304" || !ranges[1].Contains(304) || ranges[0].Contains(300) {
+		t.Errorf("ranges %v render as %s", ranges, joinStatusRanges(ranges))
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	tests := []struct {
+		values  []string
+		want    http.Header
+		wantErr string
+	}{
+		{values: []string{"Authorization: Bearer a:b"}, want: http.Header{"Authorization": {"Bearer a:b"}}},
+		{values: []string{"x-trace:1", "X-Trace: 2"}, want: http.Header{"X-Trace": {"1", "2"}}},
+		{values: []string{"X-Empty:"}, want: http.Header{"X-Empty": {""}}},
+		{values: nil, want: http.Header{}},
+		{values: []string{"NoColon"}, wantErr: "invalid header 'NoColon', expected 'Name: value'"},
+		{values: []string{" : value"}, wantErr: "invalid header ' : value', expected 'Name: value'"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseHeaders(tt.values)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ParseHeaders(%q) error = %v, want %q", tt.values, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseHeaders(%q) = %v, %v; want %v", tt.values, got, err, tt.want)
+		}
+	}
+}
+
+// redirectServer redirects /loop to itself, /see-other and /temporary to
+// /final with 303 and 307, /moved to /found to /final with 301 and 302, and
+// answers /final with the method it got, after a short delay.
+func redirectServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	redirect := func(path, to string, code int) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, to, code)
+		})
+	}
+	redirect("/moved", "/found", http.StatusMovedPermanently)
+	redirect("/found", "/final", http.StatusFound)
+	redirect("/see-other", "/final", http.StatusSeeOther)
+	redirect("/temporary", "/final", http.StatusTemporaryRedirect)
+	redirect("/loop", "/loop", http.StatusFound)
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Ignored", "1")
+		fmt.Fprintf(w, "%s %s", r.Method, r.Header.Get("X-Probe"))
+	})
+	mux.HandleFunc("/status/", func(w http.ResponseWriter, r *http.Request) {
+		var code int
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/status/"), "%d", &code)
+		w.WriteHeader(code)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunHTTPRedirects(t *testing.T) {
+	server := redirectServer(t)
+	u := NewNetworkDebugUsecase(zap.NewNop(), nil)
+
+	result, err := u.runHTTP(context.Background(), "", HTTPProbeOptions{
+		URL:    server.URL + "/moved",
+		Header: http.Header{"X-Probe": {"teemo"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []models.HTTPRedirect{
+		{URL: server.URL + "/moved", StatusCode: http.StatusMovedPermanently, Location: server.URL + "/found"},
+		{URL: server.URL + "/found", StatusCode: http.StatusFound, Location: server.URL + "/final"},
+	}
+	if len(result.Redirects) != len(want) {
+		t.Fatalf("redirects = %+v, want %+v", result.Redirects, want)
+	}
+	for i, hop := range result.Redirects {
+		if hop.Duration <= 0 {
+			t.Errorf("hop %d has no duration", i)
+		}
+		hop.Duration = 0
+		if hop != want[i] {
+			t.Errorf("hop %d = %+v, want %+v", i, hop, want[i])
+		}
+	}
+
+	if result.URL != server.URL+"/final" || result.StatusCode != http.StatusOK || result.Status != "HTTP 200 OK" || result.Proto != "HTTP/1.1" {
+		t.Errorf("final response = %s %d %q %s", result.URL, result.StatusCode, result.Status, result.Proto)
+	}
+	// Headers are sent on every hop; only the captured response headers are kept.
+	if result.ContentLength != int64(len("GET teemo")) || result.ContentType != "text/plain" {
+		t.Errorf("body of %d bytes, type %q", result.ContentLength, result.ContentType)
+	}
+	if result.Headers["Cache-Control"] != "no-store" || result.Headers["X-Ignored"] != "" {
+		t.Errorf("headers = %v", result.Headers)
+	}
+
+	timings := result.Timings
+	if timings.TCPConnect <= 0 || timings.TimeToFirstByte < 20*time.Millisecond || timings.Total < timings.TimeToFirstByte || timings.TLSHandshake != 0 {
+		t.Errorf("timings = %+v, want a connect, a first byte after the 20ms delay and no TLS", timings)
+	}
+}
+
+func TestRunHTTPRedirectMethods(t *testing.T) {
+	server := redirectServer(t)
+	u := NewNetworkDebugUsecase(zap.NewNop(), nil)
+
+	tests := []struct {
+		path   string
+		method string
+		want   string
+	}{
+		{path: "/found", method: http.MethodPost, want: "GET "},
+		{path: "/see-other", method: http.MethodPut, want: "GET "},
+		{path: "/temporary", method: http.MethodPost, want: "POST "},
+		{path: "/found", method: http.MethodHead, want: ""},
+	}
+	for _, tt := range tests {
+		result, err := u.runHTTP(context.Background(), "", HTTPProbeOptions{
+			URL:                server.URL + tt.path,
+			Method:             strings.ToLower(tt.method),
+			ExpectBodyContains: tt.want,
+		})
+		if err != nil && tt.want != "" {
+			t.Errorf("%s %s: %v", tt.method, tt.path, err)
+		}
+		if result.StatusCode != http.StatusOK {
+			t.Errorf("%s %s ended with %d", tt.method, tt.path, result.StatusCode)
+		}
+	}
+
+	result, err := u.runHTTP(context.Background(), "", HTTPProbeOptions{URL: server.URL + "/loop"})
+	if err == nil || err.Error() != "stopped after 10 redirects" || len(result.Redirects) != maxHTTPRedirects {
+		t.Errorf("loop = %d redirects, %v; want to stop after %d", len(result.Redirects), err, maxHTTPRedirects)
+	}
+}
+
+func TestRunHTTPExpectations(t *testing.T) {
+	server := redirectServer(t)
+	u := NewNetworkDebugUsecase(zap.NewNop(), nil)
+	twoxx := []StatusRange{{200, 299}}
+
+	tests := []struct {
+		name    string
+		path    string
+		status  []StatusRange
+		body    string
+		passed  []bool
+		wantErr string
+	}{
+		{name: "no expectations", path: "/status/503"},
+		{name: "status in range", path: "/status/204", status: twoxx, passed: []bool{true}},
+		{name: "status outside range", path: "/status/503", status: twoxx, passed: []bool{false}, wantErr: "expected status 200-299, got 503"},
+		{name: "several ranges", path: "/status/404", status: []StatusRange{{200, 299}, {404, 404}}, passed: []bool{true}},
+		{name: "body found", path: "/final", body: "GET", passed: []bool{true}},
+		{
+			name:    "both failing",
+			path:    "/final",
+			status:  []StatusRange{{500, 599}},
+			body:    "welcome",
+			passed:  []bool{false, false},
+			wantErr: `expected status 500-599, got 200; body does not contain "welcome"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := u.runHTTP(context.Background(), "", HTTPProbeOptions{
+				URL:                server.URL + tt.path,
+				ExpectStatus:       tt.status,
+				ExpectBodyContains: tt.body,
+			})
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+			if len(result.Expectations) != len(tt.passed) {
+				t.Fatalf("expectations = %+v, want %d", result.Expectations, len(tt.passed))
+			}
+			for i, expectation := range result.Expectations {
+				if expectation.Passed != tt.passed[i] {
+					t.Errorf("%s passed = %v, want %v", expectation.Description, expectation.Passed, tt.passed[i])
+				}
+			}
+			if len(tt.status) > 0 && result.Expectations[0].Actual != fmt.Sprint(result.StatusCode) {
+				t.Errorf("status expectation reports %q for %d", result.Expectations[0].Actual, result.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRunHTTPLimitsBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// An endless body.
+		io.Copy(w, zeroReader{})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := NewNetworkDebugUsecase(zap.NewNop(), nil).runHTTP(ctx, "", HTTPProbeOptions{URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ContentLength != maxBodyReadBytes {
+		t.Errorf("read %d bytes, want the %d limit", result.ContentLength, maxBodyReadBytes)
+	}
+}
+
+func TestRunHTTPConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	result, err := NewNetworkDebugUsecase(zap.NewNop(), nil).runHTTP(context.Background(), "", HTTPProbeOptions{URL: url})
+	if err == nil || result.URL != url || result.StatusCode != 0 {
+		t.Errorf("result = %+v, %v; want the connection error", result, err)
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
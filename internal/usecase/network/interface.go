@@ -0,0 +1,233 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// DefaultInterfaceWindow is how long the interface check samples counters.
+const DefaultInterfaceWindow = 5 * time.Second
+
+// rtfUp is the RTF_UP route flag.
+const rtfUp = 0x1
+
+// runInterface samples the counters of iface, or of the interface holding the
+// default route when iface is empty, over window and reports the rates.
+func (u *NetworkDebugUsecase) runInterface(ctx context.Context, iface string, window time.Duration) (models.InterfaceResult, error) {
+	if window <= 0 {
+		window = DefaultInterfaceWindow
+	}
+
+	result := models.InterfaceResult{Name: iface}
+	if iface == "" {
+		detected, err := defaultRouteInterface(u.ProcFS)
+		if err != nil {
+			return result, fmt.Errorf("could not detect the default interface, pass --interface: %w", err)
+		}
+		result.Name = detected
+		result.AutoDetected = true
+	}
+
+	before, err := readInterfaceCounters(u.ProcFS, result.Name)
+	if err != nil {
+		return result, err
+	}
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return result, ctx.Err()
+	case <-time.After(window):
+	}
+
+	after, err := readInterfaceCounters(u.ProcFS, result.Name)
+	if err != nil {
+		return result, err
+	}
+	elapsed := time.Since(start)
+
+	result.Window = elapsed
+	result.Totals = after
+	result.Delta = counterDeltas(before, after)
+	seconds := elapsed.Seconds()
+	result.RxBytesPerSec = float64(result.Delta.RxBytes) / seconds
+	result.TxBytesPerSec = float64(result.Delta.TxBytes) / seconds
+	result.RxPacketsPerSec = float64(result.Delta.RxPackets) / seconds
+	result.TxPacketsPerSec = float64(result.Delta.TxPackets) / seconds
+
+	sysDir := filepath.Join(u.SysFS, "class", "net", result.Name)
+	result.OperState = readSysString(filepath.Join(sysDir, "operstate"))
+	result.MAC = readSysString(filepath.Join(sysDir, "address"))
+	result.MTU = readSysInt(filepath.Join(sysDir, "mtu"))
+	// Virtual interfaces report -1 or refuse the read.
+	if speed := readSysInt(filepath.Join(sysDir, "speed")); speed > 0 {
+		result.SpeedMbps = speed
+	}
+
+	return result, nil
+}
+
+// counterDeltas returns how much each counter grew between two samples.
+func counterDeltas(before, after models.InterfaceCounters) models.InterfaceCounters {
+	return models.InterfaceCounters{
+		RxBytes:   counterDelta(before.RxBytes, after.RxBytes),
+		RxPackets: counterDelta(before.RxPackets, after.RxPackets),
+		RxErrors:  counterDelta(before.RxErrors, after.RxErrors),
+		RxDropped: counterDelta(before.RxDropped, after.RxDropped),
+		TxBytes:   counterDelta(before.TxBytes, after.TxBytes),
+		TxPackets: counterDelta(before.TxPackets, after.TxPackets),
+		TxErrors:  counterDelta(before.TxErrors, after.TxErrors),
+		TxDropped: counterDelta(before.TxDropped, after.TxDropped),
+	}
+}
+
+// counterDelta is 0 when the counter went backwards, as it does when a driver
+// resets it or a 32-bit counter wraps, instead of an underflowed huge value.
+func counterDelta(before, after uint64) uint64 {
+	if after < before {
+		return 0
+	}
+	return after - before
+}
+
+// defaultRouteInterface returns the interface of the IPv4 default route with
+// the lowest metric, falling back to the IPv6 default route.
+func defaultRouteInterface(procFS string) (string, error) {
+	if f, err := os.Open(filepath.Join(procFS, "net", "route")); err == nil {
+		iface := parseDefaultRoute(f)
+		f.Close()
+		if iface != "" {
+			return iface, nil
+		}
+	}
+
+	if f, err := os.Open(filepath.Join(procFS, "net", "ipv6_route")); err == nil {
+		iface := parseDefaultRoute6(f)
+		f.Close()
+		if iface != "" {
+			return iface, nil
+		}
+	}
+
+	return "", fmt.Errorf("no default route found")
+}
+
+func parseDefaultRoute(r io.Reader) string {
+	best, bestMetric := "", -1
+	scanner := bufio.NewScanner(r)
+	scanner.Scan() // Skip the header
+	for scanner.Scan() {
+		// Iface Destination Gateway Flags RefCnt Use Metric Mask MTU Window IRTT
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil || flags&rtfUp == 0 {
+			continue
+		}
+		metric, _ := strconv.Atoi(fields[6])
+		if bestMetric == -1 || metric < bestMetric {
+			best, bestMetric = fields[0], metric
+		}
+	}
+	return best
+}
+
+func parseDefaultRoute6(r io.Reader) string {
+	best, bestMetric := "", uint64(0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// dst dst_len src src_len next_hop metric refcnt use flags iface
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || strings.Trim(fields[0], "0") != "" || fields[1] != "00" || fields[9] == "lo" {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[8], 16, 32)
+		if err != nil || flags&rtfUp == 0 {
+			continue
+		}
+		metric, _ := strconv.ParseUint(fields[5], 16, 32)
+		if best == "" || metric < bestMetric {
+			best, bestMetric = fields[9], metric
+		}
+	}
+	return best
+}
+
+// readInterfaceCounters reads the line for iface from /proc/net/dev.
+func readInterfaceCounters(procFS, iface string) (models.InterfaceCounters, error) {
+	f, err := os.Open(filepath.Join(procFS, "net", "dev"))
+	if err != nil {
+		return models.InterfaceCounters{}, err
+	}
+	defer f.Close()
+
+	counters, ok, err := parseNetDev(f, iface)
+	if err != nil {
+		return counters, err
+	}
+	if !ok {
+		return counters, fmt.Errorf("interface '%s' not found", iface)
+	}
+	return counters, nil
+}
+
+func parseNetDev(r io.Reader, iface string) (models.InterfaceCounters, bool, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		name, data, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(name) != iface {
+			continue
+		}
+
+		// Receive: bytes packets errs drop fifo frame compressed multicast
+		// Transmit: bytes packets errs drop fifo colls carrier compressed
+		fields := strings.Fields(data)
+		if len(fields) < 16 {
+			return models.InterfaceCounters{}, false, fmt.Errorf("malformed /proc/net/dev line for %s", iface)
+		}
+		values := make([]uint64, len(fields))
+		for i, field := range fields {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return models.InterfaceCounters{}, false, fmt.Errorf("malformed /proc/net/dev line for %s", iface)
+			}
+			values[i] = v
+		}
+
+		return models.InterfaceCounters{
+			RxBytes:   values[0],
+			RxPackets: values[1],
+			RxErrors:  values[2],
+			RxDropped: values[3],
+			TxBytes:   values[8],
+			TxPackets: values[9],
+			TxErrors:  values[10],
+			TxDropped: values[11],
+		}, true, nil
+	}
+	return models.InterfaceCounters{}, false, scanner.Err()
+}
+
+func readSysString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readSysInt(path string) int {
+	v, _ := strconv.Atoi(readSysString(path))
+	return v
+}
@@ -0,0 +1,210 @@
+package network
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+func TestParseNetDev(t *testing.T) {
+	f, err := os.Open("testdata/proc/net/dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	counters, ok, err := parseNetDev(f, "eth0")
+	if err != nil || !ok {
+		t.Fatalf("parseNetDev(eth0) = %v, %v", ok, err)
+	}
+	want := models.InterfaceCounters{RxBytes: 411320, RxPackets: 483, TxBytes: 77622, TxPackets: 544}
+	if counters != want {
+		t.Errorf("eth0 counters = %+v, want %+v", counters, want)
+	}
+}
+
+func TestParseNetDevLines(t *testing.T) {
+	header := "Inter-|   Receive                                                |  Transmit\n" +
+		" face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed\n"
+
+	tests := []struct {
+		name  string
+		lines string
+		iface string
+		want  models.InterfaceCounters
+		found bool
+		err   string
+	}{
+		{
+			name:  "all counters",
+			lines: "  eth1: 100 10 1 2 0 0 0 0 200 20 3 4 0 0 0 0\n",
+			iface: "eth1",
+			want:  models.InterfaceCounters{RxBytes: 100, RxPackets: 10, RxErrors: 1, RxDropped: 2, TxBytes: 200, TxPackets: 20, TxErrors: 3, TxDropped: 4},
+			found: true,
+		},
+		{
+			name:  "no space after the colon",
+			lines: "wlan0:18446744073709551615 1 0 0 0 0 0 0 5 1 0 0 0 0 0 0\n",
+			iface: "wlan0",
+			want:  models.InterfaceCounters{RxBytes: 18446744073709551615, RxPackets: 1, TxBytes: 5, TxPackets: 1},
+			found: true,
+		},
+		{
+			name:  "prefix of another name",
+			lines: "  eth10: 1 1 0 0 0 0 0 0 1 1 0 0 0 0 0 0\n",
+			iface: "eth1",
+		},
+		{
+			name:  "missing interface",
+			lines: "    lo: 1 1 0 0 0 0 0 0 1 1 0 0 0 0 0 0\n",
+			iface: "eth0",
+		},
+		{
+			name:  "too few fields",
+			lines: "  eth0: 1 1 0 0 0 0 0 0 1 1 0 0\n",
+			iface: "eth0",
+			err:   "malformed /proc/net/dev line for eth0",
+		},
+		{
+			name:  "not a number",
+			lines: "  eth0: 1 1 0 0 0 0 0 0 1 -1 0 0 0 0 0 0\n",
+			iface: "eth0",
+			err:   "malformed /proc/net/dev line for eth0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counters, found, err := parseNetDev(strings.NewReader(header+tt.lines), tt.iface)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found != tt.found || counters != tt.want {
+				t.Errorf("parseNetDev = %+v, %v; want %+v, %v", counters, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestParseDefaultRoute(t *testing.T) {
+	header := "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n"
+
+	tests := []struct {
+		name   string
+		routes string
+		want   string
+	}{
+		{
+			name:   "default route",
+			routes: "eth0\t00000000\t010200C0\t0003\t0\t0\t0\t00000000\t0\t0\t0\n",
+			want:   "eth0",
+		},
+		{
+			name: "lowest metric wins",
+			routes: "wlan0\t00000000\t0101A8C0\t0003\t0\t0\t600\t00000000\t0\t0\t0\n" +
+				"eth0\t00000000\t010200C0\t0003\t0\t0\t100\t00000000\t0\t0\t0\n" +
+				"tun0\t00000000\t0100080A\t0003\t0\t0\t200\t00000000\t0\t0\t0\n",
+			want: "eth0",
+		},
+		{
+			name: "route that is not up",
+			routes: "eth0\t00000000\t010200C0\t0002\t0\t0\t0\t00000000\t0\t0\t0\n" +
+				"eth1\t00000000\t010300C0\t0003\t0\t0\t100\t00000000\t0\t0\t0\n",
+			want: "eth1",
+		},
+		{
+			name: "no default route",
+			routes: "eth0\t000200C0\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0\n" +
+				"eth0\t00000000\t010200C0\t0003\t0\t0\t0\t00FFFFFF\t0\t0\t0\n",
+		},
+		{
+			name:   "malformed flags",
+			routes: "eth0\t00000000\t010200C0\tzz\t0\t0\t0\t00000000\t0\t0\t0\n",
+		},
+		{name: "header only"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDefaultRoute(strings.NewReader(header + tt.routes)); got != tt.want {
+				t.Errorf("parseDefaultRoute = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDefaultRoute6(t *testing.T) {
+	f, err := os.Open("testdata/proc/net/ipv6_route")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// The fixture also holds the unreachable default route on lo.
+	if got := parseDefaultRoute6(f); got != "eth0" {
+		t.Errorf("parseDefaultRoute6 = %q, want eth0", got)
+	}
+
+	zero := "00000000000000000000000000000000"
+	routes := zero + " 00 " + zero + " 00 fe800000000000000000000000000001 00000400 00000001 00000000 00000003 wlan0\n" +
+		zero + " 00 " + zero + " 00 fe800000000000000000000000000002 00000064 00000001 00000000 00000003 eth1\n" +
+		zero + " 00 " + zero + " 00 fe800000000000000000000000000003 00000001 00000001 00000000 00000002 eth2\n"
+	if got := parseDefaultRoute6(strings.NewReader(routes)); got != "eth1" {
+		t.Errorf("parseDefaultRoute6 = %q, want eth1, the up route with the lowest metric", got)
+	}
+}
+
+func TestDefaultRouteInterface(t *testing.T) {
+	iface, err := defaultRouteInterface("testdata/proc")
+	if err != nil || iface != "eth0" {
+		t.Errorf("defaultRouteInterface = %q, %v; want eth0", iface, err)
+	}
+
+	if _, err := defaultRouteInterface(t.TempDir()); err == nil || err.Error() != "no default route found" {
+		t.Errorf("error = %v, want no default route found", err)
+	}
+}
+
+func TestCounterDeltas(t *testing.T) {
+	before := models.InterfaceCounters{RxBytes: 4294967000, RxPackets: 10, TxBytes: 500, TxPackets: 5, TxDropped: 3}
+	after := models.InterfaceCounters{RxBytes: 200, RxPackets: 12, TxBytes: 1500, TxPackets: 5}
+
+	want := models.InterfaceCounters{RxPackets: 2, TxBytes: 1000}
+	if got := counterDeltas(before, after); got != want {
+		t.Errorf("counterDeltas = %+v, want %+v", got, want)
+	}
+}
+
+func TestRunInterfaceFixture(t *testing.T) {
+	u := NewNetworkDebugUsecase(zap.NewNop(), NewFixtureRunner())
+	u.ProcFS = "testdata/proc"
+	u.SysFS = "testdata/sys"
+
+	result, err := u.runInterface(context.Background(), "", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Name != "eth0" || !result.AutoDetected || result.OperState != "up" ||
+		result.MAC != "02:fc:00:00:00:01" || result.MTU != 1400 || result.SpeedMbps != 0 {
+		t.Errorf("interface = %+v", result)
+	}
+	if result.Totals.RxBytes != 411320 || result.Delta != (models.InterfaceCounters{}) || result.RxBytesPerSec != 0 {
+		t.Errorf("totals = %+v, delta = %+v, rx rate = %v", result.Totals, result.Delta, result.RxBytesPerSec)
+	}
+
+	if _, err := u.runInterface(context.Background(), "wlan0", time.Millisecond); err == nil || err.Error() != "interface 'wlan0' not found" {
+		t.Errorf("error = %v, want interface 'wlan0' not found", err)
+	}
+}
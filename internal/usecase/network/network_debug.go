@@ -1,12 +1,10 @@
 package network
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"os/exec"
-	"strconv"
-	"strings"
+	"net"
+	"sort"
 	"sync"
 	"time"
 
@@ -15,362 +13,174 @@ import (
 )
 
 type NetworkDebugUsecase struct {
-    Logger *zap.Logger
+    Logger   *zap.Logger
+    Runner   CommandRunner
+    Resolver *net.Resolver
+    // ProcFS and SysFS are where procfs and sysfs are mounted; checks that read
+    // kernel state use them so they can be pointed at a captured copy.
+    ProcFS string
+    SysFS  string
 }
 
-func NewNetworkDebugUsecase(logger *zap.Logger) *NetworkDebugUsecase {
+func NewNetworkDebugUsecase(logger *zap.Logger, runner CommandRunner) *NetworkDebugUsecase {
     return &NetworkDebugUsecase{
-        Logger: logger,
+        Logger:   logger,
+        Runner:   runner,
+        Resolver: net.DefaultResolver,
+        ProcFS:   "/proc",
+        SysFS:    "/sys",
     }
 }
 
-func (u *NetworkDebugUsecase) NetworkDebug(ctx context.Context, domain string) (*models.NetworkDebugResult, []error) {
-    result := &models.NetworkDebugResult{}
+// DebugOptions selects and tunes the checks run by NetworkDebug.
+type DebugOptions struct {
+    // Checks to run; when empty every registered check runs.
+    Checks []Check
+    // DNSServer is queried by the dns check; empty means the first nameserver in /etc/resolv.conf.
+    DNSServer string
+    // TLSExpiryWarnDays flags certificates expiring within this many days.
+    TLSExpiryWarnDays int
+    // HTTP configures the request made by the http check.
+    HTTP HTTPProbeOptions
+    // Traceroute configures the probes sent by the traceroute check.
+    Traceroute TracerouteOptions
+    // Ping configures the probes sent by the ping check.
+    Ping PingOptions
+    // Ports configures the ports probed by the ports check.
+    Ports PortsOptions
+    // AllSockets lists every socket on the host instead of those involving the target.
+    AllSockets bool
+    // Interface is sampled by the interface check; empty means the default-route interface.
+    Interface string
+    // InterfaceWindow is how long the interface counters are sampled.
+    InterfaceWindow time.Duration
+    // Thresholds rate the result; HTTP.ExpectStatus is used when they allow no status codes.
+    Thresholds Thresholds
+    // Raw keeps how each check ran and what its tools printed in the result.
+    Raw bool
+}
+
+func (u *NetworkDebugUsecase) NetworkDebug(ctx context.Context, domain string, opts DebugOptions) (*models.NetworkDebugResult, []error) {
+    result := &models.NetworkDebugResult{
+        Domain:    domain,
+        Timestamp: time.Now().UTC(),
+        Host:      hostInfo(u.ProcFS),
+        Skipped:   []string{},
+        Errors:    []models.ToolError{},
+    }
     var wg sync.WaitGroup
     var mu sync.Mutex
     var errorsList []error
 
-    // Define a helper function to execute a tool and handle results/errors
-    executeTool := func(toolName string, fn func() error) {
-        defer wg.Done()
-        if err := fn(); err != nil {
-            mu.Lock()
-            errorsList = append(errorsList, fmt.Errorf("%s error: %w", toolName, err))
-            mu.Unlock()
-        }
+    checks := opts.Checks
+    if len(checks) == 0 {
+        checks = Checks()
     }
 
-    wg.Add(7)
-
-    // Execute tools concurrently
-    go executeTool("dig", func() error {
-        dns, err := runDig(domain)
-        if err != nil {
-            return err
+    selected := make(map[string]bool)
+    for _, check := range checks {
+        selected[check.Name] = true
+    }
+    for _, check := range registry {
+        if !selected[check.Name] {
+            result.Skipped = append(result.Skipped, check.Name)
         }
-        mu.Lock()
-        result.DNSLookup = dns
-        mu.Unlock()
-        return nil
-    })
+    }
 
-    go executeTool("nslookup", func() error {
-        ns, err := runNSLookup(domain)
+    // Define a helper function to execute a check and handle results/errors
+    executeCheck := func(check Check) {
+        defer wg.Done()
+        checkCtx, capture := withCommandCapture(ctx)
+        start := time.Now()
+        apply, err := check.run(checkCtx, u, domain, opts)
+        run := models.CheckRun{Check: check.Name, Duration: time.Since(start), Commands: capture.list()}
         if err != nil {
-            return err
+            run.Error = err.Error()
         }
-        mu.Lock()
-        result.NSLookup = ns
-        mu.Unlock()
-        return nil
-    })
+        u.Logger.Debug("Check finished",
+            zap.String("check", run.Check),
+            zap.Duration("duration", run.Duration),
+            zap.Int("commands", len(run.Commands)),
+            zap.String("error", run.Error),
+        )
 
-    go executeTool("traceroute", func() error {
-        tr, err := runTraceroute(domain)
-        if err != nil {
-            return err
-        }
         mu.Lock()
-        result.Traceroute = tr
-        mu.Unlock()
-        return nil
-    })
-
-    go executeTool("curl", func() error {
-        curl, err := runCurl(domain)
-        if err != nil {
-            return err
+        defer mu.Unlock()
+        if opts.Raw {
+            result.Raw = append(result.Raw, run)
         }
-        mu.Lock()
-        result.HTTPRequest = curl
-        mu.Unlock()
-        return nil
-    })
-
-    go executeTool("ping", func() error {
-        ping, err := runPing(domain)
-        if err != nil {
-            return err
+        // A check may report what it measured along with an error, e.g. an
+        // HTTP response that failed its expectations.
+        if apply != nil {
+            apply(result)
         }
-        mu.Lock()
-        result.Ping = ping
-        mu.Unlock()
-        return nil
-    })
-
-    go executeTool("netstat", func() error {
-        netstat, err := runNetstat()
         if err != nil {
-            return err
-        }
-        mu.Lock()
-        result.Netstat = netstat
-        mu.Unlock()
-        return nil
-    })
-
-    go executeTool("iftop", func() error {
-        // TODO: Assuming eth0; consider making this configurable
-        iftop, err := runIftop("eth0")
-        if err != nil {
-            return err
-        }
-        mu.Lock()
-        result.Iftop = iftop
-        mu.Unlock()
-        return nil
-    })
-
-    wg.Wait()
-
-    return result, errorsList
-}
-
-// Helper functions to execute network tools
-
-func runDig(domain string) (models.DNSLookupResult, error) {
-    cmd := exec.Command("dig", "+noall", "+answer", domain)
-    output, err := cmd.Output()
-    if err != nil {
-        return models.DNSLookupResult{}, err
-    }
-
-    lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-    var records []models.DNSRecord
-    for _, line := range lines {
-        parts := strings.Fields(line)
-        if len(parts) < 5 {
-            continue
+            toolErr := models.ToolError{Tool: check.Name, Message: err.Error()}
+            result.Errors = append(result.Errors, toolErr)
+            errorsList = append(errorsList, toolErr)
         }
-        // parts[3] is the record type, parts[4] is the IP
-        recordType := parts[3]
-        ip := parts[4]
-        records = append(records, models.DNSRecord{
-            Type: recordType,
-            IP:   ip,
-        })
     }
 
-    return models.DNSLookupResult{
-        Records: records,
-    }, nil
-}
+    wg.Add(len(checks))
 
-func runNSLookup(domain string) (models.NSLookupResult, error) {
-    cmd := exec.Command("nslookup", domain)
-    output, err := cmd.Output()
-    if err != nil {
-        return models.NSLookupResult{}, err
-    }
-
-    lines := strings.Split(string(output), "\n")
-    var ip string
-    for _, line := range lines {
-        if strings.Contains(line, "Address:") && !strings.Contains(line, "#") {
-            parts := strings.Split(line, ":")
-            if len(parts) > 1 {
-                ip = strings.TrimSpace(parts[1])
-                break
-            }
-        }
+    // Execute checks concurrently
+    for _, check := range checks {
+        go executeCheck(check)
     }
 
-    if ip == "" {
-        return models.NSLookupResult{}, fmt.Errorf("no IP address found")
-    }
-
-    return models.NSLookupResult{
-        IP: ip,
-    }, nil
-}
+    wg.Wait()
 
-func runTraceroute(domain string) (models.TracerouteResult, error) {
-    cmd := exec.Command("traceroute", "-m", "5", domain)
-    output, err := cmd.Output()
-    if err != nil {
-        return models.TracerouteResult{}, err
+    // Checks finish in any order; keep the raw output in registry order.
+    order := make(map[string]int)
+    for i, name := range CheckNames() {
+        order[name] = i
     }
+    sort.Slice(result.Raw, func(i, j int) bool {
+        return order[result.Raw[i].Check] < order[result.Raw[j].Check]
+    })
 
-    lines := strings.Split(string(output), "\n")
-    var hops []models.TracerouteHop
-    for _, line := range lines[1:] { // Skip the first line
-        if line == "" {
-            continue
-        }
-        parts := strings.Fields(line)
-        if len(parts) < 3 {
-            continue
-        }
-        hopNumber, err := strconv.Atoi(parts[0])
-        if err != nil {
-            continue
-        }
-        address := parts[1]
-        responseTime := parts[len(parts)-2] // Assuming the time is before the last "ms"
-
-        hops = append(hops, models.TracerouteHop{
-            HopNumber:    hopNumber,
-            Address:      address,
-            ResponseTime: responseTime + " ms",
-        })
+    thresholds := opts.Thresholds
+    if len(thresholds.AllowedStatus) == 0 {
+        thresholds.AllowedStatus = opts.HTTP.ExpectStatus
     }
+    result.Verdict = Evaluate(result, thresholds)
 
-    return models.TracerouteResult{
-        Hops: hops,
-    }, nil
+    return result, errorsList
 }
 
-func runCurl(domain string) (models.HTTPRequestResult, error) {
-    start := time.Now()
-    cmd := exec.Command("curl", "-s", "-o", "/dev/null", "-w", "%{http_code} %{time_total} %{content_type}", domain)
-    output, err := cmd.Output()
-    if err != nil {
-        return models.HTTPRequestResult{}, err
-    }
-
-    parts := strings.Fields(string(output))
-    if len(parts) < 3 {
-        return models.HTTPRequestResult{}, fmt.Errorf("unexpected curl output: %s", string(output))
-    }
-
-    status := parts[0]
-    responseTime := fmt.Sprintf("%.0f ms", time.Since(start).Seconds()*1000)
-    contentType := parts[2]
+// Helper functions to execute network checks. Each check lives in its own file;
+// the few that still shell out, like the traceroute fallback, run their tool
+// through u.Runner and hand the captured output to a parser that can be fed fixtures.
 
-    return models.HTTPRequestResult{
-        Status:       fmt.Sprintf("HTTP %s", status),
-        ResponseTime: responseTime,
-        ContentType:  contentType,
-    }, nil
+func (u *NetworkDebugUsecase) runDNS(ctx context.Context, domain, server string) (models.DNSLookupResult, error) {
+    client := &DNSClient{Server: server}
+    return client.Lookup(ctx, domain)
 }
 
-func runPing(domain string) (models.PingResult, error) {
-    cmd := exec.Command("ping", "-c", "4", domain)
-    output, err := cmd.Output()
+// runNSLookup asks the system resolver, so /etc/hosts and nsswitch are honoured
+// the same way applications on this host see them.
+func (u *NetworkDebugUsecase) runNSLookup(ctx context.Context, domain string) (models.NSLookupResult, error) {
+    addrs, err := u.Resolver.LookupIPAddr(ctx, targetHost(domain))
     if err != nil {
-        return models.PingResult{}, err
-    }
-
-    var sent, received int
-    var lossPercent float64
-    var avgLatency int
-
-    lines := strings.Split(string(output), "\n")
-    for _, line := range lines {
-        if strings.Contains(line, "packets transmitted") {
-            // Example: 4 packets transmitted, 4 received, 0% packet loss, time 3005ms
-            parts := strings.Split(line, ",")
-            if len(parts) >= 3 {
-                fmt.Sscanf(parts[0], "%d packets transmitted", &sent)
-                fmt.Sscanf(parts[1], " %d received", &received)
-                fmt.Sscanf(parts[2], " %f%% packet loss", &lossPercent)
-            }
-        }
-        if strings.Contains(line, "rtt min/avg/max/mdev") {
-            // Example: rtt min/avg/max/mdev = 10.123/15.456/20.789/2.345 ms
-            parts := strings.Split(line, "=")
-            if len(parts) == 2 {
-                stats := strings.Split(strings.TrimSpace(parts[1]), "/")
-                if len(stats) >= 2 {
-                    avg, err := strconv.ParseFloat(stats[1], 64)
-                    if err == nil {
-                        avgLatency = int(avg)
-                    }
-                }
-            }
-        }
+        return models.NSLookupResult{}, err
     }
 
-    return models.PingResult{
-        Sent:         sent,
-        Received:     received,
-        Lost:         sent - received,
-        LossPercent:  lossPercent,
-        AvgLatency:   avgLatency,
-    }, nil
-}
-
-func runNetstat() (models.NetstatResult, error) {
-    cmd := exec.Command("netstat", "-tunapl")
-    output, err := cmd.Output()
-    if err != nil {
-        return models.NetstatResult{}, err
+    if len(addrs) == 0 {
+        return models.NSLookupResult{}, fmt.Errorf("no IP address found")
     }
 
-    lines := strings.Split(string(output), "\n")
-    var connections []models.NetstatConnection
-    for _, line := range lines {
-        if strings.HasPrefix(line, "tcp") || strings.HasPrefix(line, "udp") {
-            parts := strings.Fields(line)
-            if len(parts) >= 6 {
-                connections = append(connections, models.NetstatConnection{
-                    Protocol:      parts[0],
-                    LocalAddress:  parts[3],
-                    RemoteAddress: parts[4],
-                    Status:        parts[5],
-                })
-            }
-        }
+    result := models.NSLookupResult{IP: addrs[0].IP.String()}
+    for _, addr := range addrs {
+        result.Addresses = append(result.Addresses, addr.String())
     }
 
-    return models.NetstatResult{
-        Connections: connections,
-    }, nil
+    return result, nil
 }
 
-func runIftop(interfaceName string) (models.IftopResult, error) {
-    // Note: iftop typically requires root privileges. Ensure the CLI has necessary permissions.
-    // We'll run iftop in text mode for 5 seconds and capture the output.
-    cmd := exec.Command("sudo", "iftop", "-t", "-s", "5", "-i", interfaceName)
-    var out bytes.Buffer
-    cmd.Stdout = &out
-    err := cmd.Run()
-    if err != nil {
-        return models.IftopResult{}, err
+// targetHost strips the port from a host:port domain.
+func targetHost(domain string) string {
+    if host, _, err := net.SplitHostPort(domain); err == nil {
+        return host
     }
-
-    lines := strings.Split(string(out.String()), "\n")
-    var sending, receiving string
-    var topConns []models.IftopConnection
-
-    for _, line := range lines {
-        if strings.Contains(line, "=>") || strings.Contains(line, "<=") {
-            parts := strings.Fields(line)
-            if len(parts) >= 6 {
-                topConns = append(topConns, models.IftopConnection{
-                    Source:        parts[0],
-                    Destination:   parts[2],
-                    SentKBps:      parts[4],
-                    ReceivedKBps: parts[5],
-                })
-            }
-        }
-        if strings.Contains(line, "Total send rate") {
-            // Example: Total send rate: 120.00 KB/s
-            parts := strings.Split(line, ":")
-            if len(parts) == 2 {
-                sending = strings.TrimSpace(strings.TrimSuffix(parts[1], " KB/s"))
-            }
-        }
-        if strings.Contains(line, "Total receive rate") {
-            // Example: Total receive rate: 250.00 KB/s
-            parts := strings.Split(line, ":")
-            if len(parts) == 2 {
-                receiving = strings.TrimSpace(strings.TrimSuffix(parts[1], " KB/s"))
-            }
-        }
-    }
-
-    // Select Top 3 connections
-    limit := 3
-    if len(topConns) < 3 {
-        limit = len(topConns)
-    }
-    topConns = topConns[:limit]
-
-    return models.IftopResult{
-        SendingKBps:    sending + " KB/s",
-        ReceivingKBps:  receiving + " KB/s",
-        TopConnections: topConns,
-    }, nil
+    return domain
 }
@@ -0,0 +1,89 @@
+package network
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// commandChecks stand in for dns and ping: each runs its tools through the
+// usecase's runner, and dns finishes last although it comes first.
+func commandChecks() []Check {
+	return []Check{
+		{
+			Name: "ping",
+			run: func(ctx context.Context, u *NetworkDebugUsecase, domain string, opts DebugOptions) (func(*models.NetworkDebugResult), error) {
+				_, err := u.runCommand(ctx, "ping", "-c", "1", domain)
+				return nil, err
+			},
+		},
+		{
+			Name: "dns",
+			run: func(ctx context.Context, u *NetworkDebugUsecase, domain string, opts DebugOptions) (func(*models.NetworkDebugResult), error) {
+				time.Sleep(30 * time.Millisecond)
+				for _, query := range []string{"+short", "+trace"} {
+					if _, err := u.runCommand(ctx, "dig", query, domain); err != nil {
+						return nil, err
+					}
+				}
+				return nil, nil
+			},
+		},
+	}
+}
+
+func TestNetworkDebugRawCapturesCommandsPerCheck(t *testing.T) {
+	runner := NewFixtureRunner()
+	runner.Add("dig", &CommandResult{Stdout: []byte("192.0.2.1\n")})
+	runner.Add("ping", &CommandResult{Stderr: []byte("100% packet loss\n"), ExitCode: 1})
+	u := NewNetworkDebugUsecase(zap.NewNop(), runner)
+
+	result, _ := u.NetworkDebug(context.Background(), "example.com", DebugOptions{Checks: commandChecks(), Raw: true})
+
+	var checks []string
+	for _, run := range result.Raw {
+		checks = append(checks, run.Check)
+		if run.Duration <= 0 {
+			t.Errorf("%s has no duration", run.Check)
+		}
+	}
+	if want := []string{"dns", "ping"}; !reflect.DeepEqual(checks, want) {
+		t.Fatalf("raw checks = %q, want %q in registry order", checks, want)
+	}
+
+	dns, ping := result.Raw[0], result.Raw[1]
+	if dns.Error != "" || len(dns.Commands) != 2 ||
+		dns.Commands[0].Command != "dig +short example.com" || dns.Commands[1].Command != "dig +trace example.com" ||
+		dns.Commands[0].Stdout != "192.0.2.1\n" {
+		t.Errorf("dns run = %+v, want both dig commands and their output", dns)
+	}
+	wantErr := "ping -c 1 example.com: exit status 1: 100% packet loss"
+	if ping.Error != wantErr || len(ping.Commands) != 1 ||
+		ping.Commands[0].Command != "ping -c 1 example.com" || ping.Commands[0].ExitCode != 1 || ping.Commands[0].Stderr != "100% packet loss\n" {
+		t.Errorf("ping run = %+v, want only the failed ping", ping)
+	}
+	if len(result.Errors) != 1 || result.Errors[0] != (models.ToolError{Tool: "ping", Message: wantErr}) {
+		t.Errorf("errors = %+v", result.Errors)
+	}
+}
+
+func TestNetworkDebugDropsCommandsWithoutRaw(t *testing.T) {
+	runner := NewFixtureRunner()
+	runner.Add("dig", &CommandResult{Stdout: []byte("192.0.2.1\n")})
+	runner.Add("ping", &CommandResult{ExitCode: 1})
+	u := NewNetworkDebugUsecase(zap.NewNop(), runner)
+
+	result, _ := u.NetworkDebug(context.Background(), "example.com", DebugOptions{Checks: commandChecks()})
+
+	if result.Raw != nil {
+		t.Errorf("raw = %+v, want nothing without Raw", result.Raw)
+	}
+	if calls := runner.Calls(); len(calls) != 3 {
+		t.Errorf("calls = %q, want the three commands to run anyway", calls)
+	}
+}
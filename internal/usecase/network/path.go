@@ -0,0 +1,239 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// DefaultPathInterval is how often WatchPath probes every hop.
+const DefaultPathInterval = time.Second
+
+// PathWatchOptions configures WatchPath.
+type PathWatchOptions struct {
+	Trace TracerouteOptions
+	// Interval between rounds of probes.
+	Interval time.Duration
+	// Rounds stops after this many rounds; 0 runs until ctx is cancelled.
+	Rounds int
+}
+
+// hopStats accumulates the probes of one TTL using Welford's algorithm, so
+// the standard deviation is available after every round without keeping
+// every sample.
+type hopStats struct {
+	sent, received    int
+	last, best, worst time.Duration
+	mean, m2          float64
+	responders        []string
+}
+
+func (s *hopStats) add(reply traceReply) {
+	s.sent++
+	if reply.Address == nil {
+		return
+	}
+	s.received++
+
+	rtt := reply.RTT
+	s.last = rtt
+	if s.received == 1 || rtt < s.best {
+		s.best = rtt
+	}
+	if rtt > s.worst {
+		s.worst = rtt
+	}
+	delta := float64(rtt) - s.mean
+	s.mean += delta / float64(s.received)
+	s.m2 += delta * (float64(rtt) - s.mean)
+
+	address := reply.Address.String()
+	for _, known := range s.responders {
+		if known == address {
+			return
+		}
+	}
+	s.responders = append(s.responders, address)
+}
+
+// WatchPath probes every hop to the domain once per interval, like mtr, and
+// calls update with the statistics after each round. It returns the final
+// report once ctx is cancelled or the requested rounds are done; being
+// interrupted is the normal way to stop watching and is not an error.
+func (u *NetworkDebugUsecase) WatchPath(ctx context.Context, domain string, opts PathWatchOptions, update func(*models.PathReport)) (*models.PathReport, error) {
+	trace := opts.Trace
+	if err := trace.setDefaults(); err != nil {
+		return nil, err
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultPathInterval
+	}
+
+	target, err := u.resolveTarget(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := newTracer(target, trace.Protocol, trace.Port, trace.Timeout)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return nil, fmt.Errorf("path monitoring needs raw sockets, run it as root or grant CAP_NET_RAW: %w", err)
+		}
+		return nil, err
+	}
+	defer t.Close()
+
+	report := &models.PathReport{
+		Domain:    domain,
+		Target:    target.String(),
+		Protocol:  trace.Protocol,
+		StartedAt: time.Now().UTC(),
+		Hops:      []models.PathHop{},
+	}
+	if trace.Protocol != TraceProtocolICMP {
+		report.Port = trace.Port
+	}
+
+	stats := make([]*hopStats, trace.MaxHops-trace.FirstTTL+1)
+	for i := range stats {
+		stats[i] = &hopStats{}
+	}
+	// reachedAt is the index of the lowest TTL the target answered at, probes
+	// beyond it only measure the target again.
+	reachedAt := len(stats) - 1
+
+	names := newHostnameCache(u, trace.Numeric)
+
+	for round := 1; opts.Rounds == 0 || round <= opts.Rounds; round++ {
+		start := time.Now()
+
+		replies := make([]traceReply, reachedAt+1)
+		var wg sync.WaitGroup
+		for i := range replies {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Duration(i) * traceStagger):
+				}
+				replies[i] = t.Probe(ctx, trace.FirstTTL+i)
+			}(i)
+		}
+		wg.Wait()
+		if ctx.Err() != nil {
+			break
+		}
+
+		for i, reply := range replies {
+			stats[i].add(reply)
+			if reply.Address != nil {
+				names.lookup(reply.Address.String())
+			}
+			if reply.Reached && i < reachedAt {
+				reachedAt = i
+				report.Reached = true
+			}
+		}
+		report.Rounds = round
+		report.Hops = pathHops(stats[:reachedAt+1], trace.FirstTTL, names)
+		report.EndedAt = time.Now().UTC()
+		if update != nil {
+			update(report)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Until(start.Add(opts.Interval))):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	// Names resolved since the last round are picked up here.
+	if report.Rounds > 0 {
+		report.Hops = pathHops(stats[:reachedAt+1], trace.FirstTTL, names)
+	}
+	report.EndedAt = time.Now().UTC()
+	return report, nil
+}
+
+func pathHops(stats []*hopStats, firstTTL int, names *hostnameCache) []models.PathHop {
+	hops := make([]models.PathHop, len(stats))
+	for i, s := range stats {
+		hop := models.PathHop{
+			HopNumber:  firstTTL + i,
+			Responders: append([]string{}, s.responders...),
+			Sent:       s.sent,
+			Received:   s.received,
+			Last:       s.last,
+			Best:       s.best,
+			Worst:      s.worst,
+			Avg:        time.Duration(s.mean),
+		}
+		if s.sent > 0 {
+			hop.LossPercent = float64(s.sent-s.received) * 100 / float64(s.sent)
+		}
+		if s.received > 1 {
+			hop.StdDev = time.Duration(math.Sqrt(s.m2 / float64(s.received)))
+		}
+		if len(s.responders) > 0 {
+			hop.Address = s.responders[0]
+			hop.Hostname = names.get(hop.Address)
+		}
+		hops[i] = hop
+	}
+	return hops
+}
+
+// hostnameCache resolves responder names in the background so a slow reverse
+// lookup never delays a round.
+type hostnameCache struct {
+	u       *NetworkDebugUsecase
+	numeric bool
+	mu      sync.Mutex
+	names   map[string]string
+}
+
+func newHostnameCache(u *NetworkDebugUsecase, numeric bool) *hostnameCache {
+	return &hostnameCache{u: u, numeric: numeric, names: make(map[string]string)}
+}
+
+func (c *hostnameCache) lookup(address string) {
+	if c.numeric {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, seen := c.names[address]; seen {
+		return
+	}
+	c.names[address] = ""
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+		defer cancel()
+		names, err := c.u.Resolver.LookupAddr(ctx, address)
+		if err != nil || len(names) == 0 {
+			return
+		}
+		c.mu.Lock()
+		c.names[address] = strings.TrimSuffix(names[0], ".")
+		c.mu.Unlock()
+	}()
+}
+
+func (c *hostnameCache) get(address string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.names[address]
+}
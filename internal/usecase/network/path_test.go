@@ -0,0 +1,104 @@
+package network
+
+import (
+	"math"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+func TestPathHopStatistics(t *testing.T) {
+	ms := time.Millisecond
+	a, b := net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")
+	names := &hostnameCache{names: map[string]string{"10.0.0.1": "router.lan"}}
+
+	tests := []struct {
+		name    string
+		replies []traceReply
+		want    models.PathHop
+	}{
+		{
+			name:    "steady",
+			replies: []traceReply{{Address: a, RTT: 10 * ms}, {Address: a, RTT: 20 * ms}, {Address: a, RTT: 30 * ms}, {Address: a, RTT: 40 * ms}},
+			want: models.PathHop{
+				Address: "10.0.0.1", Hostname: "router.lan", Responders: []string{"10.0.0.1"},
+				Sent: 4, Received: 4,
+				Last: 40 * ms, Avg: 25 * ms, Best: 10 * ms, Worst: 40 * ms,
+				// sqrt((15² + 5² + 5² + 15²) / 4) ms
+				StdDev: time.Duration(math.Sqrt(125) * float64(ms)),
+			},
+		},
+		{
+			name:    "unordered round trips",
+			replies: []traceReply{{Address: a, RTT: 30 * ms}, {Address: a, RTT: 10 * ms}, {Address: a, RTT: 20 * ms}},
+			want: models.PathHop{
+				Address: "10.0.0.1", Hostname: "router.lan", Responders: []string{"10.0.0.1"},
+				Sent: 3, Received: 3,
+				Last: 20 * ms, Avg: 20 * ms, Best: 10 * ms, Worst: 30 * ms,
+				StdDev: time.Duration(math.Sqrt(200.0/3) * float64(ms)),
+			},
+		},
+		{
+			name: "losses and a second responder",
+			replies: []traceReply{
+				{Address: b, RTT: 10 * ms},
+				{},
+				{Address: a, RTT: 30 * ms},
+				{Address: b, RTT: 20 * ms},
+				{},
+			},
+			want: models.PathHop{
+				Address: "10.0.0.2", Responders: []string{"10.0.0.2", "10.0.0.1"},
+				Sent: 5, Received: 3, LossPercent: 40,
+				Last: 20 * ms, Avg: 20 * ms, Best: 10 * ms, Worst: 30 * ms,
+				StdDev: time.Duration(math.Sqrt(200.0/3) * float64(ms)),
+			},
+		},
+		{
+			name:    "one reply has no deviation",
+			replies: []traceReply{{}, {Address: a, RTT: 15 * ms}},
+			want: models.PathHop{
+				Address: "10.0.0.1", Hostname: "router.lan", Responders: []string{"10.0.0.1"},
+				Sent: 2, Received: 1, LossPercent: 50,
+				Last: 15 * ms, Avg: 15 * ms, Best: 15 * ms, Worst: 15 * ms,
+			},
+		},
+		{
+			name:    "every probe lost",
+			replies: []traceReply{{}, {}, {}},
+			want:    models.PathHop{Responders: []string{}, Sent: 3, LossPercent: 100},
+		},
+		{
+			name: "not probed yet",
+			want: models.PathHop{Responders: []string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := &hopStats{}
+			for _, reply := range tt.replies {
+				stats.add(reply)
+			}
+			hops := pathHops([]*hopStats{{}, stats}, 4, names)
+			if len(hops) != 2 || hops[0].HopNumber != 4 {
+				t.Fatalf("hops = %+v, want TTL 4 and 5", hops)
+			}
+			got := hops[1]
+
+			want := tt.want
+			want.HopNumber = 5
+			// Float rounding may shift the standard deviation by a nanosecond.
+			if d := got.StdDev - want.StdDev; d > 1 || d < -1 {
+				t.Errorf("StdDev = %s, want %s", got.StdDev, want.StdDev)
+			}
+			got.StdDev = want.StdDev
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("hop = %+v\nwant  %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	PingProtocolAuto = "auto"
+	PingProtocolICMP = "icmp"
+	PingProtocolTCP  = "tcp"
+	PingProtocolUDP  = "udp"
+
+	DefaultPingCount    = 4
+	DefaultPingInterval = time.Second
+	DefaultPingSize     = 56
+
+	defaultPingTimeout = 2 * time.Second
+	defaultTCPPingPort = 443
+	// defaultUDPPingPort is the first traceroute port, unlikely to have a listener.
+	defaultUDPPingPort = 33434
+)
+
+// PingOptions configures the ping check.
+type PingOptions struct {
+	Count    int
+	Interval time.Duration
+	// Size is the ICMP/UDP payload size in bytes.
+	Size int
+	// Timeout bounds how long each probe waits for its reply.
+	Timeout time.Duration
+	// Protocol is one of auto, icmp, tcp or udp. auto tries ICMP and falls
+	// back to TCP when this host does not allow ICMP sockets.
+	Protocol string
+	// Port is used by tcp and udp probes; 0 picks 443 for tcp and 33434 for udp.
+	Port int
+}
+
+// ParsePingProtocol validates a --ping-protocol value.
+func ParsePingProtocol(value string) (string, error) {
+	switch value {
+	case "", PingProtocolAuto:
+		return PingProtocolAuto, nil
+	case PingProtocolICMP, PingProtocolTCP, PingProtocolUDP:
+		return value, nil
+	}
+	return "", fmt.Errorf("unknown ping protocol '%s' (available: auto, icmp, tcp, udp)", value)
+}
+
+// runPing probes the first address of the domain and summarises the replies.
+// Lost probes are part of the result, only a run without a single reply is
+// reported as an error.
+func (u *NetworkDebugUsecase) runPing(ctx context.Context, domain string, opts PingOptions) (models.PingResult, error) {
+	if opts.Count <= 0 {
+		opts.Count = DefaultPingCount
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultPingInterval
+	}
+	if opts.Size <= 0 {
+		opts.Size = DefaultPingSize
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultPingTimeout
+	}
+	protocol, err := ParsePingProtocol(opts.Protocol)
+	if err != nil {
+		return models.PingResult{}, err
+	}
+
+	ip, err := u.resolveTarget(ctx, domain)
+	if err != nil {
+		return models.PingResult{}, err
+	}
+
+	result := models.PingResult{Target: ip.String()}
+
+	var prober pinger
+	if protocol == PingProtocolAuto || protocol == PingProtocolICMP {
+		prober, err = newICMPPinger(ip, opts.Size)
+		if err != nil {
+			if protocol == PingProtocolICMP {
+				return result, fmt.Errorf("ICMP sockets are not available: %w", err)
+			}
+			result.Note = fmt.Sprintf("ICMP sockets are not available (%v), fell back to TCP", err)
+			protocol = PingProtocolTCP
+		} else {
+			protocol = PingProtocolICMP
+		}
+	}
+	switch protocol {
+	case PingProtocolTCP:
+		port := opts.Port
+		if port == 0 {
+			port = defaultTCPPingPort
+		}
+		prober = &tcpPinger{address: net.JoinHostPort(ip.String(), strconv.Itoa(port))}
+		result.Port = port
+	case PingProtocolUDP:
+		port := opts.Port
+		if port == 0 {
+			port = defaultUDPPingPort
+		}
+		prober = &udpPinger{address: net.JoinHostPort(ip.String(), strconv.Itoa(port)), size: opts.Size}
+		result.Port = port
+	}
+	defer prober.Close()
+	result.Protocol = protocol
+
+	for seq := 1; seq <= opts.Count; seq++ {
+		if seq > 1 {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(opts.Interval):
+			}
+		}
+
+		probe := models.PingProbe{Seq: seq}
+		rtt, err := prober.Probe(ctx, seq, opts.Timeout)
+		if err != nil {
+			probe.Error = err.Error()
+		} else {
+			probe.Success = true
+			probe.RTT = rtt
+		}
+		result.Probes = append(result.Probes, probe)
+	}
+
+	summarizePing(&result)
+
+	if result.Received == 0 {
+		return result, fmt.Errorf("no replies from %s (%d/%d probes lost)", result.Target, result.Lost, result.Sent)
+	}
+	return result, nil
+}
+
+// summarizePing fills the counters and latency statistics from the probes.
+// Jitter is the mean difference between consecutive replies' round trips.
+func summarizePing(result *models.PingResult) {
+	var rtts []time.Duration
+	for _, probe := range result.Probes {
+		if probe.Success {
+			rtts = append(rtts, probe.RTT)
+		}
+	}
+
+	result.Sent = len(result.Probes)
+	result.Received = len(rtts)
+	result.Lost = result.Sent - result.Received
+	if result.Sent > 0 {
+		result.LossPercent = float64(result.Lost) * 100 / float64(result.Sent)
+	}
+	if len(rtts) == 0 {
+		return
+	}
+
+	var sum time.Duration
+	result.MinLatency, result.MaxLatency = rtts[0], rtts[0]
+	for _, rtt := range rtts {
+		sum += rtt
+		result.MinLatency = min(result.MinLatency, rtt)
+		result.MaxLatency = max(result.MaxLatency, rtt)
+	}
+	avg := sum / time.Duration(len(rtts))
+	result.AvgLatency = avg
+
+	var variance, jitter float64
+	for i, rtt := range rtts {
+		d := float64(rtt - avg)
+		variance += d * d
+		if i > 0 {
+			jitter += math.Abs(float64(rtt - rtts[i-1]))
+		}
+	}
+	result.StdDev = time.Duration(math.Sqrt(variance / float64(len(rtts))))
+	if len(rtts) > 1 {
+		result.Jitter = time.Duration(jitter / float64(len(rtts)-1))
+	}
+}
+
+type pinger interface {
+	Probe(ctx context.Context, seq int, timeout time.Duration) (time.Duration, error)
+	Close() error
+}
+
+// icmpPinger sends echo requests over an unprivileged datagram socket, or a
+// raw socket when running as root on a host that disables the former.
+type icmpPinger struct {
+	conn      *icmp.PacketConn
+	dst       net.Addr
+	proto     int
+	echoType  icmp.Type
+	replyType icmp.Type
+	id        int
+	payload   []byte
+	// privileged sockets see every echo reply on the host, so the ID is checked too.
+	privileged bool
+}
+
+func newICMPPinger(ip net.IP, size int) (*icmpPinger, error) {
+	p := &icmpPinger{id: os.Getpid() & 0xffff}
+	if ip.To4() != nil {
+		p.proto, p.echoType, p.replyType = 1, ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	} else {
+		p.proto, p.echoType, p.replyType = 58, ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	datagram, raw := "udp4", "ip4:icmp"
+	if p.proto == 58 {
+		datagram, raw = "udp6", "ip6:ipv6-icmp"
+	}
+
+	conn, err := icmp.ListenPacket(datagram, "")
+	if err == nil {
+		p.dst = &net.UDPAddr{IP: ip}
+	} else {
+		var rawErr error
+		conn, rawErr = icmp.ListenPacket(raw, "")
+		if rawErr != nil {
+			return nil, err
+		}
+		p.dst = &net.IPAddr{IP: ip}
+		p.privileged = true
+	}
+	p.conn = conn
+
+	p.payload = make([]byte, size)
+	rand.Read(p.payload)
+
+	return p, nil
+}
+
+func (p *icmpPinger) Probe(ctx context.Context, seq int, timeout time.Duration) (time.Duration, error) {
+	msg := icmp.Message{
+		Type: p.echoType,
+		Body: &icmp.Echo{ID: p.id, Seq: seq, Data: p.payload},
+	}
+	packet, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	p.conn.SetReadDeadline(deadline)
+	// Unblock reads as soon as the caller gives up.
+	stop := context.AfterFunc(ctx, func() { p.conn.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	start := time.Now()
+	if _, err := p.conn.WriteTo(packet, p.dst); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1500+len(p.payload))
+	for {
+		n, _, err := p.conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			if isTimeout(err) {
+				return 0, errors.New("timeout")
+			}
+			return 0, err
+		}
+		rtt := time.Since(start)
+
+		reply, err := icmp.ParseMessage(p.proto, buf[:n])
+		if err != nil || reply.Type != p.replyType {
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		// The kernel rewrites the ID of datagram sockets and only delivers
+		// their own replies, so only the sequence and payload are compared.
+		if !ok || echo.Seq != seq || !bytes.Equal(echo.Data, p.payload) || (p.privileged && echo.ID != p.id) {
+			continue
+		}
+		return rtt, nil
+	}
+}
+
+func (p *icmpPinger) Close() error {
+	return p.conn.Close()
+}
+
+// tcpPinger times TCP handshakes. A refused connection still proves the host
+// answered, so it counts as a reply.
+type tcpPinger struct {
+	address string
+}
+
+func (p *tcpPinger) Probe(ctx context.Context, seq int, timeout time.Duration) (time.Duration, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	rtt := time.Since(start)
+	if err != nil {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return rtt, nil
+		}
+		if isTimeout(err) {
+			return 0, errors.New("timeout")
+		}
+		return 0, err
+	}
+	conn.Close()
+	return rtt, nil
+}
+
+func (p *tcpPinger) Close() error { return nil }
+
+// udpPinger sends a datagram to a (normally closed) port. Either a response
+// or the ICMP port unreachable it provokes, surfaced by the kernel as a
+// refused read on the connected socket, counts as a reply.
+type udpPinger struct {
+	address string
+	size    int
+}
+
+func (p *udpPinger) Probe(ctx context.Context, seq int, timeout time.Duration) (time.Duration, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", p.address)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	payload := make([]byte, p.size)
+	start := time.Now()
+	if _, err := conn.Write(payload); err != nil {
+		return 0, err
+	}
+	buf := make([]byte, 1500)
+	_, err = conn.Read(buf)
+	rtt := time.Since(start)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return 0, ctx.Err()
+	case err == nil, errors.Is(err, syscall.ECONNREFUSED):
+		return rtt, nil
+	case isTimeout(err):
+		return 0, errors.New("timeout")
+	default:
+		return 0, err
+	}
+}
+
+func (p *udpPinger) Close() error { return nil }
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
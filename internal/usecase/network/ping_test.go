@@ -0,0 +1,144 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// probes turns round trips into ping probes; 0 is a lost probe.
+func probes(rtts ...time.Duration) []models.PingProbe {
+	var list []models.PingProbe
+	for i, rtt := range rtts {
+		probe := models.PingProbe{Seq: i + 1, Success: rtt > 0, RTT: rtt}
+		if rtt == 0 {
+			probe.Error = "timeout"
+		}
+		list = append(list, probe)
+	}
+	return list
+}
+
+func TestSummarizePing(t *testing.T) {
+	ms := time.Millisecond
+
+	tests := []struct {
+		name   string
+		probes []models.PingProbe
+		want   models.PingResult
+	}{
+		{name: "no probes", want: models.PingResult{}},
+		{
+			name:   "0 received",
+			probes: probes(0, 0, 0),
+			want:   models.PingResult{Sent: 3, Lost: 3, LossPercent: 100},
+		},
+		{
+			name:   "1 received",
+			probes: probes(0, 10*ms, 0, 0),
+			want: models.PingResult{
+				Sent: 4, Received: 1, Lost: 3, LossPercent: 75,
+				MinLatency: 10 * ms, AvgLatency: 10 * ms, MaxLatency: 10 * ms,
+			},
+		},
+		{
+			name:   "all received",
+			probes: probes(10*ms, 20*ms, 30*ms, 40*ms),
+			want: models.PingResult{
+				Sent: 4, Received: 4,
+				MinLatency: 10 * ms, AvgLatency: 25 * ms, MaxLatency: 40 * ms,
+				// sqrt((15² + 5² + 5² + 15²) / 4) ms
+				StdDev: time.Duration(math.Sqrt(125) * float64(ms)),
+				Jitter: 10 * ms,
+			},
+		},
+		{
+			name:   "jitter skips lost probes",
+			probes: probes(10*ms, 0, 30*ms),
+			want: models.PingResult{
+				Sent: 3, Received: 2, Lost: 1, LossPercent: 100.0 / 3,
+				MinLatency: 10 * ms, AvgLatency: 20 * ms, MaxLatency: 30 * ms,
+				StdDev: 10 * ms,
+				Jitter: 20 * ms,
+			},
+		},
+		{
+			name:   "unordered round trips",
+			probes: probes(30*ms, 10*ms, 20*ms),
+			want: models.PingResult{
+				Sent: 3, Received: 3,
+				MinLatency: 10 * ms, AvgLatency: 20 * ms, MaxLatency: 30 * ms,
+				StdDev: time.Duration(math.Sqrt(200.0/3) * float64(ms)),
+				Jitter: 15 * ms,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := models.PingResult{Probes: tt.probes}
+			summarizePing(&result)
+			result.Probes = nil
+
+			// Float rounding may shift the standard deviation by a nanosecond.
+			if d := result.StdDev - tt.want.StdDev; d > 1 || d < -1 {
+				t.Errorf("StdDev = %s, want %s", result.StdDev, tt.want.StdDev)
+			}
+			result.StdDev = tt.want.StdDev
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("summary = %+v\nwant      %+v", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestUDPPingerStopsWhenCancelled(t *testing.T) {
+	// A bound socket that never answers: no reply and no port unreachable.
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	p := &udpPinger{address: conn.LocalAddr().String(), size: 8}
+	if _, err := p.Probe(ctx, 1, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("probe returned after %s, want right after the cancellation", elapsed)
+	}
+}
+
+func TestICMPPingerStopsWhenCancelled(t *testing.T) {
+	// 192.0.2.1 is reserved for documentation, so nothing answers it.
+	p, err := newICMPPinger(net.ParseIP("192.0.2.1"), 8)
+	if err != nil {
+		t.Skipf("ICMP sockets are not available: %v", err)
+	}
+	defer p.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err = p.Probe(ctx, 1, time.Minute)
+	switch {
+	case err == nil:
+		t.Skip("something on this network answers 192.0.2.1")
+	case !errors.Is(err, context.Canceled):
+		t.Skipf("cannot send to 192.0.2.1 from here: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("probe returned after %s, want right after the cancellation", elapsed)
+	}
+}
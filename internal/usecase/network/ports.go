@@ -0,0 +1,349 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+const (
+	DefaultPortTimeout     = 2 * time.Second
+	DefaultPortConcurrency = 64
+	// maxPorts bounds how many ports a single run may probe.
+	maxPorts = 4096
+	// bannerWait is how long an open port is given to greet us.
+	bannerWait = time.Second
+	// maxBannerLength, in characters, keeps banners to a single readable line.
+	maxBannerLength = 120
+)
+
+// DefaultPorts are probed when no ports are given and the domain has none of
+// its own: the ones the http check uses.
+var DefaultPorts = []string{"80", "443"}
+
+// PortSpec is one port to probe.
+type PortSpec struct {
+	Port     int
+	Protocol string
+}
+
+func (p PortSpec) String() string {
+	return fmt.Sprintf("%d/%s", p.Port, p.Protocol)
+}
+
+// PortsOptions configures the ports check.
+type PortsOptions struct {
+	Ports []PortSpec
+	// Timeout bounds each connect; a TCP port that does not answer in time is filtered.
+	Timeout time.Duration
+	// Banner reads the first line an open TCP port sends, or answers an HTTP request with.
+	Banner bool
+	// Concurrency bounds how many ports are probed at once.
+	Concurrency int
+}
+
+// ParsePorts parses --ports values such as "22", "8000-8010", "53/udp" and
+// "8000-8010/tcp". Ports are TCP unless suffixed with /udp; duplicates are dropped.
+func ParsePorts(values []string) ([]PortSpec, error) {
+	var specs []PortSpec
+	seen := make(map[PortSpec]bool)
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+
+		protocol := "tcp"
+		if rest, proto, ok := strings.Cut(value, "/"); ok {
+			if proto != "tcp" && proto != "udp" {
+				return nil, fmt.Errorf("invalid port '%s': protocol must be tcp or udp", value)
+			}
+			value, protocol = rest, proto
+		}
+
+		first, last, isRange := strings.Cut(value, "-")
+		low, err := parsePort(first)
+		if err != nil {
+			return nil, err
+		}
+		high := low
+		if isRange {
+			if high, err = parsePort(last); err != nil {
+				return nil, err
+			}
+			if high < low {
+				return nil, fmt.Errorf("invalid port range '%s'", value)
+			}
+		}
+
+		for port := low; port <= high; port++ {
+			spec := PortSpec{Port: port, Protocol: protocol}
+			if seen[spec] {
+				continue
+			}
+			seen[spec] = true
+			specs = append(specs, spec)
+			if len(specs) > maxPorts {
+				return nil, fmt.Errorf("too many ports, at most %d can be probed at once", maxPorts)
+			}
+		}
+	}
+	return specs, nil
+}
+
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port '%s': must be between 1 and 65535", value)
+	}
+	return port, nil
+}
+
+// runPorts probes every port concurrently. Closed and filtered ports are part
+// of the result, only a run without a single open port is reported as an error.
+func (u *NetworkDebugUsecase) runPorts(ctx context.Context, domain string, opts PortsOptions) (models.PortsResult, error) {
+	if len(opts.Ports) == 0 {
+		defaults := DefaultPorts
+		if _, port, err := net.SplitHostPort(domain); err == nil {
+			defaults = []string{port}
+		}
+		ports, err := ParsePorts(defaults)
+		if err != nil {
+			return models.PortsResult{}, err
+		}
+		opts.Ports = ports
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultPortTimeout
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultPortConcurrency
+	}
+
+	target, err := u.resolveTarget(ctx, domain)
+	if err != nil {
+		return models.PortsResult{}, err
+	}
+
+	result := models.PortsResult{
+		Target: target.String(),
+		Probes: make([]models.PortProbe, len(opts.Ports)),
+	}
+
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i, spec := range opts.Ports {
+		wg.Add(1)
+		go func(i int, spec PortSpec) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if spec.Protocol == "udp" {
+				result.Probes[i] = probeUDPPort(ctx, target, spec.Port, opts.Timeout)
+			} else {
+				result.Probes[i] = probeTCPPort(ctx, target, spec.Port, opts.Timeout, opts.Banner)
+			}
+		}(i, spec)
+	}
+	wg.Wait()
+
+	for _, probe := range result.Probes {
+		switch probe.State {
+		case models.PortOpen:
+			result.Open++
+		case models.PortRefused:
+			result.Refused++
+		case models.PortFiltered, models.PortOpenFiltered:
+			result.Filtered++
+		}
+	}
+
+	if result.Open == 0 {
+		return result, fmt.Errorf("none of the %d probed ports is open on %s", len(result.Probes), target)
+	}
+	return result, nil
+}
+
+func probeTCPPort(ctx context.Context, target net.IP, port int, timeout time.Duration, banner bool) models.PortProbe {
+	probe := models.PortProbe{Port: port, Protocol: "tcp"}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(target.String(), strconv.Itoa(port)))
+	probe.Latency = time.Since(start)
+	if err != nil {
+		probe.State = classifyDialError(err)
+		if probe.State != models.PortRefused {
+			probe.Error = err.Error()
+		}
+		return probe
+	}
+	defer conn.Close()
+
+	probe.State = models.PortOpen
+	if banner {
+		probe.Banner = readBanner(conn)
+	}
+	return probe
+}
+
+// classifyDialError tells a port that actively refused the connection from one
+// whose SYN went unanswered or was rejected by a firewall along the way.
+func classifyDialError(err error) string {
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return models.PortRefused
+	case isTimeout(err), errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH),
+		errors.Is(err, syscall.EACCES), errors.Is(err, syscall.EPERM):
+		return models.PortFiltered
+	}
+	return models.PortError
+}
+
+// readBanner returns the first line the service sends. Services that wait for
+// the client, like most HTTP servers, are sent a HEAD request instead.
+func readBanner(conn net.Conn) string {
+	buf := make([]byte, 512)
+	conn.SetReadDeadline(time.Now().Add(bannerWait))
+	n, _ := conn.Read(buf)
+	if n == 0 {
+		conn.SetWriteDeadline(time.Now().Add(bannerWait))
+		if _, err := conn.Write([]byte("HEAD / HTTP/1.0\r\n\r\n")); err != nil {
+			return ""
+		}
+		conn.SetReadDeadline(time.Now().Add(bannerWait))
+		n, _ = conn.Read(buf)
+	}
+	return bannerLine(buf[:n])
+}
+
+// bannerLine keeps the first line of data with non-printable bytes dropped.
+func bannerLine(data []byte) string {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		data = data[:i]
+	}
+	line := strings.Map(func(r rune) rune {
+		if r == unicode.ReplacementChar || !unicode.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, string(data))
+	// Cut by characters, a byte offset could split a multi-byte rune.
+	if runes := []rune(line); len(runes) > maxBannerLength {
+		line = string(runes[:maxBannerLength]) + "..."
+	}
+	return strings.TrimSpace(line)
+}
+
+// probeUDPPort sends a request the service understands, a DNS query on 53 and
+// an NTP client packet on 123, and an empty datagram elsewhere. A reply means
+// open, an ICMP port unreachable means refused, silence is open|filtered.
+func probeUDPPort(ctx context.Context, target net.IP, port int, timeout time.Duration) models.PortProbe {
+	probe := models.PortProbe{Port: port, Protocol: "udp"}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", net.JoinHostPort(target.String(), strconv.Itoa(port)))
+	if err != nil {
+		probe.State = models.PortError
+		probe.Error = err.Error()
+		return probe
+	}
+	defer conn.Close()
+
+	payload, describe := udpPayload(port)
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	start := time.Now()
+	if _, err := conn.Write(payload); err != nil {
+		probe.State = classifyDialError(err)
+		probe.Error = err.Error()
+		return probe
+	}
+	buf := make([]byte, 1500)
+	n, err := conn.Read(buf)
+	probe.Latency = time.Since(start)
+	switch {
+	case err == nil:
+		probe.State = models.PortOpen
+		probe.Banner = describe(buf[:n])
+	case errors.Is(err, syscall.ECONNREFUSED):
+		probe.State = models.PortRefused
+	case isTimeout(err):
+		probe.State = models.PortOpenFiltered
+	default:
+		probe.State = models.PortError
+		probe.Error = err.Error()
+	}
+	return probe
+}
+
+// udpPayload returns the datagram sent to a UDP port and how to describe its reply.
+func udpPayload(port int) ([]byte, func([]byte) string) {
+	switch port {
+	case 53:
+		id, err := dnsQueryID()
+		if err != nil {
+			break
+		}
+		msg := dnsmessage.Message{
+			Header: dnsmessage.Header{ID: id, RecursionDesired: true},
+			Questions: []dnsmessage.Question{{
+				Name:  dnsmessage.MustNewName("."),
+				Type:  dnsmessage.TypeNS,
+				Class: dnsmessage.ClassINET,
+			}},
+		}
+		packet, err := msg.Pack()
+		if err != nil {
+			break
+		}
+		return packet, func(reply []byte) string {
+			var header dnsmessage.Parser
+			h, err := header.Start(reply)
+			if err != nil || h.ID != id {
+				return "unexpected reply to DNS query"
+			}
+			return "DNS " + rcodeName(h.RCode)
+		}
+	case 123:
+		// LI 0, version 4, mode 3 (client).
+		packet := make([]byte, 48)
+		packet[0] = 0x23
+		return packet, func(reply []byte) string {
+			if len(reply) < 48 {
+				return "unexpected reply to NTP request"
+			}
+			stratum := reply[1]
+			version := reply[0] >> 3 & 0x7
+			if stratum == 0 {
+				// Kiss-o'-death packets carry an ASCII code in the reference ID.
+				return fmt.Sprintf("NTPv%d kiss code %s", version, bannerLine(reply[12:16]))
+			}
+			return fmt.Sprintf("NTPv%d stratum %d, root delay %s", version, stratum, ntpShort(binary.BigEndian.Uint32(reply[4:8])))
+		}
+	}
+	return []byte{}, func(reply []byte) string { return bannerLine(reply) }
+}
+
+// ntpShort converts an NTP short format (16.16 fixed point seconds) value.
+func ntpShort(v uint32) time.Duration {
+	return time.Duration(float64(v) / (1 << 16) * float64(time.Second)).Round(time.Microsecond)
+}
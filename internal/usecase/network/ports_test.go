@@ -0,0 +1,146 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+func TestParsePorts(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{"single", []string{"22"}, []string{"22/tcp"}},
+		{"range", []string{"8000-8003"}, []string{"8000/tcp", "8001/tcp", "8002/tcp", "8003/tcp"}},
+		{"range of one", []string{"443-443"}, []string{"443/tcp"}},
+		{"udp suffix", []string{"53/udp", "123/UDP"}, []string{"53/udp", "123/udp"}},
+		{"tcp suffix", []string{"80/tcp"}, []string{"80/tcp"}},
+		{"udp range", []string{"5000-5002/udp"}, []string{"5000/udp", "5001/udp", "5002/udp"}},
+		{"same port on both protocols", []string{"53", "53/udp"}, []string{"53/tcp", "53/udp"}},
+		{"duplicates dropped", []string{"80", "79-81", "80/tcp", " 81 "}, []string{"80/tcp", "79/tcp", "81/tcp"}},
+		{"empty values skipped", []string{"", " ", "22"}, []string{"22/tcp"}},
+		{"bounds", []string{"1", "65535"}, []string{"1/tcp", "65535/tcp"}},
+		{"nothing", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specs, err := ParsePorts(tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, spec := range specs {
+				got = append(got, spec.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePorts(%q) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePortsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		err    string
+	}{
+		{"zero", []string{"0"}, "invalid port '0': must be between 1 and 65535"},
+		{"too high", []string{"65536"}, "invalid port '65536': must be between 1 and 65535"},
+		{"not a number", []string{"ssh"}, "invalid port 'ssh': must be between 1 and 65535"},
+		{"unknown protocol", []string{"80/sctp"}, "invalid port '80/sctp': protocol must be tcp or udp"},
+		{"reversed range", []string{"90-80"}, "invalid port range '90-80'"},
+		{"open range", []string{"80-"}, "invalid port '': must be between 1 and 65535"},
+		{"range end too high", []string{"65000-70000"}, "invalid port '70000': must be between 1 and 65535"},
+		{"too many ports", []string{"1-4097"}, "too many ports, at most 4096 can be probed at once"},
+		{"too many across values", []string{"1-4000", "5000-5096/udp"}, "too many ports, at most 4096 can be probed at once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specs, err := ParsePorts(tt.values)
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("ParsePorts(%q) = %v, %v; want error %q", tt.values, specs, err, tt.err)
+			}
+		})
+	}
+
+	// Duplicates do not count towards the limit.
+	specs, err := ParsePorts([]string{"1-4096", "1-4096", "4096"})
+	if err != nil || len(specs) != maxPorts {
+		t.Errorf("ParsePorts at the limit = %d ports, %v; want %d", len(specs), err, maxPorts)
+	}
+}
+
+func TestBannerLine(t *testing.T) {
+	long := strings.Repeat("é", maxBannerLength+10)
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"first line", "SSH-2.0-OpenSSH_9.6\r\nsecond line\r\n", "SSH-2.0-OpenSSH_9.6"},
+		{"control bytes dropped", "220 \x00\x07mail ready\x1b\n", "220 mail ready"},
+		{"invalid utf-8 dropped", "HTTP/1.0 200 \xff\xfeOK", "HTTP/1.0 200 OK"},
+		{"surrounding space", "  hello  \n", "hello"},
+		{"empty", "", ""},
+		{"at the limit", strings.Repeat("a", maxBannerLength), strings.Repeat("a", maxBannerLength)},
+		{"truncated by characters", long, strings.Repeat("é", maxBannerLength) + "..."},
+		{"multi-byte rune across the byte limit", strings.Repeat("a", maxBannerLength-1) + "日本語", strings.Repeat("a", maxBannerLength-1) + "日..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bannerLine([]byte(tt.data))
+			if got != tt.want {
+				t.Errorf("bannerLine(%q) = %q, want %q", tt.data, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("bannerLine(%q) = %q is not valid UTF-8", tt.data, got)
+			}
+		})
+	}
+}
+
+func TestProbeTCPPortBanner(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(conn, "SSH-2.0-%s\r\n", strings.Repeat("ü", 200))
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	probe := probeTCPPort(context.Background(), net.ParseIP("127.0.0.1"), port, DefaultPortTimeout, true)
+	if probe.State != models.PortOpen {
+		t.Fatalf("state = %s (%s), want open", probe.State, probe.Error)
+	}
+	if want := "SSH-2.0-" + strings.Repeat("ü", maxBannerLength-len("SSH-2.0-")) + "..."; probe.Banner != want {
+		t.Errorf("banner = %q, want %q", probe.Banner, want)
+	}
+
+	listener.Close()
+	probe = probeTCPPort(context.Background(), net.ParseIP("127.0.0.1"), port, DefaultPortTimeout, false)
+	if probe.State != models.PortRefused || probe.Error != "" {
+		t.Errorf("closed port = %s (%s), want refused", probe.State, probe.Error)
+	}
+}
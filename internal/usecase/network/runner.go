@@ -0,0 +1,262 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// CommandResult holds everything captured from a single tool execution.
+type CommandResult struct {
+	Command  string
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+	Duration time.Duration
+}
+
+// CommandRunner executes the external tools used by the network diagnostics.
+type CommandRunner interface {
+	Run(ctx context.Context, name string, args ...string) (*CommandResult, error)
+}
+
+// ExecRunner runs commands on the local host and kills them when ctx is cancelled.
+type ExecRunner struct{}
+
+func NewExecRunner() *ExecRunner {
+	return &ExecRunner{}
+}
+
+func (r *ExecRunner) Run(ctx context.Context, name string, args ...string) (*CommandResult, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+
+	result := &CommandResult{
+		Command:  commandLine(name, args),
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		Duration: time.Since(start),
+	}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return result, fmt.Errorf("%s: %w", result.Command, ctx.Err())
+		}
+		return result, commandError(result, err)
+	}
+
+	return result, nil
+}
+
+// FixtureRunner replays recorded tool output instead of executing anything,
+// so parsers can be exercised against golden outputs from real tools.
+type FixtureRunner struct {
+	mu       sync.Mutex
+	fixtures map[string]*CommandResult
+	calls    []string
+}
+
+func NewFixtureRunner() *FixtureRunner {
+	return &FixtureRunner{
+		fixtures: make(map[string]*CommandResult),
+	}
+}
+
+// LoadFixtureRunner builds a FixtureRunner from a directory of recorded outputs.
+// Each <tool>.stdout file is replayed for that tool, with optional <tool>.stderr
+// and <tool>.exitcode files next to it.
+func LoadFixtureRunner(dir string) (*FixtureRunner, error) {
+	runner := NewFixtureRunner()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.stdout"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		tool := strings.TrimSuffix(filepath.Base(path), ".stdout")
+
+		stdout, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading fixture %s: %w", path, err)
+		}
+
+		result := &CommandResult{Command: tool, Stdout: stdout}
+
+		if stderr, err := os.ReadFile(filepath.Join(dir, tool+".stderr")); err == nil {
+			result.Stderr = stderr
+		}
+		if code, err := os.ReadFile(filepath.Join(dir, tool+".exitcode")); err == nil {
+			if _, err := fmt.Sscanf(strings.TrimSpace(string(code)), "%d", &result.ExitCode); err != nil {
+				return nil, fmt.Errorf("invalid exit code in fixture for %s: %w", tool, err)
+			}
+		}
+
+		runner.Add(tool, result)
+	}
+
+	return runner, nil
+}
+
+// Add registers a recorded result. The key is either a tool name ("dig") or a
+// full command line ("dig +noall +answer example.com"); full command lines win.
+func (r *FixtureRunner) Add(key string, result *CommandResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fixtures[key] = result
+}
+
+// Calls returns the command lines that were requested, in order.
+func (r *FixtureRunner) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+func (r *FixtureRunner) Run(ctx context.Context, name string, args ...string) (*CommandResult, error) {
+	line := commandLine(name, args)
+
+	r.mu.Lock()
+	r.calls = append(r.calls, line)
+	fixture, ok := r.fixtures[line]
+	if !ok {
+		fixture, ok = r.fixtures[toolName(name, args)]
+	}
+	r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", line, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("no fixture recorded for %q", line)
+	}
+
+	result := *fixture
+	result.Command = line
+	if result.ExitCode != 0 {
+		return &result, commandError(&result, fmt.Errorf("exit status %d", result.ExitCode))
+	}
+
+	return &result, nil
+}
+
+// RecordingRunner wraps another runner and writes every result to Dir in the
+// layout LoadFixtureRunner expects, which is how golden outputs are captured.
+type RecordingRunner struct {
+	Runner CommandRunner
+	Dir    string
+}
+
+func (r *RecordingRunner) Run(ctx context.Context, name string, args ...string) (*CommandResult, error) {
+	result, err := r.Runner.Run(ctx, name, args...)
+	if result == nil {
+		return result, err
+	}
+
+	base := filepath.Join(r.Dir, toolName(name, args))
+	if werr := os.WriteFile(base+".stdout", result.Stdout, 0o644); werr != nil {
+		return result, errors.Join(err, werr)
+	}
+	if len(result.Stderr) > 0 {
+		if werr := os.WriteFile(base+".stderr", result.Stderr, 0o644); werr != nil {
+			return result, errors.Join(err, werr)
+		}
+	}
+	if result.ExitCode != 0 {
+		if werr := os.WriteFile(base+".exitcode", []byte(fmt.Sprintf("%d\n", result.ExitCode)), 0o644); werr != nil {
+			return result, errors.Join(err, werr)
+		}
+	}
+
+	return result, err
+}
+
+func commandLine(name string, args []string) string {
+	return strings.TrimSpace(name + " " + strings.Join(args, " "))
+}
+
+// toolName returns the tool a command line actually runs, looking through sudo.
+func toolName(name string, args []string) string {
+	if name == "sudo" && len(args) > 0 {
+		return args[0]
+	}
+	return name
+}
+
+func commandError(result *CommandResult, err error) error {
+	stderr := strings.TrimSpace(string(result.Stderr))
+	if stderr == "" {
+		return fmt.Errorf("%s: %w", result.Command, err)
+	}
+	return fmt.Errorf("%s: %w: %s", result.Command, err, stderr)
+}
+
+// commandCapture collects the commands run on behalf of one check.
+type commandCapture struct {
+	mu       sync.Mutex
+	commands []models.RawCommand
+}
+
+type commandCaptureKey struct{}
+
+func withCommandCapture(ctx context.Context) (context.Context, *commandCapture) {
+	capture := &commandCapture{}
+	return context.WithValue(ctx, commandCaptureKey{}, capture), capture
+}
+
+func (c *commandCapture) list() []models.RawCommand {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]models.RawCommand{}, c.commands...)
+}
+
+// runCommand runs a tool through u.Runner, logs the command line, exit code,
+// duration and output at debug level, and keeps them for the check's raw
+// output, since parsers skip whatever they do not recognise.
+func (u *NetworkDebugUsecase) runCommand(ctx context.Context, name string, args ...string) (*CommandResult, error) {
+	res, err := u.Runner.Run(ctx, name, args...)
+	if res == nil {
+		return res, err
+	}
+
+	raw := models.RawCommand{
+		Command:  res.Command,
+		Stdout:   string(res.Stdout),
+		Stderr:   string(res.Stderr),
+		ExitCode: res.ExitCode,
+		Duration: res.Duration,
+	}
+	u.Logger.Debug("Command finished",
+		zap.String("command", raw.Command),
+		zap.Int("exit_code", raw.ExitCode),
+		zap.Duration("duration", raw.Duration),
+		zap.String("stdout", raw.Stdout),
+		zap.String("stderr", raw.Stderr),
+	)
+	if capture, ok := ctx.Value(commandCaptureKey{}).(*commandCapture); ok {
+		capture.mu.Lock()
+		capture.commands = append(capture.commands, raw)
+		capture.mu.Unlock()
+	}
+
+	return res, err
+}
@@ -0,0 +1,240 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// socketTables are the /proc/net files read by the netstat check, named the
+// way netstat labels their protocols.
+var socketTables = []string{"tcp", "tcp6", "udp", "udp6"}
+
+// tcpStates maps the st column of /proc/net/tcp to the names netstat prints.
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+	"0C": "NEW_SYN_RECV",
+}
+
+// runSockets lists the sockets on this host that involve the target's
+// addresses, or every socket when all is set.
+func (u *NetworkDebugUsecase) runSockets(ctx context.Context, domain string, all bool) (models.NetstatResult, error) {
+	result := models.NetstatResult{
+		Filter:      []string{},
+		Connections: []models.NetstatConnection{},
+		// The states that point at connection trouble are always reported.
+		States: map[string]int{"ESTABLISHED": 0, "TIME_WAIT": 0, "CLOSE_WAIT": 0},
+	}
+
+	var targets []net.IP
+	if !all {
+		host := targetHost(domain)
+		addrs, err := u.Resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return result, fmt.Errorf("could not resolve %s to filter sockets (use --all-sockets to list every socket): %w", host, err)
+		}
+		for _, addr := range addrs {
+			targets = append(targets, addr.IP)
+			result.Filter = append(result.Filter, addr.IP.String())
+		}
+	}
+
+	sockets, err := readSockets(u.ProcFS)
+	if err != nil {
+		return result, err
+	}
+	result.Total = len(sockets)
+
+	owners := socketOwners(u.ProcFS)
+	for _, s := range sockets {
+		if !all && !matchesAny(s.localIP, targets) && !matchesAny(s.remoteIP, targets) {
+			continue
+		}
+		conn := s.NetstatConnection
+		if owner, ok := owners[s.inode]; ok {
+			conn.PID = owner.pid
+			conn.Process = owner.name
+		}
+		result.Connections = append(result.Connections, conn)
+		result.States[conn.Status]++
+	}
+
+	return result, nil
+}
+
+type procSocket struct {
+	models.NetstatConnection
+	localIP  net.IP
+	remoteIP net.IP
+	inode    string
+}
+
+// readSockets parses every socket table under procFS. Missing tables, such as
+// tcp6 on a host without IPv6, are skipped.
+func readSockets(procFS string) ([]procSocket, error) {
+	var sockets []procSocket
+	read := 0
+	for _, table := range socketTables {
+		f, err := os.Open(filepath.Join(procFS, "net", table))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		parsed, err := parseSocketTable(table, f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", f.Name(), err)
+		}
+		sockets = append(sockets, parsed...)
+		read++
+	}
+	if read == 0 {
+		return nil, fmt.Errorf("no socket tables found under %s/net", procFS)
+	}
+	return sockets, nil
+}
+
+func parseSocketTable(protocol string, r io.Reader) ([]procSocket, error) {
+	var sockets []procSocket
+	scanner := bufio.NewScanner(r)
+	scanner.Scan() // Skip the header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		localIP, localPort, err := parseHexAddress(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		remoteIP, remotePort, err := parseHexAddress(fields[2])
+		if err != nil {
+			return nil, err
+		}
+
+		state := tcpStates[fields[3]]
+		if strings.HasPrefix(protocol, "udp") {
+			// UDP sockets are either connected or not; "CLOSE" reads oddly.
+			state = "UNCONN"
+			if fields[3] == "01" {
+				state = "ESTABLISHED"
+			}
+		}
+		if state == "" {
+			state = "UNKNOWN"
+		}
+
+		sockets = append(sockets, procSocket{
+			NetstatConnection: models.NetstatConnection{
+				Protocol:      protocol,
+				LocalAddress:  net.JoinHostPort(localIP.String(), strconv.Itoa(localPort)),
+				RemoteAddress: net.JoinHostPort(remoteIP.String(), strconv.Itoa(remotePort)),
+				Status:        state,
+			},
+			localIP:  localIP,
+			remoteIP: remoteIP,
+			inode:    fields[9],
+		})
+	}
+	return sockets, scanner.Err()
+}
+
+// parseHexAddress decodes "0100007F:1F90" style addresses. The kernel prints
+// the address as 32-bit words in host byte order.
+func parseHexAddress(value string) (net.IP, int, error) {
+	hexIP, hexPort, ok := strings.Cut(value, ":")
+	if !ok {
+		return nil, 0, fmt.Errorf("malformed address '%s'", value)
+	}
+	raw, err := hex.DecodeString(hexIP)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("malformed address '%s'", value)
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("malformed port in '%s'", value)
+	}
+
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.NativeEndian.Uint32(raw[i:]))
+	}
+	return ip, int(port), nil
+}
+
+type socketOwner struct {
+	pid  int
+	name string
+}
+
+// socketOwners maps socket inodes to the process holding them by walking
+// /proc/<pid>/fd. Processes of other users are unreadable without root and
+// are left out.
+func socketOwners(procFS string) map[string]socketOwner {
+	owners := make(map[string]socketOwner)
+	entries, err := os.ReadDir(procFS)
+	if err != nil {
+		return owners
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join(procFS, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+
+		var name string
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode := strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")
+			if _, seen := owners[inode]; seen {
+				continue
+			}
+			if name == "" {
+				comm, _ := os.ReadFile(filepath.Join(procFS, entry.Name(), "comm"))
+				name = strings.TrimSpace(string(comm))
+			}
+			owners[inode] = socketOwner{pid: pid, name: name}
+		}
+	}
+	return owners
+}
+
+func matchesAny(ip net.IP, targets []net.IP) bool {
+	for _, target := range targets {
+		if ip.Equal(target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// The kernel prints socket addresses as 32-bit words in host byte order, so
+// the captured tables under testdata/proc only decode on little-endian hosts.
+func skipOnBigEndian(t *testing.T) {
+	t.Helper()
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		t.Skip("fixtures were captured on a little-endian host")
+	}
+}
+
+func TestParseHexAddress(t *testing.T) {
+	skipOnBigEndian(t)
+
+	tests := []struct {
+		value string
+		ip    string
+		port  int
+		err   string
+	}{
+		{value: "0100007F:1F90", ip: "127.0.0.1", port: 8080},
+		{value: "00000000:0000", ip: "0.0.0.0", port: 0},
+		{value: "0101A8C0:FFFF", ip: "192.168.1.1", port: 65535},
+		{value: "00000000000000000000000001000000:24E4", ip: "::1", port: 9444},
+		{value: "00000000000000000000000000000000:0035", ip: "::", port: 53},
+		{value: "0000000000000000FFFF00000100007F:24E3", ip: "127.0.0.1", port: 9443},
+		{value: "B80D0120000000000000000001000000:01BB", ip: "2001:db8::1", port: 443},
+		{value: "B80D01200000A3852E8A000034737003:0050", ip: "2001:db8:85a3::8a2e:370:7334", port: 80},
+		{value: "000080FE00000000785634120000DCFE:0016", ip: "fe80::1234:5678:fedc:0", port: 22},
+		{value: "0100007F", err: "malformed address"},
+		{value: "0100007:1F90", err: "malformed address"},
+		{value: "0100007G:1F90", err: "malformed address"},
+		{value: "000000000100007F:1F90", err: "malformed address"},
+		{value: "0100007F:10000", err: "malformed port"},
+		{value: "0100007F:", err: "malformed port"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			ip, port, err := parseHexAddress(tt.value)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ip.String() != tt.ip || port != tt.port {
+				t.Errorf("parseHexAddress(%s) = %s %d, want %s %d", tt.value, ip, port, tt.ip, tt.port)
+			}
+		})
+	}
+}
+
+func TestParseSocketTable(t *testing.T) {
+	skipOnBigEndian(t)
+
+	table := "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n" +
+		"   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 100 1 0 100 0 0 10 0\n" +
+		"   1: truncated line\n" +
+		"   2: 0100007F:1F90 0100007F:D431 63 00000000:00000000 00:00000000 00000000     0        0 101 1 0 100 0 0 10 0\n"
+	sockets, err := parseSocketTable("tcp", strings.NewReader(table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := socketLines(sockets)
+	want := []string{
+		"tcp 127.0.0.1:8080 0.0.0.0:0 LISTEN inode 100",
+		"tcp 127.0.0.1:8080 127.0.0.1:54321 UNKNOWN inode 101",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sockets =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	malformed := "header\n   0: 0100007F:1F90 nonsense 0A 00000000:00000000 00:00000000 00000000 0 0 100 1\n"
+	if _, err := parseSocketTable("tcp", strings.NewReader(malformed)); err == nil {
+		t.Error("expected an error for a malformed remote address")
+	}
+}
+
+// testdata/proc/net holds the socket tables of a host with an IPv4 and
+// dual-stack IPv6 listener, connections to both, UDP sockets and TIME_WAIT
+// leftovers; testdata/proc/4242 owns two of the sockets.
+func TestReadSocketsFixture(t *testing.T) {
+	skipOnBigEndian(t)
+
+	sockets, err := readSockets("testdata/proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sockets) != 18 {
+		t.Errorf("read %d sockets, want 18", len(sockets))
+	}
+
+	lines := socketLines(sockets)
+	for _, want := range []string{
+		"tcp 0.0.0.0:2024 0.0.0.0:0 LISTEN inode 662",
+		"tcp 127.0.0.1:8080 0.0.0.0:0 LISTEN inode 32562",
+		"tcp 127.0.0.1:45776 127.0.0.1:9443 ESTABLISHED inode 58125",
+		"tcp 127.0.0.1:36408 127.0.0.1:36974 TIME_WAIT inode 0",
+		"tcp6 [::]:9443 [::]:0 LISTEN inode 58123",
+		"tcp6 [::1]:9444 [::]:0 LISTEN inode 58124",
+		"tcp6 [::1]:33434 [::1]:9444 ESTABLISHED inode 58127",
+		"tcp6 127.0.0.1:9443 127.0.0.1:45776 ESTABLISHED inode 58126",
+		"udp 127.0.0.1:5300 127.0.0.1:53 ESTABLISHED inode 58130",
+		"udp6 [::1]:5353 [::]:0 UNCONN inode 58129",
+	} {
+		if !containsLine(lines, want) {
+			t.Errorf("sockets lack %q", want)
+		}
+	}
+}
+
+func TestRunSocketsFiltersByTarget(t *testing.T) {
+	skipOnBigEndian(t)
+
+	u := NewNetworkDebugUsecase(zap.NewNop(), NewFixtureRunner())
+	u.ProcFS = "testdata/proc"
+
+	result, err := u.runSockets(context.Background(), "[::1]:9444", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Filter, []string{"::1"}) || result.Total != 18 {
+		t.Errorf("filter = %q, total = %d; want [::1] out of 18", result.Filter, result.Total)
+	}
+
+	var got []string
+	for _, conn := range result.Connections {
+		got = append(got, strings.Join([]string{conn.Protocol, conn.LocalAddress, conn.RemoteAddress, conn.Status, conn.Process}, " "))
+	}
+	want := []string{
+		"tcp6 [::1]:9444 [::]:0 LISTEN ",
+		"tcp6 [::1]:33434 [::1]:9444 ESTABLISHED ",
+		"tcp6 [::1]:9444 [::1]:33434 ESTABLISHED ",
+		"udp6 [::1]:5353 [::]:0 UNCONN python3",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("connections =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if result.States["ESTABLISHED"] != 2 || result.States["LISTEN"] != 1 || result.States["TIME_WAIT"] != 0 {
+		t.Errorf("states = %v", result.States)
+	}
+}
+
+func TestSocketOwners(t *testing.T) {
+	owners := socketOwners("testdata/proc")
+	want := map[string]socketOwner{
+		"58126": {pid: 4242, name: "python3"},
+		"58129": {pid: 4242, name: "python3"},
+	}
+	if !reflect.DeepEqual(owners, want) {
+		t.Errorf("owners = %v, want %v", owners, want)
+	}
+}
+
+func socketLines(sockets []procSocket) []string {
+	lines := make([]string, len(sockets))
+	for i, s := range sockets {
+		lines[i] = strings.Join([]string{s.Protocol, s.LocalAddress, s.RemoteAddress, s.Status, "inode", s.inode}, " ")
+	}
+	return lines
+}
+
+func containsLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// DefaultTargetConcurrency bounds how many targets are diagnosed at once.
+const DefaultTargetConcurrency = 4
+
+// ReadTargets reads one target per line, skipping blank lines, # comments and
+// duplicates.
+func ReadTargets(r io.Reader) ([]string, error) {
+	var targets []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		target := strings.TrimSpace(line)
+		if target == "" || seen[target] {
+			continue
+		}
+		seen[target] = true
+		targets = append(targets, target)
+	}
+	return targets, scanner.Err()
+}
+
+// NetworkDebugTargets diagnoses every target with a pool of concurrency
+// workers and summarises each one. progress, when set, is called after each
+// target completes. Once ctx is cancelled no further target is started.
+func (u *NetworkDebugUsecase) NetworkDebugTargets(ctx context.Context, targets []string, opts DebugOptions, concurrency int, progress func(done, total int)) *models.MultiDebugResult {
+	if concurrency <= 0 {
+		concurrency = DefaultTargetConcurrency
+	}
+
+	result := &models.MultiDebugResult{
+		Timestamp: time.Now().UTC(),
+		Summary:   make([]models.TargetSummary, len(targets)),
+		Results:   make([]*models.NetworkDebugResult, len(targets)),
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+	for w := 0; w < concurrency && w < len(targets); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				debug, _ := u.NetworkDebug(ctx, targets[i], opts)
+				result.Results[i] = debug
+				result.Summary[i] = SummarizeTarget(debug)
+
+				mu.Lock()
+				done++
+				if progress != nil {
+					progress(done, len(targets))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := range targets {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Targets never started because ctx was cancelled are left out.
+	summary, results := result.Summary[:0], result.Results[:0]
+	for i, debug := range result.Results {
+		if debug != nil {
+			summary = append(summary, result.Summary[i])
+			results = append(results, debug)
+		}
+	}
+	result.Summary, result.Results = summary, results
+
+	return result
+}
+
+// SummarizeTarget reduces a diagnosis to the figures compared across targets.
+func SummarizeTarget(result *models.NetworkDebugResult) models.TargetSummary {
+	summary := models.TargetSummary{
+		Target:      result.Domain,
+		HTTPStatus:  result.HTTPRequest.StatusCode,
+		LossPercent: result.Ping.LossPercent,
+		Failed:      len(result.Errors) > 0,
+		Errors:      result.Errors,
+		Verdict:     result.Verdict.Status,
+	}
+
+	failed := failedChecks(result)
+	switch {
+	case net.ParseIP(targetHost(result.Domain)) != nil:
+		// An address needs no resolving.
+		summary.DNSOK = true
+	case !result.IsSkipped("dns"):
+		summary.DNSOK = failed["dns"] == "" && len(result.DNSLookup.Records) > 0
+	case !result.IsSkipped("nslookup"):
+		summary.DNSOK = failed["nslookup"] == "" && result.NSLookup.IP != ""
+	}
+
+	if result.HTTPRequest.StatusCode != 0 {
+		summary.Latency = result.HTTPRequest.Timings.Total
+	} else if result.Ping.Received > 0 {
+		summary.Latency = result.Ping.AvgLatency
+	}
+
+	if len(result.TLS.Chain) > 0 {
+		expiry := result.TLS.Chain[0].NotAfter
+		summary.TLSExpiry = &expiry
+	}
+
+	return summary
+}
@@ -0,0 +1,195 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+func TestReadTargets(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "one per line", input: "example.com\nexample.org:8443\n192.0.2.1\n", want: []string{"example.com", "example.org:8443", "192.0.2.1"}},
+		{name: "comments", input: "# production\nexample.com # the shop\n#example.org\n", want: []string{"example.com"}},
+		{name: "blanks and spaces", input: "\n   \n\texample.com  \n\n", want: []string{"example.com"}},
+		{name: "duplicates keep the first", input: "b.example\na.example\nb.example\n a.example # again\n", want: []string{"b.example", "a.example"}},
+		{name: "no trailing newline", input: "example.com", want: []string{"example.com"}},
+		{name: "only comments", input: "# nothing\n\n", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadTargets(strings.NewReader(tt.input))
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadTargets = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// skippedExcept lists every registered check but the ones that ran.
+func skippedExcept(ran ...string) []string {
+	skipped := []string{}
+	for _, name := range CheckNames() {
+		if !slices.Contains(ran, name) {
+			skipped = append(skipped, name)
+		}
+	}
+	return skipped
+}
+
+func TestSummarizeTargetDNS(t *testing.T) {
+	records := models.DNSLookupResult{Records: []models.DNSRecord{{Name: "example.com.", Type: "A", Value: "192.0.2.1"}}}
+	dnsFailed := []models.ToolError{{Tool: "dns", Message: "i/o timeout"}}
+	nsFailed := []models.ToolError{{Tool: "nslookup", Message: "no such host"}}
+
+	tests := []struct {
+		name   string
+		result models.NetworkDebugResult
+		want   bool
+	}{
+		{name: "address", result: models.NetworkDebugResult{Domain: "192.0.2.1", Skipped: skippedExcept("ping")}, want: true},
+		{name: "address with port", result: models.NetworkDebugResult{Domain: "[2001:db8::1]:8443", Skipped: skippedExcept("ports")}, want: true},
+		{name: "dns records", result: models.NetworkDebugResult{Domain: "example.com", Skipped: skippedExcept("dns"), DNSLookup: records}, want: true},
+		{name: "dns without records", result: models.NetworkDebugResult{Domain: "example.com", Skipped: skippedExcept("dns")}},
+		{name: "dns failed", result: models.NetworkDebugResult{Domain: "example.com", Skipped: skippedExcept("dns"), Errors: dnsFailed}},
+		{
+			name:   "dns is preferred over nslookup",
+			result: models.NetworkDebugResult{Domain: "example.com", Skipped: skippedExcept("dns", "nslookup"), NSLookup: models.NSLookupResult{IP: "192.0.2.1"}, Errors: dnsFailed},
+		},
+		{name: "nslookup address", result: models.NetworkDebugResult{Domain: "example.com", Skipped: skippedExcept("nslookup"), NSLookup: models.NSLookupResult{IP: "192.0.2.1"}}, want: true},
+		{name: "nslookup failed", result: models.NetworkDebugResult{Domain: "example.com", Skipped: skippedExcept("nslookup"), Errors: nsFailed}},
+		{name: "no resolving check", result: models.NetworkDebugResult{Domain: "example.com", Skipped: skippedExcept("http")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SummarizeTarget(&tt.result).DNSOK; got != tt.want {
+				t.Errorf("DNSOK = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeTargetFigures(t *testing.T) {
+	notAfter := time.Date(2027, 1, 2, 3, 4, 5, 0, time.UTC)
+	errs := []models.ToolError{{Tool: "ping", Message: "100% packet loss"}}
+	result := &models.NetworkDebugResult{
+		Domain:      "example.com",
+		Skipped:     []string{},
+		HTTPRequest: models.HTTPRequestResult{StatusCode: 503, Timings: models.HTTPTimings{Total: 80 * time.Millisecond}},
+		Ping:        models.PingResult{Received: 2, LossPercent: 50, AvgLatency: 12 * time.Millisecond},
+		TLS:         models.TLSResult{Chain: []models.TLSCertificate{{NotAfter: notAfter}, {}}},
+		Errors:      errs,
+		Verdict:     models.Verdict{Status: models.VerdictFail},
+	}
+
+	got := SummarizeTarget(result)
+	want := models.TargetSummary{
+		Target: "example.com", HTTPStatus: 503, Latency: 80 * time.Millisecond, LossPercent: 50,
+		TLSExpiry: &notAfter, Failed: true, Errors: errs, Verdict: models.VerdictFail,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("summary = %+v\nwant      %+v", got, want)
+	}
+
+	// Without an HTTP response the ping round trip stands in for the latency.
+	result.HTTPRequest = models.HTTPRequestResult{}
+	if got := SummarizeTarget(result).Latency; got != 12*time.Millisecond {
+		t.Errorf("Latency = %s, want the 12ms ping average", got)
+	}
+}
+
+// stubCheck stands in for the registered checks and reports what run returns
+// as its error.
+func stubCheck(run func(ctx context.Context, domain string) error) Check {
+	return Check{
+		Name: "stub",
+		run: func(ctx context.Context, u *NetworkDebugUsecase, domain string, opts DebugOptions) (func(*models.NetworkDebugResult), error) {
+			return nil, run(ctx, domain)
+		},
+	}
+}
+
+func TestNetworkDebugTargetsKeepsInputOrder(t *testing.T) {
+	// Later targets finish first.
+	delays := map[string]time.Duration{"a.example": 60 * time.Millisecond, "b.example": 30 * time.Millisecond, "c.example": 0}
+	check := stubCheck(func(ctx context.Context, domain string) error {
+		time.Sleep(delays[domain])
+		if domain == "b.example" {
+			return errors.New("unreachable")
+		}
+		return nil
+	})
+
+	var mu sync.Mutex
+	var progress []int
+	u := NewNetworkDebugUsecase(zap.NewNop(), nil)
+	result := u.NetworkDebugTargets(context.Background(), []string{"a.example", "b.example", "c.example"}, DebugOptions{Checks: []Check{check}}, 3, func(done, total int) {
+		mu.Lock()
+		defer mu.Unlock()
+		if total != 3 {
+			t.Errorf("progress total = %d, want 3", total)
+		}
+		progress = append(progress, done)
+	})
+
+	var targets []string
+	for i, debug := range result.Results {
+		targets = append(targets, debug.Domain)
+		if result.Summary[i].Target != debug.Domain {
+			t.Errorf("summary %d is for %s, result for %s", i, result.Summary[i].Target, debug.Domain)
+		}
+	}
+	if want := []string{"a.example", "b.example", "c.example"}; !reflect.DeepEqual(targets, want) {
+		t.Errorf("results = %q, want %q", targets, want)
+	}
+	if failed := []bool{result.Summary[0].Failed, result.Summary[1].Failed, result.Summary[2].Failed}; !reflect.DeepEqual(failed, []bool{false, true, false}) {
+		t.Errorf("failed = %v, want only b.example", failed)
+	}
+	if !reflect.DeepEqual(progress, []int{1, 2, 3}) {
+		t.Errorf("progress = %v, want 1, 2, 3", progress)
+	}
+}
+
+func TestNetworkDebugTargetsStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var started []string
+	check := stubCheck(func(_ context.Context, domain string) error {
+		mu.Lock()
+		started = append(started, domain)
+		mu.Unlock()
+		if domain == "a.example" {
+			cancel()
+			// Give the feeder time to see the cancellation while the only
+			// worker is still busy.
+			time.Sleep(50 * time.Millisecond)
+		}
+		return nil
+	})
+
+	u := NewNetworkDebugUsecase(zap.NewNop(), nil)
+	result := u.NetworkDebugTargets(ctx, []string{"a.example", "b.example", "c.example"}, DebugOptions{Checks: []Check{check}}, 1, nil)
+
+	if !reflect.DeepEqual(started, []string{"a.example"}) {
+		t.Errorf("started = %q, want only a.example", started)
+	}
+	if len(result.Results) != 1 || len(result.Summary) != 1 || result.Results[0].Domain != "a.example" || result.Summary[0].Target != "a.example" {
+		t.Errorf("result = %+v, want only a.example once the rest were pruned", result)
+	}
+}
@@ -0,0 +1,128 @@
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// DefaultTLSExpiryWarnDays is how close to expiry a certificate gets before it is flagged.
+const DefaultTLSExpiryWarnDays = 30
+
+// runTLS performs a handshake with the domain and records what was negotiated.
+// Verification is done by hand after the handshake so an untrusted or
+// mismatched certificate is reported instead of aborting the connection.
+func (u *NetworkDebugUsecase) runTLS(ctx context.Context, domain string, warnDays int) (models.TLSResult, error) {
+	host, port := domain, "443"
+	if h, p, err := net.SplitHostPort(domain); err == nil {
+		host, port = h, p
+	}
+	if warnDays <= 0 {
+		warnDays = DefaultTLSExpiryWarnDays
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: 10 * time.Second},
+		Config: &tls.Config{
+			ServerName:         host,
+			NextProtos:         []string{"h2", "http/1.1"},
+			InsecureSkipVerify: true,
+		},
+	}
+
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return models.TLSResult{}, err
+	}
+	defer conn.Close()
+	handshake := time.Since(start)
+
+	state := conn.(*tls.Conn).ConnectionState()
+	result := models.TLSResult{
+		Address:          conn.RemoteAddr().String(),
+		ServerName:       host,
+		Version:          tls.VersionName(state.Version),
+		CipherSuite:      tls.CipherSuiteName(state.CipherSuite),
+		ALPN:             state.NegotiatedProtocol,
+		HandshakeTime:    handshake,
+		OCSPStapled:      len(state.OCSPResponse) > 0,
+		ExpiryWarnDays:   warnDays,
+		HostnameVerified: true,
+		ChainVerified:    true,
+		Warnings:         []string{},
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		return result, fmt.Errorf("server presented no certificates")
+	}
+
+	now := time.Now()
+	for _, cert := range state.PeerCertificates {
+		result.Chain = append(result.Chain, certificateInfo(cert, now))
+	}
+
+	leaf := state.PeerCertificates[0]
+	if err := leaf.VerifyHostname(host); err != nil {
+		result.HostnameVerified = false
+		result.Warnings = append(result.Warnings, err.Error())
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{Intermediates: intermediates}); err != nil {
+		result.ChainVerified = false
+		result.VerifyError = err.Error()
+		result.Warnings = append(result.Warnings, fmt.Sprintf("certificate chain is not trusted: %v", err))
+	}
+
+	for _, cert := range result.Chain {
+		switch {
+		case cert.DaysToExpiry < 0:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("certificate %q expired on %s", cert.Subject, cert.NotAfter.Format(time.RFC3339)))
+		case cert.DaysToExpiry <= warnDays:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("certificate %q expires in %d days", cert.Subject, cert.DaysToExpiry))
+		}
+		if now.Before(cert.NotBefore) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("certificate %q is not valid until %s", cert.Subject, cert.NotBefore.Format(time.RFC3339)))
+		}
+	}
+
+	if state.Version < tls.VersionTLS12 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("server negotiated %s, TLS 1.2 or newer is expected", result.Version))
+	}
+
+	return result, nil
+}
+
+func certificateInfo(cert *x509.Certificate, now time.Time) models.TLSCertificate {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	return models.TLSCertificate{
+		Subject:      cert.Subject.String(),
+		Issuer:       cert.Issuer.String(),
+		SANs:         sans,
+		SerialNumber: cert.SerialNumber.String(),
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+		DaysToExpiry: daysUntil(cert.NotAfter, now),
+		IsCA:         cert.IsCA,
+	}
+}
+
+// daysUntil counts whole days from now to t, rounding down so a certificate
+// that expired an hour ago is -1 days from expiry rather than 0.
+func daysUntil(t, now time.Time) int {
+	return int(math.Floor(t.Sub(now).Hours() / 24))
+}
@@ -0,0 +1,173 @@
+package network
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// testCert issues a certificate for 127.0.0.1, signed by parent or, when
+// parent is nil, by itself.
+func testCert(t *testing.T, name string, notBefore, notAfter time.Time, isCA bool, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if !isCA {
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	}
+
+	signer, signerKey := template, any(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+	if parent != nil {
+		cert.Certificate = append(cert.Certificate, parent.Certificate...)
+	}
+	return cert
+}
+
+// tlsServer serves cert, with its chain, on 127.0.0.1.
+func tlsServer(t *testing.T, cert tls.Certificate) string {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	// runTLS hangs up right after the handshake, which the server would log.
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server.Listener.Addr().String()
+}
+
+func TestRunTLS(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	ca := testCert(t, "Test CA", now.Add(-day), now.Add(3650*day), true, nil)
+
+	tests := []struct {
+		name      string
+		notBefore time.Time
+		notAfter  time.Time
+		days      int
+		warning   string
+	}{
+		{name: "valid", notBefore: now.Add(-day), notAfter: now.Add(365*day + time.Hour), days: 365},
+		{name: "expiring", notBefore: now.Add(-day), notAfter: now.Add(10*day + time.Hour), days: 10, warning: `certificate "CN=leaf" expires in 10 days`},
+		{name: "expires today", notBefore: now.Add(-day), notAfter: now.Add(time.Hour), days: 0, warning: `certificate "CN=leaf" expires in 0 days`},
+		{name: "expired an hour ago", notBefore: now.Add(-day), notAfter: now.Add(-time.Hour), days: -1, warning: `certificate "CN=leaf" expired on `},
+		{name: "expired", notBefore: now.Add(-30 * day), notAfter: now.Add(-10*day - time.Hour), days: -11, warning: `certificate "CN=leaf" expired on `},
+		{name: "not yet valid", notBefore: now.Add(day), notAfter: now.Add(365*day + time.Hour), days: 365, warning: `certificate "CN=leaf" is not valid until`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaf := testCert(t, "leaf", tt.notBefore, tt.notAfter, false, &ca)
+			addr := tlsServer(t, leaf)
+			u := NewNetworkDebugUsecase(zap.NewNop(), nil)
+
+			result, err := u.runTLS(context.Background(), addr, 30)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Chain) != 2 || result.Chain[0].Subject != "CN=leaf" || result.Chain[1].Subject != "CN=Test CA" {
+				t.Fatalf("chain = %+v, want the leaf and the CA", result.Chain)
+			}
+			if got := result.Chain[0]; got.Issuer != "CN=Test CA" || got.IsCA || len(got.SANs) != 1 || got.SANs[0] != "127.0.0.1" {
+				t.Errorf("leaf = %+v", got)
+			}
+			if !result.Chain[1].IsCA || result.Chain[1].DaysToExpiry < 3649 {
+				t.Errorf("CA = %+v", result.Chain[1])
+			}
+			if result.Chain[0].DaysToExpiry != tt.days {
+				t.Errorf("DaysToExpiry = %d, want %d", result.Chain[0].DaysToExpiry, tt.days)
+			}
+			if result.ServerName != "127.0.0.1" || result.Address != addr || result.Version == "" || result.ExpiryWarnDays != 30 {
+				t.Errorf("result = %+v", result)
+			}
+
+			// The test CA is not in the system roots, so every chain is
+			// reported as untrusted, while the hostname matches the IP SAN.
+			if result.ChainVerified || !result.HostnameVerified {
+				t.Errorf("ChainVerified = %v, HostnameVerified = %v; want false, true", result.ChainVerified, result.HostnameVerified)
+			}
+			var expiry []string
+			for _, warning := range result.Warnings {
+				if !strings.HasPrefix(warning, "certificate chain is not trusted") {
+					expiry = append(expiry, warning)
+				}
+			}
+			if tt.warning == "" && len(expiry) != 0 || tt.warning != "" && (len(expiry) != 1 || !strings.HasPrefix(expiry[0], tt.warning)) {
+				t.Errorf("warnings = %q, want %q", expiry, tt.warning)
+			}
+		})
+	}
+}
+
+func TestRunTLSHostnameMismatch(t *testing.T) {
+	now := time.Now()
+	ca := testCert(t, "Test CA", now.Add(-time.Hour), now.Add(24*time.Hour), true, nil)
+	addr := tlsServer(t, testCert(t, "leaf", now.Add(-time.Hour), now.Add(24*time.Hour), false, &ca))
+	_, port, _ := net.SplitHostPort(addr)
+
+	// The certificate names 127.0.0.1 only.
+	result, err := NewNetworkDebugUsecase(zap.NewNop(), nil).runTLS(context.Background(), net.JoinHostPort("localhost", port), 30)
+	if err != nil {
+		t.Skipf("localhost does not reach the test server: %v", err)
+	}
+	if result.HostnameVerified || result.ServerName != "localhost" {
+		t.Errorf("HostnameVerified = %v for %s, want false", result.HostnameVerified, result.ServerName)
+	}
+}
+
+func TestDaysUntil(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	for offset, want := range map[time.Duration]int{
+		48 * time.Hour:              2,
+		47 * time.Hour:              1,
+		time.Minute:                 0,
+		0:                           0,
+		-time.Minute:                -1,
+		-24 * time.Hour:             -1,
+		-24*time.Hour - time.Minute: -2,
+	} {
+		if got := daysUntil(now.Add(offset), now); got != want {
+			t.Errorf("daysUntil(now%+v) = %d, want %d", offset, got, want)
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	TraceProtocolUDP  = "udp"
+	TraceProtocolICMP = "icmp"
+	TraceProtocolTCP  = "tcp"
+)
+
+// traceReply is what a single TTL-limited probe observed.
+type traceReply struct {
+	// Address answered the probe; nil when it timed out.
+	Address net.IP
+	RTT     time.Duration
+	// Reached is set when the answer came from the target itself.
+	Reached bool
+	Err     error
+}
+
+// tracer sends TTL-limited probes and matches the ICMP errors they provoke,
+// read from a raw ICMP socket, back to the probe through the original header
+// quoted in the error: the source port for UDP and TCP, the sequence for ICMP.
+type tracer struct {
+	target   net.IP
+	protocol string
+	port     int
+	timeout  time.Duration
+
+	conn  *icmp.PacketConn
+	proto int
+	id    int
+
+	mu      sync.Mutex
+	seq     int
+	pending map[string]chan traceReply
+}
+
+// newTracer opens the raw ICMP socket, which needs root or CAP_NET_RAW.
+func newTracer(target net.IP, protocol string, port int, timeout time.Duration) (*tracer, error) {
+	network, proto := "ip4:icmp", 1
+	if target.To4() == nil {
+		network, proto = "ip6:ipv6-icmp", 58
+	}
+	conn, err := icmp.ListenPacket(network, "")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tracer{
+		target:   target,
+		protocol: protocol,
+		port:     port,
+		timeout:  timeout,
+		conn:     conn,
+		proto:    proto,
+		id:       os.Getpid() & 0xffff,
+		pending:  make(map[string]chan traceReply),
+	}
+	go t.readLoop()
+	return t, nil
+}
+
+// rawSocketsDenied reports whether opening the raw ICMP socket newTracer
+// needs fails for lack of privileges, in which case traceroute falls back to
+// the traceroute binary.
+func rawSocketsDenied() bool {
+	conn, err := icmp.ListenPacket("ip4:icmp", "")
+	if err != nil {
+		return errors.Is(err, os.ErrPermission)
+	}
+	conn.Close()
+	return false
+}
+
+func (t *tracer) Close() error {
+	return t.conn.Close()
+}
+
+func (t *tracer) isIPv6() bool {
+	return t.proto == 58
+}
+
+// Probe sends one probe with the given TTL and waits for its answer.
+func (t *tracer) Probe(ctx context.Context, ttl int) traceReply {
+	switch t.protocol {
+	case TraceProtocolICMP:
+		return t.probeICMP(ctx, ttl)
+	case TraceProtocolTCP:
+		return t.probeTCP(ctx, ttl)
+	default:
+		return t.probeUDP(ctx, ttl)
+	}
+}
+
+func (t *tracer) register(key string) chan traceReply {
+	ch := make(chan traceReply, 1)
+	t.mu.Lock()
+	t.pending[key] = ch
+	t.mu.Unlock()
+	return ch
+}
+
+func (t *tracer) unregister(key string) {
+	t.mu.Lock()
+	delete(t.pending, key)
+	t.mu.Unlock()
+}
+
+func (t *tracer) deliver(key string, reply traceReply) {
+	t.mu.Lock()
+	ch, ok := t.pending[key]
+	delete(t.pending, key)
+	t.mu.Unlock()
+	if ok {
+		ch <- reply
+	}
+}
+
+func (t *tracer) wait(ctx context.Context, ch chan traceReply, start time.Time) traceReply {
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
+	select {
+	case reply := <-ch:
+		reply.RTT = time.Since(start)
+		return reply
+	case <-timer.C:
+		return traceReply{}
+	case <-ctx.Done():
+		return traceReply{Err: ctx.Err()}
+	}
+}
+
+func (t *tracer) probeICMP(ctx context.Context, ttl int) traceReply {
+	t.mu.Lock()
+	t.seq++
+	seq := t.seq & 0xffff
+	t.mu.Unlock()
+
+	echoType := icmp.Type(ipv4.ICMPTypeEcho)
+	if t.isIPv6() {
+		echoType = ipv6.ICMPTypeEchoRequest
+	}
+	packet, err := (&icmp.Message{
+		Type: echoType,
+		Body: &icmp.Echo{ID: t.id, Seq: seq, Data: []byte("teemo-traceroute")},
+	}).Marshal(nil)
+	if err != nil {
+		return traceReply{Err: err}
+	}
+
+	key := "icmp:" + strconv.Itoa(seq)
+	ch := t.register(key)
+	defer t.unregister(key)
+
+	// The TTL belongs to the shared socket, so setting it and sending must not
+	// interleave with another probe.
+	t.mu.Lock()
+	if t.isIPv6() {
+		err = t.conn.IPv6PacketConn().SetHopLimit(ttl)
+	} else {
+		err = t.conn.IPv4PacketConn().SetTTL(ttl)
+	}
+	start := time.Now()
+	if err == nil {
+		_, err = t.conn.WriteTo(packet, &net.IPAddr{IP: t.target})
+	}
+	t.mu.Unlock()
+	if err != nil {
+		return traceReply{Err: err}
+	}
+
+	return t.wait(ctx, ch, start)
+}
+
+func (t *tracer) probeUDP(ctx context.Context, ttl int) traceReply {
+	network := "udp4"
+	if t.isIPv6() {
+		network = "udp6"
+	}
+	conn, err := net.ListenPacket(network, ":0")
+	if err != nil {
+		return traceReply{Err: err}
+	}
+	defer conn.Close()
+
+	if t.isIPv6() {
+		err = ipv6.NewPacketConn(conn).SetHopLimit(ttl)
+	} else {
+		err = ipv4.NewPacketConn(conn).SetTTL(ttl)
+	}
+	if err != nil {
+		return traceReply{Err: err}
+	}
+
+	key := "udp:" + strconv.Itoa(conn.LocalAddr().(*net.UDPAddr).Port)
+	ch := t.register(key)
+	defer t.unregister(key)
+
+	start := time.Now()
+	if _, err := conn.WriteTo(make([]byte, 32), &net.UDPAddr{IP: t.target, Port: t.port}); err != nil {
+		return traceReply{Err: err}
+	}
+
+	// A service listening on the port answers directly instead of with a
+	// port unreachable.
+	go func() {
+		conn.SetReadDeadline(time.Now().Add(t.timeout))
+		if _, _, err := conn.ReadFrom(make([]byte, 1500)); err == nil {
+			t.deliver(key, traceReply{Address: t.target, Reached: true})
+		}
+	}()
+
+	return t.wait(ctx, ch, start)
+}
+
+func (t *tracer) probeTCP(ctx context.Context, ttl int) traceReply {
+	// key is only written by Control and only read once the dial has
+	// returned, so it needs no lock of its own.
+	var key string
+	ch := make(chan traceReply, 1)
+
+	// The source port identifies the SYN in the quoted header, so the socket
+	// is bound before connect to learn it and register the probe in time.
+	dialer := &net.Dialer{
+		Timeout: t.timeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			var err error
+			controlErr := c.Control(func(fd uintptr) {
+				var local syscall.Sockaddr = &syscall.SockaddrInet4{}
+				if t.isIPv6() {
+					local = &syscall.SockaddrInet6{}
+					err = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_UNICAST_HOPS, ttl)
+				} else {
+					err = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TTL, ttl)
+				}
+				if err != nil {
+					return
+				}
+				if err = syscall.Bind(int(fd), local); err != nil {
+					return
+				}
+				var sa syscall.Sockaddr
+				if sa, err = syscall.Getsockname(int(fd)); err != nil {
+					return
+				}
+				switch sa := sa.(type) {
+				case *syscall.SockaddrInet4:
+					key = "tcp:" + strconv.Itoa(sa.Port)
+				case *syscall.SockaddrInet6:
+					key = "tcp:" + strconv.Itoa(sa.Port)
+				}
+				t.mu.Lock()
+				t.pending[key] = ch
+				t.mu.Unlock()
+			})
+			if controlErr != nil {
+				return controlErr
+			}
+			return err
+		},
+	}
+
+	dialCtx, cancel := context.WithCancel(ctx)
+	dialed := make(chan traceReply, 1)
+	dialDone := make(chan struct{})
+	defer func() {
+		cancel()
+		<-dialDone
+		if key != "" {
+			t.unregister(key)
+		}
+	}()
+
+	start := time.Now()
+	go func() {
+		defer close(dialDone)
+		conn, err := dialer.DialContext(dialCtx, "tcp", net.JoinHostPort(t.target.String(), strconv.Itoa(t.port)))
+		switch {
+		case err == nil:
+			conn.Close()
+			dialed <- traceReply{Address: t.target, Reached: true, RTT: time.Since(start)}
+		case errors.Is(err, syscall.ECONNREFUSED):
+			dialed <- traceReply{Address: t.target, Reached: true, RTT: time.Since(start)}
+		default:
+			dialed <- traceReply{Err: err}
+		}
+	}()
+
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case reply := <-dialed:
+			switch {
+			case reply.Err == nil, key == "":
+				return reply
+			case isTimeout(reply.Err):
+				return traceReply{}
+			}
+			// Connect may fail early on an ICMP error; keep waiting for the
+			// listener to report who sent it.
+			dialed = nil
+		case reply := <-ch:
+			reply.RTT = time.Since(start)
+			return reply
+		case <-timer.C:
+			return traceReply{}
+		case <-ctx.Done():
+			return traceReply{Err: ctx.Err()}
+		}
+	}
+}
+
+// readLoop dispatches ICMP time-exceeded, unreachable and echo replies to the
+// probes waiting for them until the socket is closed.
+func (t *tracer) readLoop() {
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := t.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		from := peer.(*net.IPAddr).IP
+
+		msg, err := icmp.ParseMessage(t.proto, buf[:n])
+		if err != nil {
+			continue
+		}
+
+		var quoted []byte
+		switch body := msg.Body.(type) {
+		case *icmp.TimeExceeded:
+			quoted = body.Data
+		case *icmp.DstUnreach:
+			quoted = body.Data
+		case *icmp.Echo:
+			if (msg.Type == ipv4.ICMPTypeEchoReply || msg.Type == ipv6.ICMPTypeEchoReply) && body.ID == t.id {
+				t.deliver("icmp:"+strconv.Itoa(body.Seq), traceReply{Address: from, Reached: true})
+			}
+			continue
+		default:
+			continue
+		}
+
+		key, dst, ok := quotedProbeKey(quoted, t.isIPv6(), t.id)
+		if !ok || !dst.Equal(t.target) {
+			continue
+		}
+		t.deliver(key, traceReply{Address: from, Reached: from.Equal(t.target)})
+	}
+}
+
+// quotedProbeKey reads the probe key and destination from the original
+// packet quoted in an ICMP error.
+func quotedProbeKey(data []byte, v6 bool, id int) (string, net.IP, bool) {
+	var proto byte
+	var dst net.IP
+	var payload []byte
+	if v6 {
+		if len(data) < 48 {
+			return "", nil, false
+		}
+		proto, dst, payload = data[6], net.IP(data[24:40]), data[40:]
+	} else {
+		if len(data) < 20 {
+			return "", nil, false
+		}
+		headerLen := int(data[0]&0x0f) * 4
+		if headerLen < 20 || len(data) < headerLen+8 {
+			return "", nil, false
+		}
+		proto, dst, payload = data[9], net.IP(data[16:20]), data[headerLen:]
+	}
+
+	switch proto {
+	case syscall.IPPROTO_UDP:
+		return "udp:" + strconv.Itoa(int(binary.BigEndian.Uint16(payload[0:2]))), dst, true
+	case syscall.IPPROTO_TCP:
+		return "tcp:" + strconv.Itoa(int(binary.BigEndian.Uint16(payload[0:2]))), dst, true
+	case syscall.IPPROTO_ICMP, syscall.IPPROTO_ICMPV6:
+		if int(binary.BigEndian.Uint16(payload[4:6])) != id {
+			return "", nil, false
+		}
+		return "icmp:" + strconv.Itoa(int(binary.BigEndian.Uint16(payload[6:8]))), dst, true
+	}
+	return "", nil, false
+}
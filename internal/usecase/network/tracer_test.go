@@ -0,0 +1,94 @@
+package network
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+	"testing"
+)
+
+// quotedIPv4 builds the original IPv4 packet an ICMP error quotes: a header
+// of ihl 32-bit words, options zeroed, followed by transport.
+func quotedIPv4(ihl int, proto byte, dst string, transport []byte) []byte {
+	header := make([]byte, ihl*4)
+	header[0] = 0x40 | byte(ihl)
+	header[9] = proto
+	copy(header[16:20], net.ParseIP(dst).To4())
+	return append(header, transport...)
+}
+
+// withIHL rewrites the header length field of a quoted IPv4 packet.
+func withIHL(packet []byte, ihl byte) []byte {
+	packet[0] = 0x40 | ihl
+	return packet
+}
+
+// quotedIPv6 builds the original IPv6 packet an ICMPv6 error quotes.
+func quotedIPv6(next byte, dst string, transport []byte) []byte {
+	header := make([]byte, 40)
+	header[0] = 0x60
+	header[6] = next
+	copy(header[24:40], net.ParseIP(dst).To16())
+	return append(header, transport...)
+}
+
+// ports is the first 8 bytes of a UDP or TCP header from src to dst.
+func ports(src, dst uint16) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint16(b[0:2], src)
+	binary.BigEndian.PutUint16(b[2:4], dst)
+	return b
+}
+
+// echo is the first 8 bytes of an ICMP echo request.
+func echo(id, seq uint16) []byte {
+	b := make([]byte, 8)
+	b[0] = 8
+	binary.BigEndian.PutUint16(b[4:6], id)
+	binary.BigEndian.PutUint16(b[6:8], seq)
+	return b
+}
+
+func TestQuotedProbeKey(t *testing.T) {
+	const id = 0x1234
+
+	tests := []struct {
+		name    string
+		data    []byte
+		v6      bool
+		wantKey string
+		wantDst string
+	}{
+		{name: "ipv4 udp", data: quotedIPv4(5, syscall.IPPROTO_UDP, "192.0.2.7", ports(40001, 33434)), wantKey: "udp:40001", wantDst: "192.0.2.7"},
+		{name: "ipv4 tcp", data: quotedIPv4(5, syscall.IPPROTO_TCP, "192.0.2.7", ports(40002, 443)), wantKey: "tcp:40002", wantDst: "192.0.2.7"},
+		{name: "ipv4 icmp", data: quotedIPv4(5, syscall.IPPROTO_ICMP, "192.0.2.7", echo(id, 9)), wantKey: "icmp:9", wantDst: "192.0.2.7"},
+		{name: "ipv4 icmp of another process", data: quotedIPv4(5, syscall.IPPROTO_ICMP, "192.0.2.7", echo(id+1, 9))},
+		{name: "ipv4 options", data: quotedIPv4(6, syscall.IPPROTO_UDP, "198.51.100.1", ports(40003, 33435)), wantKey: "udp:40003", wantDst: "198.51.100.1"},
+		{name: "ipv4 longest header", data: quotedIPv4(15, syscall.IPPROTO_TCP, "198.51.100.1", ports(40004, 80)), wantKey: "tcp:40004", wantDst: "198.51.100.1"},
+		{name: "ipv4 header length below minimum", data: withIHL(quotedIPv4(5, syscall.IPPROTO_UDP, "192.0.2.7", ports(40001, 33434)), 4)},
+		{name: "ipv4 too short for a header", data: quotedIPv4(5, syscall.IPPROTO_UDP, "192.0.2.7", nil)[:19]},
+		{name: "ipv4 too short for the ports", data: quotedIPv4(5, syscall.IPPROTO_UDP, "192.0.2.7", ports(40001, 33434))[:27]},
+		{name: "ipv4 options cut the ports short", data: quotedIPv4(6, syscall.IPPROTO_UDP, "192.0.2.7", ports(40001, 33434))[:28]},
+		{name: "ipv4 other protocol", data: quotedIPv4(5, 47, "192.0.2.7", ports(1, 2))},
+		{name: "ipv6 udp", data: quotedIPv6(syscall.IPPROTO_UDP, "2001:db8::7", ports(40005, 33434)), v6: true, wantKey: "udp:40005", wantDst: "2001:db8::7"},
+		{name: "ipv6 tcp", data: quotedIPv6(syscall.IPPROTO_TCP, "2001:db8::7", ports(40006, 443)), v6: true, wantKey: "tcp:40006", wantDst: "2001:db8::7"},
+		{name: "ipv6 icmp", data: quotedIPv6(syscall.IPPROTO_ICMPV6, "2001:db8::7", echo(id, 3)), v6: true, wantKey: "icmp:3", wantDst: "2001:db8::7"},
+		{name: "ipv6 icmp of another process", data: quotedIPv6(syscall.IPPROTO_ICMPV6, "2001:db8::7", echo(7, 3)), v6: true},
+		{name: "ipv6 too short", data: quotedIPv6(syscall.IPPROTO_UDP, "2001:db8::7", ports(40005, 33434))[:47], v6: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, dst, ok := quotedProbeKey(tt.data, tt.v6, id)
+			if tt.wantKey == "" {
+				if ok {
+					t.Errorf("quotedProbeKey = %q, %s; want no match", key, dst)
+				}
+				return
+			}
+			if !ok || key != tt.wantKey || !dst.Equal(net.ParseIP(tt.wantDst)) {
+				t.Errorf("quotedProbeKey = %q, %s, %v; want %q, %s", key, dst, ok, tt.wantKey, tt.wantDst)
+			}
+		})
+	}
+}
@@ -0,0 +1,420 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+const (
+	DefaultMaxHops      = 30
+	DefaultProbesPerHop = 3
+
+	defaultTraceTimeout = 2 * time.Second
+	defaultTraceUDPPort = 33434
+	defaultTraceTCPPort = 80
+	// traceStagger spaces out the start of each TTL so routers that rate
+	// limit ICMP are not hit by every probe at once.
+	traceStagger = 25 * time.Millisecond
+	// lookupTimeout bounds each reverse DNS and ASN lookup.
+	lookupTimeout = 2 * time.Second
+)
+
+// TracerouteOptions configures the traceroute check.
+type TracerouteOptions struct {
+	// Protocol is udp, icmp or tcp (a TCP SYN to Port).
+	Protocol     string
+	MaxHops      int
+	ProbesPerHop int
+	FirstTTL     int
+	// Port is the UDP or TCP destination port; 0 picks 33434 for udp and 80 for tcp.
+	Port int
+	// Timeout bounds how long each probe waits for an answer.
+	Timeout time.Duration
+	// Numeric skips the reverse DNS and ASN lookups.
+	Numeric bool
+}
+
+// ParseTraceProtocol validates a --trace-protocol value.
+func ParseTraceProtocol(value string) (string, error) {
+	switch value {
+	case "":
+		return TraceProtocolUDP, nil
+	case TraceProtocolUDP, TraceProtocolICMP, TraceProtocolTCP:
+		return value, nil
+	}
+	return "", fmt.Errorf("unknown traceroute protocol '%s' (available: udp, icmp, tcp)", value)
+}
+
+func (o *TracerouteOptions) setDefaults() error {
+	protocol, err := ParseTraceProtocol(o.Protocol)
+	if err != nil {
+		return err
+	}
+	o.Protocol = protocol
+	if o.MaxHops <= 0 {
+		o.MaxHops = DefaultMaxHops
+	}
+	if o.ProbesPerHop <= 0 {
+		o.ProbesPerHop = DefaultProbesPerHop
+	}
+	if o.FirstTTL <= 0 {
+		o.FirstTTL = 1
+	}
+	if o.FirstTTL > o.MaxHops {
+		return fmt.Errorf("first TTL %d is beyond the maximum of %d hops", o.FirstTTL, o.MaxHops)
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = defaultTraceTimeout
+	}
+	if o.Port == 0 {
+		switch o.Protocol {
+		case TraceProtocolUDP:
+			o.Port = defaultTraceUDPPort
+		case TraceProtocolTCP:
+			o.Port = defaultTraceTCPPort
+		}
+	}
+	return nil
+}
+
+// runTraceroute traces the path to the domain. Every TTL is probed
+// concurrently and the hops past the first one the target answered from are
+// dropped. Without permission to open raw sockets it falls back to the
+// traceroute binary.
+func (u *NetworkDebugUsecase) runTraceroute(ctx context.Context, domain string, opts TracerouteOptions) (models.TracerouteResult, error) {
+	if err := opts.setDefaults(); err != nil {
+		return models.TracerouteResult{}, err
+	}
+
+	target, err := u.resolveTarget(ctx, domain)
+	if err != nil {
+		return models.TracerouteResult{}, err
+	}
+
+	result := models.TracerouteResult{
+		Target:   target.String(),
+		Protocol: opts.Protocol,
+		Hops:     []models.TracerouteHop{},
+	}
+	if opts.Protocol != TraceProtocolICMP {
+		result.Port = opts.Port
+	}
+
+	var replies [][]traceReply
+	t, err := newTracer(target, opts.Protocol, opts.Port, opts.Timeout)
+	if err != nil {
+		if !errors.Is(err, os.ErrPermission) {
+			return result, err
+		}
+		replies, err = u.tracerouteExec(ctx, target, opts)
+		if err != nil {
+			return result, fmt.Errorf("raw sockets are not permitted and the traceroute binary failed: %w", err)
+		}
+		result.Note = "raw sockets are not permitted, used the traceroute binary"
+	} else {
+		defer t.Close()
+		replies = probeHops(ctx, t, opts)
+	}
+
+	for i, hopReplies := range replies {
+		hop := buildHop(opts.FirstTTL+i, hopReplies)
+		result.Hops = append(result.Hops, hop)
+		if hop.Reached {
+			result.Reached = true
+			break
+		}
+	}
+
+	if !opts.Numeric {
+		u.annotateHops(ctx, result.Hops)
+	}
+
+	return result, ctx.Err()
+}
+
+func (u *NetworkDebugUsecase) resolveTarget(ctx context.Context, domain string) (net.IP, error) {
+	host := targetHost(domain)
+	addrs, err := u.Resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no IP address found for %s", host)
+	}
+	return preferIPv4(addrs)[0].IP, nil
+}
+
+// probeHops sends ProbesPerHop probes for every TTL, one after the other
+// within a hop and concurrently across hops.
+func probeHops(ctx context.Context, t *tracer, opts TracerouteOptions) [][]traceReply {
+	replies := make([][]traceReply, opts.MaxHops-opts.FirstTTL+1)
+	var wg sync.WaitGroup
+	for i := range replies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(i) * traceStagger):
+			}
+			for probe := 0; probe < opts.ProbesPerHop; probe++ {
+				reply := t.Probe(ctx, opts.FirstTTL+i)
+				if reply.Err != nil && ctx.Err() != nil {
+					return
+				}
+				replies[i] = append(replies[i], reply)
+			}
+		}(i)
+	}
+	wg.Wait()
+	return replies
+}
+
+// buildHop summarises the replies to one TTL. Load-balanced paths can answer
+// a single TTL from several routers, each is kept as a responder.
+func buildHop(ttl int, replies []traceReply) models.TracerouteHop {
+	hop := models.TracerouteHop{
+		HopNumber:  ttl,
+		Sent:       len(replies),
+		Responders: []models.TracerouteResponder{},
+		Probes:     []models.TracerouteProbe{},
+	}
+
+	index := make(map[string]int)
+	var rtts []time.Duration
+	for _, reply := range replies {
+		if reply.Address == nil {
+			probe := models.TracerouteProbe{Timeout: true}
+			if reply.Err != nil {
+				probe.Error = reply.Err.Error()
+			}
+			hop.Probes = append(hop.Probes, probe)
+			continue
+		}
+
+		address := reply.Address.String()
+		hop.Probes = append(hop.Probes, models.TracerouteProbe{Address: address, RTT: reply.RTT})
+		rtts = append(rtts, reply.RTT)
+		if reply.Reached {
+			hop.Reached = true
+		}
+
+		i, ok := index[address]
+		if !ok {
+			i = len(hop.Responders)
+			index[address] = i
+			hop.Responders = append(hop.Responders, models.TracerouteResponder{Address: address})
+		}
+		hop.Responders[i].Replies++
+	}
+
+	hop.Received = len(rtts)
+	if hop.Sent > 0 {
+		hop.LossPercent = float64(hop.Sent-hop.Received) * 100 / float64(hop.Sent)
+	}
+	if len(hop.Responders) > 0 {
+		hop.Address = hop.Responders[0].Address
+	}
+	if len(rtts) > 0 {
+		var sum time.Duration
+		hop.MinRTT, hop.MaxRTT = rtts[0], rtts[0]
+		for _, rtt := range rtts {
+			sum += rtt
+			hop.MinRTT = min(hop.MinRTT, rtt)
+			hop.MaxRTT = max(hop.MaxRTT, rtt)
+		}
+		hop.AvgRTT = sum / time.Duration(len(rtts))
+	}
+
+	return hop
+}
+
+// annotateHops adds reverse DNS names and origin ASNs to every responder.
+func (u *NetworkDebugUsecase) annotateHops(ctx context.Context, hops []models.TracerouteHop) {
+	type annotation struct {
+		hostname, asn, asName string
+	}
+
+	annotations := make(map[string]*annotation)
+	for _, hop := range hops {
+		for _, responder := range hop.Responders {
+			annotations[responder.Address] = &annotation{}
+		}
+	}
+
+	var wg sync.WaitGroup
+	for address, a := range annotations {
+		wg.Add(1)
+		go func(address string, a *annotation) {
+			defer wg.Done()
+			lookupCtx, cancel := context.WithTimeout(ctx, lookupTimeout)
+			defer cancel()
+			if names, err := u.Resolver.LookupAddr(lookupCtx, address); err == nil && len(names) > 0 {
+				a.hostname = strings.TrimSuffix(names[0], ".")
+			}
+			a.asn, a.asName = lookupASN(lookupCtx, net.ParseIP(address))
+		}(address, a)
+	}
+	wg.Wait()
+
+	for i := range hops {
+		for j := range hops[i].Responders {
+			responder := &hops[i].Responders[j]
+			a := annotations[responder.Address]
+			responder.Hostname, responder.ASN, responder.ASName = a.hostname, a.asn, a.asName
+		}
+		if len(hops[i].Responders) > 0 {
+			hops[i].Hostname = hops[i].Responders[0].Hostname
+			hops[i].ASN = hops[i].Responders[0].ASN
+		}
+	}
+}
+
+// lookupASN asks Team Cymru's IP-to-ASN DNS service which AS originates the
+// address and what it is called. Private addresses are not looked up.
+func lookupASN(ctx context.Context, ip net.IP) (string, string) {
+	if ip == nil || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() {
+		return "", ""
+	}
+
+	var name string
+	if v4 := ip.To4(); v4 != nil {
+		name = fmt.Sprintf("%d.%d.%d.%d.origin.asn.cymru.com", v4[3], v4[2], v4[1], v4[0])
+	} else {
+		hex := fmt.Sprintf("%x", []byte(ip.To16()))
+		nibbles := make([]string, len(hex))
+		for i := range hex {
+			nibbles[len(hex)-1-i] = string(hex[i])
+		}
+		name = strings.Join(nibbles, ".") + ".origin6.asn.cymru.com"
+	}
+
+	// "13335 | 1.1.1.0/24 | AU | apnic | 2011-08-11"
+	fields := cymruTXT(ctx, name)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	asn := strings.Fields(fields[0])
+	if len(asn) == 0 {
+		return "", ""
+	}
+
+	// "13335 | US | arin | 2010-07-14 | CLOUDFLARENET, US"
+	var asName string
+	if fields := cymruTXT(ctx, "AS"+asn[0]+".asn.cymru.com"); len(fields) == 5 {
+		asName = fields[4]
+	}
+	return "AS" + asn[0], asName
+}
+
+func cymruTXT(ctx context.Context, name string) []string {
+	client := &DNSClient{}
+	lookup, err := client.LookupType(ctx, name, "TXT")
+	if err != nil {
+		return nil
+	}
+	for _, record := range lookup.Records {
+		if record.Type != "TXT" {
+			continue
+		}
+		// Values are quoted strings, only the first one matters here.
+		quoted, err := strconv.QuotedPrefix(record.Value)
+		if err != nil {
+			continue
+		}
+		unquoted, _ := strconv.Unquote(quoted)
+		fields := strings.Split(unquoted, "|")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		return fields
+	}
+	return nil
+}
+
+// tracerouteExec runs the traceroute binary, which ships setuid or with
+// capabilities on most distributions, and parses its numeric output.
+func (u *NetworkDebugUsecase) tracerouteExec(ctx context.Context, target net.IP, opts TracerouteOptions) ([][]traceReply, error) {
+	args := []string{
+		"-n",
+		"-m", strconv.Itoa(opts.MaxHops),
+		"-f", strconv.Itoa(opts.FirstTTL),
+		"-q", strconv.Itoa(opts.ProbesPerHop),
+		"-w", strconv.FormatFloat(opts.Timeout.Seconds(), 'f', -1, 64),
+	}
+	switch opts.Protocol {
+	case TraceProtocolICMP:
+		args = append(args, "-I")
+	case TraceProtocolTCP:
+		args = append(args, "-T", "-p", strconv.Itoa(opts.Port))
+	default:
+		args = append(args, "-p", strconv.Itoa(opts.Port))
+	}
+	args = append(args, target.String())
+
+	res, err := u.runCommand(ctx, "traceroute", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTraceroute(res.Stdout, target, opts.FirstTTL), nil
+}
+
+// parseTraceroute reads `traceroute -n` output such as
+//
+//	3  10.0.0.1  5.123 ms 10.0.0.2  6.001 ms  *
+//
+// where a hop can list several responders and "*" marks a probe that timed out.
+func parseTraceroute(output []byte, target net.IP, firstTTL int) [][]traceReply {
+	var replies [][]traceReply
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		ttl, err := strconv.Atoi(fields[0])
+		if err != nil || ttl < firstTTL {
+			continue // Header or noise
+		}
+		for len(replies) <= ttl-firstTTL {
+			replies = append(replies, nil)
+		}
+
+		var hop []traceReply
+		var current net.IP
+		for i := 1; i < len(fields); i++ {
+			field := fields[i]
+			switch {
+			case field == "*":
+				hop = append(hop, traceReply{})
+			case net.ParseIP(field) != nil:
+				current = net.ParseIP(field)
+			case i+1 < len(fields) && fields[i+1] == "ms" && current != nil:
+				ms, err := strconv.ParseFloat(field, 64)
+				if err != nil {
+					continue
+				}
+				hop = append(hop, traceReply{
+					Address: current,
+					RTT:     time.Duration(math.Round(ms * float64(time.Millisecond))),
+					Reached: current.Equal(target),
+				})
+				i++
+			}
+			// Annotations such as !H or !X are ignored.
+		}
+		replies[ttl-firstTTL] = hop
+	}
+	return replies
+}
@@ -0,0 +1,266 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// The testdata/traceroute directories hold `traceroute -n` output as
+// LoadFixtureRunner replays it.
+func TestTracerouteExecFixtures(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		opts    TracerouteOptions
+		command string
+		hops    []string
+		err     string
+	}{
+		{
+			name:    "udp",
+			target:  "93.184.216.34",
+			command: "traceroute -n -m 30 -f 1 -q 3 -w 2 -p 33434 93.184.216.34",
+			hops: []string{
+				"192.168.1.1 412µs, 192.168.1.1 378µs, 192.168.1.1 361µs",
+				"100.64.0.1 8.214ms, 100.64.0.1 8.198ms, 100.64.0.1 8.305ms",
+				"*, *, *",
+				"72.14.215.85 10.112ms, 72.14.215.87 10.54ms, 72.14.215.85 10.098ms",
+				"93.184.216.34 11.872ms reached, 93.184.216.34 11.801ms reached, 93.184.216.34 11.934ms reached",
+			},
+		},
+		{
+			name:    "icmp6",
+			target:  "2606:2800:220:1:248:1893:25c8:1946",
+			opts:    TracerouteOptions{Protocol: TraceProtocolICMP},
+			command: "traceroute -n -m 30 -f 1 -q 3 -w 2 -I 2606:2800:220:1:248:1893:25c8:1946",
+			hops: []string{
+				"2001:db8:1::1 521µs, 2001:db8:1::1 488µs, 2001:db8:1::1 470µs",
+				"*, 2001:db8:ff::1 9.114ms, *",
+				"2606:2800:220:1:248:1893:25c8:1946 12.006ms reached, 2606:2800:220:1:248:1893:25c8:1946 11.987ms reached, 2606:2800:220:1:248:1893:25c8:1946 12.044ms reached",
+			},
+		},
+		{
+			name:    "unreachable",
+			target:  "10.9.8.7",
+			opts:    TracerouteOptions{FirstTTL: 3},
+			command: "traceroute -n -m 30 -f 3 -q 3 -w 2 -p 33434 10.9.8.7",
+			hops: []string{
+				"10.0.0.1 1.204ms, 10.0.0.1 1.187ms, 10.0.0.1 1.166ms",
+				"10.9.0.1 3.311ms, 10.9.0.1 3.298ms, 10.9.0.1 3.402ms",
+			},
+		},
+		{
+			name:    "tcp-denied",
+			target:  "93.184.216.34",
+			opts:    TracerouteOptions{Protocol: TraceProtocolTCP},
+			command: "traceroute -n -m 30 -f 1 -q 3 -w 2 -T -p 80 93.184.216.34",
+			err:     "traceroute -n -m 30 -f 1 -q 3 -w 2 -T -p 80 93.184.216.34: exit status 1: You do not have enough privileges to use this traceroute method.\nsocket: Operation not permitted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, err := LoadFixtureRunner(filepath.Join("testdata", "traceroute", tt.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			u := NewNetworkDebugUsecase(zap.NewNop(), runner)
+			opts := tt.opts
+			if err := opts.setDefaults(); err != nil {
+				t.Fatal(err)
+			}
+
+			replies, err := u.tracerouteExec(context.Background(), net.ParseIP(tt.target), opts)
+
+			if calls := runner.Calls(); len(calls) != 1 || calls[0] != tt.command {
+				t.Errorf("calls = %q, want [%q]", calls, tt.command)
+			}
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := formatReplies(replies); !reflect.DeepEqual(got, tt.hops) {
+				t.Errorf("hops =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.hops, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseTracerouteSkipsHeaderAndNoise(t *testing.T) {
+	output := "traceroute to 10.0.0.9 (10.0.0.9), 30 hops max, 60 byte packets\n" +
+		"\n" +
+		" 1  bogus line\n" +
+		" 2  10.0.0.9  abc ms  1.000 ms\n"
+
+	got := formatReplies(parseTraceroute([]byte(output), net.ParseIP("10.0.0.9"), 1))
+	want := []string{"", "10.0.0.9 1ms reached"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("hops = %q, want %q", got, want)
+	}
+}
+
+func TestRecordingRunnerRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	source := NewFixtureRunner()
+	source.Add("traceroute", &CommandResult{Stdout: []byte(" 1  10.0.0.1  1.000 ms\n")})
+	source.Add("sudo ping -c 1 10.0.0.1", &CommandResult{Stderr: []byte("unreachable\n"), ExitCode: 2})
+
+	recorder := &RecordingRunner{Runner: source, Dir: dir}
+	if _, err := recorder.Run(context.Background(), "traceroute", "-n", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recorder.Run(context.Background(), "sudo", "ping", "-c", "1", "10.0.0.1"); err == nil {
+		t.Fatal("expected the failing ping to return an error")
+	}
+
+	replay, err := LoadFixtureRunner(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := replay.Run(context.Background(), "traceroute", "-q", "1", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Stdout) != " 1  10.0.0.1  1.000 ms\n" || res.Command != "traceroute -q 1 10.0.0.1" {
+		t.Errorf("replayed traceroute = %+v", res)
+	}
+	res, err = replay.Run(context.Background(), "ping", "-c", "1", "10.0.0.1")
+	if err == nil || res.ExitCode != 2 || !strings.Contains(err.Error(), "unreachable") {
+		t.Errorf("replayed ping = %+v, %v; want exit code 2 with its stderr", res, err)
+	}
+}
+
+func TestFixtureRunnerPrefersFullCommandLine(t *testing.T) {
+	runner := NewFixtureRunner()
+	runner.Add("dig", &CommandResult{Stdout: []byte("tool")})
+	runner.Add("dig example.com", &CommandResult{Stdout: []byte("line")})
+
+	for args, want := range map[string]string{"example.com": "line", "example.org": "tool"} {
+		res, err := runner.Run(context.Background(), "dig", args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(res.Stdout) != want {
+			t.Errorf("dig %s replayed %q, want %q", args, res.Stdout, want)
+		}
+	}
+
+	if _, err := runner.Run(context.Background(), "nslookup", "example.com"); err == nil {
+		t.Error("expected an error for a tool without fixture")
+	}
+}
+
+func TestBuildHop(t *testing.T) {
+	ms := time.Millisecond
+	a, b, target := net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2"), net.ParseIP("192.0.2.1")
+
+	tests := []struct {
+		name    string
+		replies []traceReply
+		want    models.TracerouteHop
+	}{
+		{
+			name: "mixed responders and timeouts",
+			replies: []traceReply{
+				{Address: a, RTT: 10 * ms},
+				{},
+				{Address: b, RTT: 30 * ms},
+				{Address: a, RTT: 20 * ms},
+				{Err: errors.New("network is unreachable")},
+			},
+			want: models.TracerouteHop{
+				HopNumber: 4,
+				Address:   "10.0.0.1",
+				Responders: []models.TracerouteResponder{
+					{Address: "10.0.0.1", Replies: 2},
+					{Address: "10.0.0.2", Replies: 1},
+				},
+				Probes: []models.TracerouteProbe{
+					{Address: "10.0.0.1", RTT: 10 * ms},
+					{Timeout: true},
+					{Address: "10.0.0.2", RTT: 30 * ms},
+					{Address: "10.0.0.1", RTT: 20 * ms},
+					{Timeout: true, Error: "network is unreachable"},
+				},
+				Sent: 5, Received: 3, LossPercent: 40,
+				MinRTT: 10 * ms, AvgRTT: 20 * ms, MaxRTT: 30 * ms,
+			},
+		},
+		{
+			name:    "every probe timed out",
+			replies: []traceReply{{}, {}, {}},
+			want: models.TracerouteHop{
+				HopNumber:  4,
+				Responders: []models.TracerouteResponder{},
+				Probes:     []models.TracerouteProbe{{Timeout: true}, {Timeout: true}, {Timeout: true}},
+				Sent:       3, LossPercent: 100,
+			},
+		},
+		{
+			name:    "target reached",
+			replies: []traceReply{{}, {Address: target, RTT: 12 * ms, Reached: true}},
+			want: models.TracerouteHop{
+				HopNumber:  4,
+				Address:    "192.0.2.1",
+				Responders: []models.TracerouteResponder{{Address: "192.0.2.1", Replies: 1}},
+				Probes:     []models.TracerouteProbe{{Timeout: true}, {Address: "192.0.2.1", RTT: 12 * ms}},
+				Sent:       2, Received: 1, LossPercent: 50,
+				MinRTT: 12 * ms, AvgRTT: 12 * ms, MaxRTT: 12 * ms,
+				Reached: true,
+			},
+		},
+		{
+			name: "no probes",
+			want: models.TracerouteHop{
+				HopNumber:  4,
+				Responders: []models.TracerouteResponder{},
+				Probes:     []models.TracerouteProbe{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildHop(4, tt.replies); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildHop =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// formatReplies renders each hop as "address rtt[ reached]" per probe, "*"
+// for a timeout.
+func formatReplies(replies [][]traceReply) []string {
+	hops := make([]string, len(replies))
+	for i, hop := range replies {
+		probes := make([]string, len(hop))
+		for j, reply := range hop {
+			if reply.Address == nil {
+				probes[j] = "*"
+				continue
+			}
+			probes[j] = fmt.Sprintf("%s %s", reply.Address, reply.RTT.Round(time.Microsecond))
+			if reply.Reached {
+				probes[j] += " reached"
+			}
+		}
+		hops[i] = strings.Join(probes, ", ")
+	}
+	return hops
+}
@@ -0,0 +1,148 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// DefaultMinTLSDays fails the tls check for certificates expiring sooner.
+const DefaultMinTLSDays = 7
+
+// Thresholds turn measurements into verdicts. Zero values disable a limit.
+type Thresholds struct {
+	// MaxLatency fails the http check when the response takes longer, and the
+	// ping check when the average round trip does.
+	MaxLatency time.Duration
+	// MaxLossPercent fails the ping check above this packet loss; any loss
+	// below it is a warning.
+	MaxLossPercent float64
+	// AllowedStatus lists the HTTP status codes that pass. When empty, 2xx
+	// and 3xx pass, 4xx warn and 5xx fail.
+	AllowedStatus []StatusRange
+	// MinTLSDays fails the tls check when the certificate expires sooner.
+	MinTLSDays int
+}
+
+// Evaluate rates every check that ran as pass, warn or fail. A check that
+// reported an error fails; otherwise its measurements are held against the
+// thresholds. The overall status is the worst of them.
+func Evaluate(result *models.NetworkDebugResult, t Thresholds) models.Verdict {
+	verdict := models.Verdict{Status: models.VerdictPass, Checks: []models.CheckVerdict{}}
+	failed := failedChecks(result)
+
+	for _, check := range registry {
+		if result.IsSkipped(check.Name) {
+			continue
+		}
+		cv := models.CheckVerdict{Check: check.Name, Status: models.VerdictPass, Reasons: []string{}}
+		flag := func(status, format string, args ...any) {
+			cv.Status = models.WorseVerdict(cv.Status, status)
+			cv.Reasons = append(cv.Reasons, fmt.Sprintf(format, args...))
+		}
+
+		if message := failed[check.Name]; message != "" {
+			flag(models.VerdictFail, "%s", message)
+		}
+
+		switch check.Name {
+		case "dns":
+			if len(result.DNSLookup.Records) == 0 && net.ParseIP(targetHost(result.Domain)) == nil {
+				flag(models.VerdictFail, "no DNS records found")
+			}
+		case "http":
+			evaluateHTTP(result.HTTPRequest, t, failed["http"] != "", flag)
+		case "tls":
+			evaluateTLS(result.TLS, t, flag)
+		case "ping":
+			ping := result.Ping
+			switch {
+			case ping.Sent == 0:
+			case t.MaxLossPercent > 0 && ping.LossPercent > t.MaxLossPercent:
+				flag(models.VerdictFail, "packet loss %.0f%% above %.0f%%", ping.LossPercent, t.MaxLossPercent)
+			case ping.LossPercent > 0 && ping.Received > 0:
+				flag(models.VerdictWarn, "packet loss %.0f%%", ping.LossPercent)
+			}
+			if t.MaxLatency > 0 && ping.Received > 0 && ping.AvgLatency > t.MaxLatency {
+				flag(models.VerdictFail, "average round trip %s above %s", ping.AvgLatency.Round(10*time.Microsecond), t.MaxLatency)
+			}
+		case "traceroute":
+			if len(result.Traceroute.Hops) > 0 && !result.Traceroute.Reached {
+				flag(models.VerdictWarn, "target not reached within %d hops", len(result.Traceroute.Hops))
+			}
+		case "ports":
+			for _, probe := range result.Ports.Probes {
+				if probe.State != models.PortOpen && result.Ports.Open > 0 {
+					flag(models.VerdictWarn, "%d/%s is %s", probe.Port, probe.Protocol, probe.State)
+				}
+			}
+		case "interface":
+			delta := result.Interface.Delta
+			if errs := delta.RxErrors + delta.TxErrors; errs > 0 {
+				flag(models.VerdictWarn, "%d interface errors during the sample", errs)
+			}
+			if drops := delta.RxDropped + delta.TxDropped; drops > 0 {
+				flag(models.VerdictWarn, "%d packets dropped during the sample", drops)
+			}
+		}
+
+		verdict.Status = models.WorseVerdict(verdict.Status, cv.Status)
+		verdict.Checks = append(verdict.Checks, cv)
+	}
+
+	return verdict
+}
+
+// evaluateHTTP judges the status code unless the check already failed, in
+// which case its error, such as a failed expectation, says why.
+func evaluateHTTP(httpResult models.HTTPRequestResult, t Thresholds, failed bool, flag func(status, format string, args ...any)) {
+	code := httpResult.StatusCode
+	if code == 0 {
+		return
+	}
+
+	switch {
+	case failed:
+	case len(t.AllowedStatus) > 0:
+		allowed := false
+		for _, r := range t.AllowedStatus {
+			if r.Contains(code) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			flag(models.VerdictFail, "status %d is not allowed", code)
+		}
+	case code >= 500:
+		flag(models.VerdictFail, "server error %d", code)
+	case code >= 400:
+		flag(models.VerdictWarn, "client error %d", code)
+	}
+
+	if t.MaxLatency > 0 && httpResult.Timings.Total > t.MaxLatency {
+		flag(models.VerdictFail, "response time %s above %s", httpResult.Timings.Total.Round(time.Millisecond), t.MaxLatency)
+	}
+}
+
+// evaluateTLS reuses the warnings of the tls check as reasons; an untrusted
+// chain, a hostname mismatch or an expiry sooner than MinTLSDays fails it.
+func evaluateTLS(tlsResult models.TLSResult, t Thresholds, flag func(status, format string, args ...any)) {
+	if len(tlsResult.Chain) == 0 {
+		return
+	}
+
+	days := tlsResult.Chain[0].DaysToExpiry
+	status := models.VerdictWarn
+	if !tlsResult.ChainVerified || !tlsResult.HostnameVerified || days < 0 {
+		status = models.VerdictFail
+	}
+	for _, warning := range tlsResult.Warnings {
+		flag(status, "%s", warning)
+	}
+	if days >= 0 && t.MinTLSDays > 0 && days < t.MinTLSDays {
+		flag(models.VerdictFail, "certificate expires in %d days, less than the required %d", days, t.MinTLSDays)
+	}
+}
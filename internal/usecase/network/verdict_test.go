@@ -0,0 +1,264 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// onlyCheck marks every check but name as skipped, so Evaluate rates name alone.
+func onlyCheck(name string, result models.NetworkDebugResult) *models.NetworkDebugResult {
+	result.Skipped = nil
+	for _, check := range registry {
+		if check.Name != name {
+			result.Skipped = append(result.Skipped, check.Name)
+		}
+	}
+	return &result
+}
+
+func TestEvaluate(t *testing.T) {
+	aRecord := models.DNSLookupResult{Records: []models.DNSRecord{{Name: "example.com", Type: "A", Value: "93.184.216.34"}}}
+	cert := func(days int) []models.TLSCertificate {
+		return []models.TLSCertificate{{Subject: "CN=example.com", DaysToExpiry: days}}
+	}
+	timeout := []models.ToolError{{Tool: "http", Message: "request timed out"}}
+
+	tests := []struct {
+		name       string
+		check      string
+		result     models.NetworkDebugResult
+		thresholds Thresholds
+		status     string
+		reasons    []string
+	}{
+		{name: "dns records", check: "dns", result: models.NetworkDebugResult{Domain: "example.com", DNSLookup: aRecord}, status: models.VerdictPass},
+		{name: "dns without records", check: "dns", result: models.NetworkDebugResult{Domain: "example.com"}, status: models.VerdictFail, reasons: []string{"no DNS records found"}},
+		{name: "dns of an IP target", check: "dns", result: models.NetworkDebugResult{Domain: "10.0.0.1:8080"}, status: models.VerdictPass},
+		{
+			name:    "dns error",
+			check:   "dns",
+			result:  models.NetworkDebugResult{Domain: "example.com", Errors: []models.ToolError{{Tool: "dns", Message: "NXDOMAIN"}}},
+			status:  models.VerdictFail,
+			reasons: []string{"NXDOMAIN", "no DNS records found"},
+		},
+
+		{name: "http ok", check: "http", result: models.NetworkDebugResult{HTTPRequest: models.HTTPRequestResult{StatusCode: 200}}, status: models.VerdictPass},
+		{name: "http redirect", check: "http", result: models.NetworkDebugResult{HTTPRequest: models.HTTPRequestResult{StatusCode: 301}}, status: models.VerdictPass},
+		{name: "http client error", check: "http", result: models.NetworkDebugResult{HTTPRequest: models.HTTPRequestResult{StatusCode: 404}}, status: models.VerdictWarn, reasons: []string{"client error 404"}},
+		{name: "http server error", check: "http", result: models.NetworkDebugResult{HTTPRequest: models.HTTPRequestResult{StatusCode: 503}}, status: models.VerdictFail, reasons: []string{"server error 503"}},
+		{
+			name:       "http allowed status",
+			check:      "http",
+			result:     models.NetworkDebugResult{HTTPRequest: models.HTTPRequestResult{StatusCode: 404}},
+			thresholds: Thresholds{AllowedStatus: []StatusRange{{Min: 200, Max: 299}, {Min: 404, Max: 404}}},
+			status:     models.VerdictPass,
+		},
+		{
+			name:       "http status not allowed",
+			check:      "http",
+			result:     models.NetworkDebugResult{HTTPRequest: models.HTTPRequestResult{StatusCode: 302}},
+			thresholds: Thresholds{AllowedStatus: []StatusRange{{Min: 200, Max: 299}}},
+			status:     models.VerdictFail,
+			reasons:    []string{"status 302 is not allowed"},
+		},
+		{
+			name:       "http slow",
+			check:      "http",
+			result:     models.NetworkDebugResult{HTTPRequest: models.HTTPRequestResult{StatusCode: 200, Timings: models.HTTPTimings{Total: 1500 * time.Millisecond}}},
+			thresholds: Thresholds{MaxLatency: time.Second},
+			status:     models.VerdictFail,
+			reasons:    []string{"response time 1.5s above 1s"},
+		},
+		{
+			name:    "http error keeps its own reason",
+			check:   "http",
+			result:  models.NetworkDebugResult{HTTPRequest: models.HTTPRequestResult{StatusCode: 500}, Errors: []models.ToolError{{Tool: "http", Message: "expected status 200, got 500"}}},
+			status:  models.VerdictFail,
+			reasons: []string{"expected status 200, got 500"},
+		},
+		{name: "http without response", check: "http", result: models.NetworkDebugResult{Errors: timeout}, status: models.VerdictFail, reasons: []string{"request timed out"}},
+
+		{
+			name:   "tls valid",
+			check:  "tls",
+			result: models.NetworkDebugResult{TLS: models.TLSResult{Chain: cert(90), ChainVerified: true, HostnameVerified: true}},
+			status: models.VerdictPass,
+		},
+		{
+			name:    "tls expiring soon",
+			check:   "tls",
+			result:  models.NetworkDebugResult{TLS: models.TLSResult{Chain: cert(20), ChainVerified: true, HostnameVerified: true, Warnings: []string{"certificate expires in 20 days"}}},
+			status:  models.VerdictWarn,
+			reasons: []string{"certificate expires in 20 days"},
+		},
+		{
+			name:       "tls below the required days",
+			check:      "tls",
+			result:     models.NetworkDebugResult{TLS: models.TLSResult{Chain: cert(5), ChainVerified: true, HostnameVerified: true, Warnings: []string{"certificate expires in 5 days"}}},
+			thresholds: Thresholds{MinTLSDays: 7},
+			status:     models.VerdictFail,
+			reasons:    []string{"certificate expires in 5 days", "certificate expires in 5 days, less than the required 7"},
+		},
+		{
+			name:    "tls expired",
+			check:   "tls",
+			result:  models.NetworkDebugResult{TLS: models.TLSResult{Chain: cert(-2), ChainVerified: true, HostnameVerified: true, Warnings: []string{"certificate expired 2 days ago"}}},
+			status:  models.VerdictFail,
+			reasons: []string{"certificate expired 2 days ago"},
+		},
+		{
+			name:    "tls untrusted",
+			check:   "tls",
+			result:  models.NetworkDebugResult{TLS: models.TLSResult{Chain: cert(90), HostnameVerified: true, Warnings: []string{"certificate signed by unknown authority"}}},
+			status:  models.VerdictFail,
+			reasons: []string{"certificate signed by unknown authority"},
+		},
+		{
+			name:    "tls hostname mismatch",
+			check:   "tls",
+			result:  models.NetworkDebugResult{TLS: models.TLSResult{Chain: cert(90), ChainVerified: true, Warnings: []string{"certificate is not valid for example.org"}}},
+			status:  models.VerdictFail,
+			reasons: []string{"certificate is not valid for example.org"},
+		},
+
+		{name: "ping clean", check: "ping", result: models.NetworkDebugResult{Ping: models.PingResult{Sent: 4, Received: 4, AvgLatency: 20 * time.Millisecond}}, status: models.VerdictPass},
+		{
+			name:    "ping some loss",
+			check:   "ping",
+			result:  models.NetworkDebugResult{Ping: models.PingResult{Sent: 4, Received: 3, LossPercent: 25}},
+			status:  models.VerdictWarn,
+			reasons: []string{"packet loss 25%"},
+		},
+		{
+			name:       "ping loss above the limit",
+			check:      "ping",
+			result:     models.NetworkDebugResult{Ping: models.PingResult{Sent: 4, Received: 2, LossPercent: 50}},
+			thresholds: Thresholds{MaxLossPercent: 30},
+			status:     models.VerdictFail,
+			reasons:    []string{"packet loss 50% above 30%"},
+		},
+		{
+			name:       "ping loss within the limit",
+			check:      "ping",
+			result:     models.NetworkDebugResult{Ping: models.PingResult{Sent: 4, Received: 3, LossPercent: 25}},
+			thresholds: Thresholds{MaxLossPercent: 30},
+			status:     models.VerdictWarn,
+			reasons:    []string{"packet loss 25%"},
+		},
+		{
+			name:       "ping slow",
+			check:      "ping",
+			result:     models.NetworkDebugResult{Ping: models.PingResult{Sent: 4, Received: 4, AvgLatency: 123456 * time.Microsecond}},
+			thresholds: Thresholds{MaxLatency: 100 * time.Millisecond},
+			status:     models.VerdictFail,
+			reasons:    []string{"average round trip 123.46ms above 100ms"},
+		},
+		{
+			name:    "ping nothing received",
+			check:   "ping",
+			result:  models.NetworkDebugResult{Ping: models.PingResult{Sent: 4, LossPercent: 100}, Errors: []models.ToolError{{Tool: "ping", Message: "no replies"}}},
+			status:  models.VerdictFail,
+			reasons: []string{"no replies"},
+		},
+		{name: "ping not run", check: "ping", status: models.VerdictPass},
+
+		{
+			name:   "traceroute reached",
+			check:  "traceroute",
+			result: models.NetworkDebugResult{Traceroute: models.TracerouteResult{Reached: true, Hops: make([]models.TracerouteHop, 5)}},
+			status: models.VerdictPass,
+		},
+		{
+			name:    "traceroute not reached",
+			check:   "traceroute",
+			result:  models.NetworkDebugResult{Traceroute: models.TracerouteResult{Hops: make([]models.TracerouteHop, 30)}},
+			status:  models.VerdictWarn,
+			reasons: []string{"target not reached within 30 hops"},
+		},
+
+		{
+			name:  "ports partly open",
+			check: "ports",
+			result: models.NetworkDebugResult{Ports: models.PortsResult{Open: 1, Probes: []models.PortProbe{
+				{Port: 80, Protocol: "tcp", State: models.PortOpen},
+				{Port: 443, Protocol: "tcp", State: models.PortFiltered},
+			}}},
+			status:  models.VerdictWarn,
+			reasons: []string{"443/tcp is filtered"},
+		},
+		{
+			name:  "ports none open",
+			check: "ports",
+			result: models.NetworkDebugResult{
+				Ports:  models.PortsResult{Probes: []models.PortProbe{{Port: 80, Protocol: "tcp", State: models.PortRefused}}},
+				Errors: []models.ToolError{{Tool: "ports", Message: "none of the 1 probed ports is open on 10.0.0.1"}},
+			},
+			status:  models.VerdictFail,
+			reasons: []string{"none of the 1 probed ports is open on 10.0.0.1"},
+		},
+
+		{name: "interface clean", check: "interface", result: models.NetworkDebugResult{Interface: models.InterfaceResult{Delta: models.InterfaceCounters{RxBytes: 1000}}}, status: models.VerdictPass},
+		{
+			name:    "interface errors and drops",
+			check:   "interface",
+			result:  models.NetworkDebugResult{Interface: models.InterfaceResult{Delta: models.InterfaceCounters{RxErrors: 1, TxErrors: 2, TxDropped: 4}}},
+			status:  models.VerdictWarn,
+			reasons: []string{"3 interface errors during the sample", "4 packets dropped during the sample"},
+		},
+
+		{name: "netstat error", check: "netstat", result: models.NetworkDebugResult{Errors: []models.ToolError{{Tool: "netstat", Message: "permission denied"}}}, status: models.VerdictFail, reasons: []string{"permission denied"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := Evaluate(onlyCheck(tt.check, tt.result), tt.thresholds)
+			if len(verdict.Checks) != 1 || verdict.Checks[0].Check != tt.check {
+				t.Fatalf("checks = %+v, want only %s", verdict.Checks, tt.check)
+			}
+			reasons := tt.reasons
+			if reasons == nil {
+				reasons = []string{}
+			}
+			got := verdict.Checks[0]
+			if got.Status != tt.status || !reflect.DeepEqual(got.Reasons, reasons) {
+				t.Errorf("%s = %s %q, want %s %q", tt.check, got.Status, got.Reasons, tt.status, reasons)
+			}
+			if verdict.Status != tt.status {
+				t.Errorf("overall status = %s, want %s", verdict.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestEvaluateWorstCheckWins(t *testing.T) {
+	result := &models.NetworkDebugResult{
+		Domain:      "example.com",
+		DNSLookup:   models.DNSLookupResult{Records: []models.DNSRecord{{Type: "A", Value: "93.184.216.34"}}},
+		HTTPRequest: models.HTTPRequestResult{StatusCode: 404},
+		Ping:        models.PingResult{Sent: 4, Received: 4},
+		Skipped:     []string{"nslookup", "traceroute", "tls", "ports", "netstat", "interface"},
+	}
+
+	verdict := Evaluate(result, Thresholds{})
+	var got []string
+	for _, check := range verdict.Checks {
+		got = append(got, check.Check+" "+check.Status)
+	}
+	want := []string{"dns pass", "http warn", "ping pass"}
+	if !reflect.DeepEqual(got, want) || verdict.Status != models.VerdictWarn {
+		t.Errorf("verdict = %s %q, want warn %q", verdict.Status, got, want)
+	}
+
+	result.Errors = []models.ToolError{{Tool: "ping", Message: "host unreachable"}}
+	if verdict := Evaluate(result, Thresholds{}); verdict.Status != models.VerdictFail {
+		t.Errorf("status = %s, want fail once a check errors", verdict.Status)
+	}
+
+	result.Skipped = CheckNames()
+	if verdict := Evaluate(result, Thresholds{}); verdict.Status != models.VerdictPass || len(verdict.Checks) != 0 {
+		t.Errorf("verdict of a run without checks = %+v, want an empty pass", verdict)
+	}
+}
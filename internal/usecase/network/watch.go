@@ -0,0 +1,205 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+const (
+	DefaultWatchInterval = 30 * time.Second
+	// DefaultLatencyThreshold is how much slower a run may be before it is
+	// reported as a latency regression.
+	DefaultLatencyThreshold = 50 * time.Millisecond
+)
+
+// WatchOptions configures WatchNetworkDebug.
+type WatchOptions struct {
+	// Interval between the starts of two runs.
+	Interval time.Duration
+	// LatencyThreshold is the increase in HTTP or ping latency reported as a regression.
+	LatencyThreshold time.Duration
+}
+
+// WatchNetworkDebug runs NetworkDebug every interval until ctx is cancelled and
+// calls update after each run with what changed since the previous one. A run
+// interrupted by the cancellation is discarded. The summary counts the runs
+// each check failed.
+func (u *NetworkDebugUsecase) WatchNetworkDebug(ctx context.Context, domain string, opts DebugOptions, watch WatchOptions, update func(models.DebugWatchRun)) *models.DebugWatchSummary {
+	if watch.Interval <= 0 {
+		watch.Interval = DefaultWatchInterval
+	}
+	if watch.LatencyThreshold <= 0 {
+		watch.LatencyThreshold = DefaultLatencyThreshold
+	}
+
+	summary := &models.DebugWatchSummary{
+		Domain:    domain,
+		StartedAt: time.Now().UTC(),
+		Failures:  make(map[string]int),
+	}
+
+	var previous *models.NetworkDebugResult
+	for run := 1; ; run++ {
+		start := time.Now()
+		result, _ := u.NetworkDebug(ctx, domain, opts)
+		if ctx.Err() != nil {
+			break
+		}
+
+		summary.Runs = run
+		for _, toolErr := range result.Errors {
+			summary.Failures[toolErr.Tool]++
+		}
+
+		changes := []models.DebugChange{}
+		if previous != nil {
+			changes = CompareDebugResults(previous, result, watch.LatencyThreshold)
+		}
+		if update != nil {
+			update(models.DebugWatchRun{Run: run, Result: result, Changes: changes})
+		}
+		previous = result
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Until(start.Add(watch.Interval))):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	summary.EndedAt = time.Now().UTC()
+	return summary
+}
+
+// CompareDebugResults lists what changed between two runs: checks that
+// started or stopped failing, new or removed DNS records, HTTP status
+// changes, latency regressions beyond threshold, new traceroute hops and
+// ports changing state. Checks skipped in either run are not compared.
+func CompareDebugResults(previous, current *models.NetworkDebugResult, threshold time.Duration) []models.DebugChange {
+	changes := []models.DebugChange{}
+	add := func(check, format string, args ...any) {
+		changes = append(changes, models.DebugChange{Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+	compared := func(check string) bool {
+		return !previous.IsSkipped(check) && !current.IsSkipped(check)
+	}
+
+	failedBefore := failedChecks(previous)
+	failedNow := failedChecks(current)
+	for _, check := range CheckNames() {
+		switch {
+		case failedNow[check] != "" && failedBefore[check] == "":
+			add(check, "started failing: %s", failedNow[check])
+		case failedNow[check] == "" && failedBefore[check] != "" && !current.IsSkipped(check):
+			add(check, "recovered")
+		}
+	}
+
+	if compared("dns") {
+		before := dnsRecordSet(previous.DNSLookup.Records)
+		now := dnsRecordSet(current.DNSLookup.Records)
+		for _, record := range sortedSetKeys(now) {
+			if !before[record] {
+				add("dns", "new record %s", record)
+			}
+		}
+		for _, record := range sortedSetKeys(before) {
+			if !now[record] {
+				add("dns", "record %s is gone", record)
+			}
+		}
+	}
+
+	if compared("http") {
+		before, now := previous.HTTPRequest, current.HTTPRequest
+		if before.StatusCode != 0 && now.StatusCode != 0 && before.StatusCode != now.StatusCode {
+			add("http", "status changed from %d to %d", before.StatusCode, now.StatusCode)
+		}
+		if before.StatusCode != 0 && now.StatusCode != 0 {
+			if regression := now.Timings.Total - before.Timings.Total; regression > threshold {
+				add("http", "response time rose by %s to %s", regression.Round(time.Millisecond), now.Timings.Total.Round(time.Millisecond))
+			}
+		}
+	}
+
+	if compared("ping") {
+		before, now := previous.Ping, current.Ping
+		if before.Received > 0 && now.Received > 0 {
+			if regression := now.AvgLatency - before.AvgLatency; regression > threshold {
+				add("ping", "average round trip rose by %s to %s", regression.Round(10*time.Microsecond), now.AvgLatency.Round(10*time.Microsecond))
+			}
+		}
+		if before.Sent > 0 && now.Sent > 0 && now.LossPercent > before.LossPercent {
+			add("ping", "packet loss rose from %.0f%% to %.0f%%", before.LossPercent, now.LossPercent)
+		}
+	}
+
+	if compared("traceroute") {
+		seen := make(map[int]map[string]bool)
+		for _, hop := range previous.Traceroute.Hops {
+			seen[hop.HopNumber] = make(map[string]bool)
+			for _, responder := range hop.Responders {
+				seen[hop.HopNumber][responder.Address] = true
+			}
+		}
+		// A path that was not traced before has nothing to compare with.
+		if len(seen) > 0 {
+			for _, hop := range current.Traceroute.Hops {
+				for _, responder := range hop.Responders {
+					if !seen[hop.HopNumber][responder.Address] {
+						add("traceroute", "new hop %d: %s", hop.HopNumber, responder.Address)
+					}
+				}
+			}
+		}
+	}
+
+	if compared("ports") {
+		before := make(map[string]string)
+		for _, probe := range previous.Ports.Probes {
+			before[fmt.Sprintf("%d/%s", probe.Port, probe.Protocol)] = probe.State
+		}
+		for _, probe := range current.Ports.Probes {
+			port := fmt.Sprintf("%d/%s", probe.Port, probe.Protocol)
+			if state, ok := before[port]; ok && state != probe.State {
+				add("ports", "%s changed from %s to %s", port, state, probe.State)
+			}
+		}
+	}
+
+	return changes
+}
+
+// failedChecks maps each failed check of a run to its error message.
+func failedChecks(result *models.NetworkDebugResult) map[string]string {
+	failed := make(map[string]string)
+	for _, toolErr := range result.Errors {
+		failed[toolErr.Tool] = toolErr.Message
+	}
+	return failed
+}
+
+// dnsRecordSet identifies records by type and value; TTLs count down between
+// runs, so they are left out.
+func dnsRecordSet(records []models.DNSRecord) map[string]bool {
+	set := make(map[string]bool)
+	for _, record := range records {
+		set[fmt.Sprintf("%s %s %s", record.Name, record.Type, record.Value)] = true
+	}
+	return set
+}
+
+func sortedSetKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,154 @@
+package network
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+func TestCompareDebugResults(t *testing.T) {
+	tests := []struct {
+		name      string
+		before    func(*models.NetworkDebugResult)
+		after     func(*models.NetworkDebugResult)
+		threshold time.Duration
+		want      []string
+	}{
+		{name: "unchanged"},
+		{
+			name: "started failing",
+			after: func(r *models.NetworkDebugResult) {
+				r.Errors = []models.ToolError{{Tool: "http", Message: "request timed out"}}
+			},
+			want: []string{"http: started failing: request timed out"},
+		},
+		{
+			name: "recovered",
+			before: func(r *models.NetworkDebugResult) {
+				r.Errors = []models.ToolError{{Tool: "ping", Message: "no replies"}}
+			},
+			want: []string{"ping: recovered"},
+		},
+		{
+			name: "failed check skipped afterwards has not recovered",
+			before: func(r *models.NetworkDebugResult) {
+				r.Errors = []models.ToolError{{Tool: "ping", Message: "no replies"}}
+			},
+			after: func(r *models.NetworkDebugResult) { r.Skipped = []string{"ping"} },
+		},
+		{
+			name: "dns records",
+			after: func(r *models.NetworkDebugResult) {
+				r.DNSLookup.Records[0].TTL = 10
+				r.DNSLookup.Records = append(r.DNSLookup.Records, models.DNSRecord{Name: "example.com.", Type: "AAAA", Value: "2606:2800:220:1:248:1893:25c8:1946"})
+			},
+			want: []string{"dns: new record example.com. AAAA 2606:2800:220:1:248:1893:25c8:1946"},
+		},
+		{
+			name:  "dns record gone",
+			after: func(r *models.NetworkDebugResult) { r.DNSLookup.Records = nil },
+			want:  []string{"dns: record example.com. A 93.184.216.34 is gone"},
+		},
+		{
+			name:   "dns skipped before",
+			before: func(r *models.NetworkDebugResult) { r.Skipped = []string{"dns"}; r.DNSLookup.Records = nil },
+		},
+		{
+			name:  "http status",
+			after: func(r *models.NetworkDebugResult) { r.HTTPRequest.StatusCode = 502 },
+			want:  []string{"http: status changed from 200 to 502"},
+		},
+		{
+			name:  "http latency above the threshold",
+			after: func(r *models.NetworkDebugResult) { r.HTTPRequest.Timings.Total = 180 * time.Millisecond },
+			want:  []string{"http: response time rose by 60ms to 180ms"},
+		},
+		{
+			name:  "http latency within the threshold",
+			after: func(r *models.NetworkDebugResult) { r.HTTPRequest.Timings.Total = 170 * time.Millisecond },
+		},
+		{
+			name:      "custom threshold",
+			after:     func(r *models.NetworkDebugResult) { r.HTTPRequest.Timings.Total = 170 * time.Millisecond },
+			threshold: 20 * time.Millisecond,
+			want:      []string{"http: response time rose by 50ms to 170ms"},
+		},
+		{
+			name:  "http without a response",
+			after: func(r *models.NetworkDebugResult) { r.HTTPRequest = models.HTTPRequestResult{} },
+		},
+		{
+			name: "ping latency and loss",
+			after: func(r *models.NetworkDebugResult) {
+				r.Ping.AvgLatency = 95 * time.Millisecond
+				r.Ping.Received, r.Ping.LossPercent = 3, 25
+			},
+			want: []string{
+				"ping: average round trip rose by 75ms to 95ms",
+				"ping: packet loss rose from 0% to 25%",
+			},
+		},
+		{
+			name:   "ping latency improved",
+			before: func(r *models.NetworkDebugResult) { r.Ping.AvgLatency = 200 * time.Millisecond },
+		},
+		{
+			name: "new traceroute hops",
+			after: func(r *models.NetworkDebugResult) {
+				r.Traceroute.Hops[1].Responders = append(r.Traceroute.Hops[1].Responders, models.TracerouteResponder{Address: "100.64.0.9"})
+				r.Traceroute.Hops = append(r.Traceroute.Hops, models.TracerouteHop{HopNumber: 4, Responders: []models.TracerouteResponder{{Address: "93.184.216.34"}}})
+			},
+			want: []string{"traceroute: new hop 2: 100.64.0.9", "traceroute: new hop 4: 93.184.216.34"},
+		},
+		{
+			name:   "traceroute not traced before",
+			before: func(r *models.NetworkDebugResult) { r.Traceroute.Hops = nil },
+		},
+		{
+			name: "traceroute skipped now",
+			after: func(r *models.NetworkDebugResult) {
+				r.Skipped = []string{"traceroute"}
+				r.Traceroute.Hops[0].Responders[0].Address = "10.0.0.1"
+			},
+		},
+		{
+			name: "port states",
+			after: func(r *models.NetworkDebugResult) {
+				r.Ports.Probes[0].State = models.PortRefused
+				r.Ports.Probes = append(r.Ports.Probes, models.PortProbe{Port: 22, Protocol: "tcp", State: models.PortOpen})
+			},
+			want: []string{"ports: 80/tcp changed from open to refused"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous, current := debugSnapshot(), debugSnapshot()
+			if tt.before != nil {
+				tt.before(previous)
+			}
+			if tt.after != nil {
+				tt.after(current)
+			}
+			threshold := tt.threshold
+			if threshold == 0 {
+				threshold = DefaultLatencyThreshold
+			}
+
+			got := []string{}
+			for _, change := range CompareDebugResults(previous, current, threshold) {
+				got = append(got, change.Check+": "+change.Message)
+			}
+			want := tt.want
+			if want == nil {
+				want = []string{}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("changes =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+			}
+		})
+	}
+}
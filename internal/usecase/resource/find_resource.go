@@ -0,0 +1,61 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"github.com/iagonc/jorge-cli/cmd/cli/pkg/client"
+)
+
+// ErrAmbiguousResource is returned by FindResource when the name or DNS
+// matches more than one resource.
+var ErrAmbiguousResource = errors.New("ambiguous resource")
+
+// FindResource returns the resource ref refers to: the one with that ID when
+// ref is a number, else the one named exactly ref, else the one whose DNS is
+// exactly ref. A number that is no ID is also tried as a name and DNS.
+func (s *ResourceUsecase) FindResource(ctx context.Context, ref string) (*models.Resource, error) {
+    if id, err := strconv.Atoi(ref); err == nil && id > 0 {
+        resource, err := s.GetResourceByID(ctx, id)
+        if !errors.Is(err, client.ErrNotFound) {
+            return resource, err
+        }
+    }
+
+    resources, err := s.ListResources(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    matches := matching(resources, func(r models.Resource) bool { return r.Name == ref })
+    if len(matches) == 0 {
+        matches = matching(resources, func(r models.Resource) bool { return r.Dns == ref })
+    }
+
+    switch len(matches) {
+    case 0:
+        return nil, fmt.Errorf("resource '%s' %w", ref, client.ErrNotFound)
+    case 1:
+        return &matches[0], nil
+    }
+
+    ids := make([]string, 0, len(matches))
+    for _, resource := range matches {
+        ids = append(ids, strconv.Itoa(resource.ID))
+    }
+    return nil, fmt.Errorf("%w: '%s' matches the resources with IDs %s; use an ID", ErrAmbiguousResource, ref, strings.Join(ids, ", "))
+}
+
+func matching(resources []models.Resource, match func(models.Resource) bool) []models.Resource {
+    var matches []models.Resource
+    for _, resource := range resources {
+        if match(resource) {
+            matches = append(matches, resource)
+        }
+    }
+    return matches
+}
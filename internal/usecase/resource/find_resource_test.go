@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"github.com/iagonc/jorge-cli/cmd/cli/pkg/client"
+)
+
+func TestFindResource(t *testing.T) {
+	resources := []models.Resource{
+		{ID: 1, Name: "api", Dns: "api.example.com"},
+		{ID: 2, Name: "web", Dns: "web.example.com"},
+		{ID: 3, Name: "2024", Dns: "archive.example.com"},
+		{ID: 4, Name: "web.example.com", Dns: "legacy.example.com"},
+		{ID: 5, Name: "db", Dns: "db.example.com"},
+		{ID: 6, Name: "db", Dns: "db-replica.example.com"},
+		{ID: 7, Name: "cache", Dns: "shared.example.com"},
+		{ID: 8, Name: "queue", Dns: "shared.example.com"},
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		wantID  int
+		calls   string
+		wantErr string
+	}{
+		{name: "ID", ref: "2", wantID: 2, calls: "get"},
+		{name: "number that is no ID", ref: "2024", wantID: 3, calls: "get,list"},
+		{name: "name", ref: "api", wantID: 1, calls: "list"},
+		{name: "DNS", ref: "api.example.com", wantID: 1, calls: "list"},
+		{name: "name before DNS", ref: "web.example.com", wantID: 4, calls: "list"},
+		{name: "ambiguous name", ref: "db", calls: "list", wantErr: "ambiguous resource: 'db' matches the resources with IDs 5, 6; use an ID"},
+		{name: "ambiguous DNS", ref: "shared.example.com", calls: "list", wantErr: "ambiguous resource: 'shared.example.com' matches the resources with IDs 7, 8; use an ID"},
+		{name: "not found", ref: "mail", calls: "list", wantErr: "resource 'mail' not found"},
+		{name: "missing ID", ref: "99", calls: "get,list", wantErr: "resource '99' not found"},
+		{name: "name is case sensitive", ref: "API", calls: "list", wantErr: "resource 'API' not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeAPI{resources: resources}
+			u := NewResourceUsecase(api, zap.NewNop())
+
+			resource, err := u.FindResource(context.Background(), tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				if resource != nil {
+					t.Errorf("resource = %+v, want none", resource)
+				}
+			} else if err != nil || resource == nil || resource.ID != tt.wantID {
+				t.Errorf("FindResource(%q) = %+v, %v; want ID %d", tt.ref, resource, err, tt.wantID)
+			}
+			if got := strings.Join(api.calls, ","); got != tt.calls {
+				t.Errorf("API calls = %s, want %s", got, tt.calls)
+			}
+		})
+	}
+}
+
+func TestFindResourceErrors(t *testing.T) {
+	api := &fakeAPI{resources: []models.Resource{{ID: 1, Name: "db"}, {ID: 2, Name: "db"}}}
+	u := NewResourceUsecase(api, zap.NewNop())
+	ctx := context.Background()
+
+	if _, err := u.FindResource(ctx, "db"); !errors.Is(err, ErrAmbiguousResource) {
+		t.Errorf("errors.Is(%v, ErrAmbiguousResource) = false", err)
+	}
+	if _, err := u.FindResource(ctx, "mail"); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("errors.Is(%v, ErrNotFound) = false", err)
+	}
+
+	// Errors other than a missing ID are not hidden by the name lookup.
+	api.err = &client.APIError{StatusCode: http.StatusUnauthorized}
+	for _, ref := range []string{"1", "db"} {
+		if _, err := u.FindResource(ctx, ref); !errors.Is(err, client.ErrUnauthorized) {
+			t.Errorf("FindResource(%q) = %v, want ErrUnauthorized", ref, err)
+		}
+	}
+	if strings.Join(api.calls, ",") != "list,list,get,list" {
+		t.Errorf("API calls = %q", api.calls)
+	}
+}
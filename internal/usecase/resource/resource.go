@@ -1,22 +1,73 @@
 package resource
 
 import (
-	"github.com/iagonc/jorge-cli/cmd/cli/internal/config"
-	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/pkg/client"
 
 	"go.uber.org/zap"
 )
 
+// ResourceAPI is the part of the API client the usecase calls; *client.Client
+// implements it.
+type ResourceAPI interface {
+    ListResources(ctx context.Context) ([]client.Resource, error)
+    GetResource(ctx context.Context, id int) (*client.Resource, error)
+    CreateResource(ctx context.Context, req client.CreateRequest) (*client.Resource, error)
+    UpdateResource(ctx context.Context, id int, req client.UpdateRequest) (*client.Resource, error)
+    DeleteResource(ctx context.Context, id int) (*client.Resource, error)
+}
+
+var _ ResourceAPI = (*client.Client)(nil)
+
 type ResourceUsecase struct {
-    Client utils.HTTPClient
-    Config *config.Config
-    Logger *zap.Logger
+    API ResourceAPI
+    // Connect builds API on first use when it is nil, so commands that call
+    // the API only sometimes do not load the config and credentials otherwise.
+    Connect func() (ResourceAPI, error)
+    Logger  *zap.Logger
+
+    mu sync.Mutex
 }
 
-func NewResourceUsecase(client utils.HTTPClient, cfg *config.Config, logger *zap.Logger) *ResourceUsecase {
+func NewResourceUsecase(api ResourceAPI, logger *zap.Logger) *ResourceUsecase {
     return &ResourceUsecase{
-        Client: client,
-        Config: cfg,
+        API:    api,
         Logger: logger,
     }
 }
+
+// EnsureAPI builds the API client now, so a command fails on bad settings or
+// credentials before it does anything else.
+func (s *ResourceUsecase) EnsureAPI() error {
+    _, err := s.api()
+    return err
+}
+
+func (s *ResourceUsecase) api() (ResourceAPI, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.API != nil {
+        return s.API, nil
+    }
+    if s.Connect == nil {
+        return nil, fmt.Errorf("no API client configured")
+    }
+    api, err := s.Connect()
+    if err != nil {
+        return nil, err
+    }
+    s.API = api
+    return api, nil
+}
+
+// notFound reports a missing resource by ID; errors.Is still matches client.ErrNotFound.
+func notFound(id int, err error) error {
+    if errors.Is(err, client.ErrNotFound) {
+        return fmt.Errorf("resource with ID %d %w", id, client.ErrNotFound)
+    }
+    return err
+}
@@ -0,0 +1,146 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+	"github.com/iagonc/jorge-cli/cmd/cli/pkg/client"
+)
+
+// fakeAPI serves resources from memory and answers a missing ID the way the
+// API does, with a 404 *client.APIError. err, when set, fails every call.
+type fakeAPI struct {
+	resources []models.Resource
+	err       error
+	calls     []string
+}
+
+func (f *fakeAPI) ListResources(ctx context.Context) ([]client.Resource, error) {
+	f.calls = append(f.calls, "list")
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.resources, nil
+}
+
+func (f *fakeAPI) GetResource(ctx context.Context, id int) (*client.Resource, error) {
+	f.calls = append(f.calls, "get")
+	return f.find(id)
+}
+
+func (f *fakeAPI) CreateResource(ctx context.Context, req client.CreateRequest) (*client.Resource, error) {
+	f.calls = append(f.calls, "create")
+	if f.err != nil {
+		return nil, f.err
+	}
+	resource := models.Resource{ID: len(f.resources) + 1, Name: req.Name, Dns: req.Dns}
+	f.resources = append(f.resources, resource)
+	return &resource, nil
+}
+
+func (f *fakeAPI) UpdateResource(ctx context.Context, id int, req client.UpdateRequest) (*client.Resource, error) {
+	f.calls = append(f.calls, "update")
+	return f.find(id)
+}
+
+func (f *fakeAPI) DeleteResource(ctx context.Context, id int) (*client.Resource, error) {
+	f.calls = append(f.calls, "delete")
+	return f.find(id)
+}
+
+func (f *fakeAPI) find(id int) (*client.Resource, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	for i := range f.resources {
+		if f.resources[i].ID == id {
+			return &f.resources[i], nil
+		}
+	}
+	return nil, &client.APIError{StatusCode: http.StatusNotFound, Code: "not_found", Message: "resource not found"}
+}
+
+func TestUsecaseKeepsTypedErrors(t *testing.T) {
+	api := &fakeAPI{resources: []models.Resource{{ID: 1, Name: "api", Dns: "api.example.com"}}}
+	u := NewResourceUsecase(api, zap.NewNop())
+	ctx := context.Background()
+
+	calls := map[string]func() error{
+		"get":    func() error { _, err := u.GetResourceByID(ctx, 9); return err },
+		"update": func() error { _, err := u.UpdateResource(ctx, 9, "web", ""); return err },
+		"delete": func() error { _, err := u.DeleteResource(ctx, 9); return err },
+	}
+	for name, call := range calls {
+		err := call()
+		if !errors.Is(err, client.ErrNotFound) {
+			t.Errorf("%s: errors.Is(%v, ErrNotFound) = false", name, err)
+		}
+		if want := "resource with ID 9 not found"; err == nil || err.Error() != want {
+			t.Errorf("%s: error = %v, want %q", name, err, want)
+		}
+	}
+
+	for status, want := range map[int]error{
+		http.StatusConflict:     client.ErrConflict,
+		http.StatusBadRequest:   client.ErrValidation,
+		http.StatusUnauthorized: client.ErrUnauthorized,
+	} {
+		api.err = &client.APIError{StatusCode: status}
+		if _, err := u.CreateResource(ctx, "api", "api.example.com"); !errors.Is(err, want) {
+			t.Errorf("create with status %d: errors.Is(%v, %v) = false", status, err, want)
+		}
+		// Only a missing resource is reported by ID.
+		if _, err := u.GetResourceByID(ctx, 1); !errors.Is(err, want) || errors.Is(err, client.ErrNotFound) || err != api.err {
+			t.Errorf("get with status %d = %v, want the API error unchanged", status, err)
+		}
+	}
+}
+
+func TestUpdateResourceNeedsAField(t *testing.T) {
+	api := &fakeAPI{resources: []models.Resource{{ID: 1, Name: "api"}}}
+	u := NewResourceUsecase(api, zap.NewNop())
+
+	if _, err := u.UpdateResource(context.Background(), 1, "", ""); err == nil {
+		t.Fatal("expected an error without name and dns")
+	}
+	if len(api.calls) != 0 {
+		t.Errorf("API calls = %q, want none", api.calls)
+	}
+}
+
+func TestUsecaseConnectsOnFirstCall(t *testing.T) {
+	api := &fakeAPI{resources: []models.Resource{{ID: 1, Name: "api"}}}
+	connects := 0
+	u := NewResourceUsecase(nil, zap.NewNop())
+	u.Connect = func() (ResourceAPI, error) {
+		connects++
+		return api, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := u.ListResources(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if connects != 1 || len(api.calls) != 2 {
+		t.Errorf("connected %d times for calls %q, want once", connects, api.calls)
+	}
+
+	// A failed connection is reported and tried again on the next call.
+	failing := NewResourceUsecase(nil, zap.NewNop())
+	failing.Connect = func() (ResourceAPI, error) { return nil, errors.New("no credentials") }
+	if err := failing.EnsureAPI(); err == nil || err.Error() != "no credentials" {
+		t.Errorf("EnsureAPI = %v, want the connection error", err)
+	}
+	if failing.API != nil {
+		t.Errorf("API = %v after a failed connection", failing.API)
+	}
+	if err := NewResourceUsecase(nil, zap.NewNop()).EnsureAPI(); err == nil {
+		t.Error("expected an error without an API client or Connect")
+	}
+}
@@ -1,16 +1,11 @@
 package resource
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"net/url"
-	"strconv"
 
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
-	"github.com/iagonc/jorge-cli/cmd/cli/internal/utils"
+	"github.com/iagonc/jorge-cli/cmd/cli/pkg/client"
 	"go.uber.org/zap"
 )
 
@@ -19,45 +14,19 @@ func (s *ResourceUsecase) UpdateResource(ctx context.Context, id int, name, dns
         return nil, fmt.Errorf("at least one of 'name' or 'dns' must be provided")
     }
 
-    updateReq := models.UpdateRequest{
-        Name: name,
-        Dns:  dns,
-    }
-
-    jsonData, err := json.Marshal(updateReq)
-    if err != nil {
-        return nil, fmt.Errorf("error marshaling JSON: %w", err)
-    }
-
-    baseURL := fmt.Sprintf("%s/resource", s.Config.APIBaseURL)
-    params := url.Values{}
-    params.Add("id", strconv.Itoa(id))
-    fullURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
-
-    req, err := http.NewRequestWithContext(ctx, "PUT", fullURL, bytes.NewBuffer(jsonData))
+    api, err := s.api()
     if err != nil {
-        return nil, fmt.Errorf("error creating request: %w", err)
+        return nil, err
     }
-    req.Header.Set("Content-Type", "application/json")
-
-    resp, err := s.Client.Do(req)
+    resource, err := api.UpdateResource(ctx, id, client.UpdateRequest{
+        Name: name,
+        Dns:  dns,
+    })
     if err != nil {
-        return nil, fmt.Errorf("error sending request: %w", err)
-    }
-    defer resp.Body.Close()
-
-    if resp.StatusCode == http.StatusNotFound {
-        return nil, fmt.Errorf("resource with ID %d not found", id)
-    } else if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-        return nil, utils.ParseErrorResponse(resp)
-    }
-
-    var updateResp models.UpdateResponse
-    if err := json.NewDecoder(resp.Body).Decode(&updateResp); err != nil {
-        return nil, fmt.Errorf("error decoding response: %w", err)
+        return nil, notFound(id, err)
     }
 
-    s.Logger.Info("Resource updated", zap.Int("ID", updateResp.Data.ID))
+    s.Logger.Info("Resource updated", zap.Int("ID", resource.ID))
 
-    return &updateResp.Data, nil
-}
\ No newline at end of file
+    return resource, nil
+}
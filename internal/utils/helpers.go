@@ -3,9 +3,7 @@ package utils
 import (
 	"bufio"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"os/signal"
@@ -15,6 +13,7 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/term"
 )
 
 type HTTPClient interface {
@@ -28,34 +27,12 @@ func NewHTTPClient(timeout time.Duration) *http.Client {
 	}
 }
 
-// ParseErrorResponse parses the API error response and returns a formatted error.
-func ParseErrorResponse(resp *http.Response) error {
-	contentType := resp.Header.Get("Content-Type")
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
-	}
-
-	if strings.Contains(contentType, "application/json") {
-		var errResp struct {
-			Error   string `json:"error"`
-			Message string `json:"message"`
-		}
-		if err := json.Unmarshal(bodyBytes, &errResp); err != nil {
-			return fmt.Errorf("HTTP %d: %s - %s", resp.StatusCode, http.StatusText(resp.StatusCode), string(bodyBytes))
-		}
-		return fmt.Errorf("API Error: %s - %s", errResp.Error, errResp.Message)
-	}
-
-	// Handle non-JSON error responses
-	return fmt.Errorf("HTTP %d: %s - %s", resp.StatusCode, http.StatusText(resp.StatusCode), string(bodyBytes))
-}
-
-// ConfirmAction prompts the user for confirmation.
+// ConfirmAction prompts the user for confirmation. The prompt goes to stderr,
+// so stdout holds only the result of the command.
 func ConfirmAction(prompt string) bool {
 	reader := bufio.NewReader(os.Stdin)
 	for {
-		fmt.Print(prompt)
+		fmt.Fprint(os.Stderr, prompt)
 		input, err := reader.ReadString('\n')
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
@@ -68,7 +45,7 @@ func ConfirmAction(prompt string) bool {
 		case "no", "n":
 			return false
 		default:
-			fmt.Println("Invalid input. Please type 'yes' or 'no'.")
+			fmt.Fprintln(os.Stderr, "Invalid input. Please type 'yes' or 'no'.")
 		}
 	}
 }
@@ -109,3 +86,8 @@ func ValidateCreateInputs(name, dns string) error {
 	}
 	return nil
 }
+
+// IsTerminal reports whether f is attached to an interactive terminal.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
@@ -7,8 +7,14 @@ import (
 	"go.uber.org/zap"
 )
 
+// LogLevel is the level of the logger built by InitializeLogger; --debug-log
+// lowers it to debug at run time.
+var LogLevel = zap.NewAtomicLevelAt(zap.InfoLevel)
+
 func InitializeLogger() (*zap.Logger, error) {
-	logger, err := zap.NewProduction()
+	config := zap.NewProductionConfig()
+	config.Level = LogLevel
+	logger, err := config.Build()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing logger: %v\n", err)
 		return nil, err
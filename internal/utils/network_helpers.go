@@ -2,6 +2,9 @@ package utils
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
@@ -12,13 +15,25 @@ func FormatAndDisplayNetworkDebugResult(result *models.NetworkDebugResult, domai
     // Define styles using Lipgloss
     titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
     listStyle := lipgloss.NewStyle().PaddingLeft(2).Foreground(lipgloss.Color("#FFFFFF"))
+    skippedStyle := lipgloss.NewStyle().Faint(true)
+    skipped := skippedStyle.Render("- Skipped.")
 
     // DNS Lookup
-    fmt.Println(titleStyle.Render("✨ DNS Verification (dig):"))
-    if len(result.DNSLookup.Records) > 0 {
-        fmt.Printf("- The domain %s has the following DNS records:\n", domain)
+    fmt.Println(titleStyle.Render("✨ DNS Verification:"))
+    if result.IsSkipped("dns") {
+        fmt.Println(skipped)
+    } else if len(result.DNSLookup.Records) > 0 {
+        fmt.Printf("- The domain %s has the following DNS records (answered by %s):\n", domain, result.DNSLookup.Server)
         for _, record := range result.DNSLookup.Records {
-            fmt.Println(listStyle.Render(fmt.Sprintf("- Type: %s, IP: %s", record.Type, record.IP)))
+            fmt.Println(listStyle.Render(fmt.Sprintf("- %s %d %s %s %s", record.Name, record.TTL, record.Class, record.Type, record.Value)))
+        }
+        if len(result.DNSLookup.CNAMEChain) > 0 {
+            fmt.Printf("- CNAME chain: %s\n", strings.Join(result.DNSLookup.CNAMEChain, " → "))
+        }
+        for _, query := range result.DNSLookup.Queries {
+            if query.Error != "" {
+                fmt.Printf("- %s query failed: %s\n", query.Type, query.Error)
+            }
         }
     } else {
         fmt.Println("- No DNS records found.")
@@ -26,9 +41,11 @@ func FormatAndDisplayNetworkDebugResult(result *models.NetworkDebugResult, domai
     fmt.Println()
 
     // NSLookup
-    fmt.Println(titleStyle.Render("🔍 Address Lookup (nslookup):"))
-    if result.NSLookup.IP != "" {
-        fmt.Printf("- The IP address of %s is %s\n", domain, result.NSLookup.IP)
+    fmt.Println(titleStyle.Render("🔍 Address Lookup (system resolver):"))
+    if result.IsSkipped("nslookup") {
+        fmt.Println(skipped)
+    } else if len(result.NSLookup.Addresses) > 0 {
+        fmt.Printf("- %s resolves to %s\n", domain, strings.Join(result.NSLookup.Addresses, ", "))
     } else {
         fmt.Println("- No IP address found.")
     }
@@ -36,63 +53,294 @@ func FormatAndDisplayNetworkDebugResult(result *models.NetworkDebugResult, domai
 
     // Traceroute
     fmt.Println(titleStyle.Render("🚀 Data Route (Traceroute):"))
-    if len(result.Traceroute.Hops) > 0 {
-        lastHop := result.Traceroute.Hops[len(result.Traceroute.Hops)-1].Address
-        fmt.Printf("- Data traveled through %d points before reaching %s:\n", len(result.Traceroute.Hops), lastHop)
-        for _, hop := range result.Traceroute.Hops {
-            fmt.Printf("  %d. %s: Response in %s\n", hop.HopNumber, hop.Address, hop.ResponseTime)
+    if result.IsSkipped("traceroute") {
+        fmt.Println(skipped)
+    } else if len(result.Traceroute.Hops) > 0 {
+        trace := result.Traceroute
+        if trace.Note != "" {
+            fmt.Printf("- Note: %s\n", trace.Note)
+        }
+        if trace.Reached {
+            fmt.Printf("- Data traveled through %d points before reaching %s (%s):\n", len(trace.Hops), trace.Target, strings.ToUpper(trace.Protocol))
+        } else {
+            fmt.Printf("- %s was not reached within %d hops (%s):\n", trace.Target, trace.Hops[len(trace.Hops)-1].HopNumber, strings.ToUpper(trace.Protocol))
+        }
+        for _, hop := range trace.Hops {
+            if len(hop.Responders) == 0 {
+                fmt.Printf("  %2d. * * * (no reply)\n", hop.HopNumber)
+                continue
+            }
+            fmt.Printf("  %2d. %s  loss %.0f%% | min %s | avg %s | max %s\n", hop.HopNumber, describeResponder(hop.Responders[0]),
+                hop.LossPercent, hop.MinRTT.Round(10*time.Microsecond), hop.AvgRTT.Round(10*time.Microsecond), hop.MaxRTT.Round(10*time.Microsecond))
+            for _, responder := range hop.Responders[1:] {
+                fmt.Printf("      %s\n", describeResponder(responder))
+            }
         }
     } else {
         fmt.Println("- No traceroute data available.")
     }
     fmt.Println()
 
-    // HTTP Request (curl)
-    fmt.Println(titleStyle.Render("📡 Site Verification (curl):"))
-    if result.HTTPRequest.Status != "" {
-        fmt.Printf("- Site Status: Working correctly (%s)\n", result.HTTPRequest.Status)
-        fmt.Printf("- Response Time: %s\n", result.HTTPRequest.ResponseTime)
-        fmt.Printf("- Content Type: %s\n", result.HTTPRequest.ContentType)
+    // HTTP Request
+    fmt.Println(titleStyle.Render("📡 Site Verification (HTTP):"))
+    if result.IsSkipped("http") {
+        fmt.Println(skipped)
+    } else if result.HTTPRequest.Status != "" {
+        httpResult := result.HTTPRequest
+        timings := httpResult.Timings
+        for _, redirect := range httpResult.Redirects {
+            fmt.Printf("- Redirect: %s -> %d -> %s (%s)\n", redirect.URL, redirect.StatusCode, redirect.Location, redirect.Duration.Round(time.Millisecond))
+        }
+        fmt.Printf("- %s %s (%s)\n", httpResult.Method, httpResult.URL, httpResult.Proto)
+        fmt.Printf("- Site Status: %s (%s)\n", describeHTTPStatus(httpResult.StatusCode), httpResult.Status)
+        fmt.Printf("- Response Time: %s\n", timings.Total.Round(time.Millisecond))
+        fmt.Printf("- Timings: DNS %s | Connect %s | TLS %s | TTFB %s | Transfer %s\n",
+            timings.DNSLookup.Round(10*time.Microsecond), timings.TCPConnect.Round(10*time.Microsecond),
+            timings.TLSHandshake.Round(10*time.Microsecond), timings.TimeToFirstByte.Round(10*time.Microsecond),
+            timings.Transfer.Round(10*time.Microsecond))
+        fmt.Printf("- Content Type: %s (%d bytes)\n", valueOrNone(httpResult.ContentType), httpResult.ContentLength)
+        for _, name := range sortedKeys(httpResult.Headers) {
+            fmt.Printf("  %s: %s\n", name, httpResult.Headers[name])
+        }
+        for _, expectation := range httpResult.Expectations {
+            mark := "✅"
+            if !expectation.Passed {
+                mark = "❌"
+            }
+            fmt.Printf("- Expect %s: %s\n", expectation.Description, mark)
+        }
     } else {
         fmt.Println("- No HTTP request data available.")
     }
     fmt.Println()
 
+    // TLS
+    fmt.Println(titleStyle.Render("🔒 TLS Certificate:"))
+    if result.IsSkipped("tls") {
+        fmt.Println(skipped)
+    } else if len(result.TLS.Chain) > 0 {
+        leaf := result.TLS.Chain[0]
+        fmt.Printf("- Negotiated %s with %s (ALPN: %s) in %s\n", result.TLS.Version, result.TLS.CipherSuite, valueOrNone(result.TLS.ALPN), result.TLS.HandshakeTime.Round(time.Millisecond))
+        fmt.Printf("- Hostname verified: %s | Chain trusted: %s | OCSP stapled: %s\n", yesNo(result.TLS.HostnameVerified), yesNo(result.TLS.ChainVerified), yesNo(result.TLS.OCSPStapled))
+        fmt.Printf("- Certificate for %s expires %s (%d days left)\n", strings.Join(leaf.SANs, ", "), leaf.NotAfter.Format("2006-01-02"), leaf.DaysToExpiry)
+        fmt.Println("- Chain:")
+        for i, cert := range result.TLS.Chain {
+            fmt.Println(listStyle.Render(fmt.Sprintf("%d. %s (issued by %s, valid %s to %s)", i+1, cert.Subject, cert.Issuer, cert.NotBefore.Format("2006-01-02"), cert.NotAfter.Format("2006-01-02"))))
+        }
+        for _, warning := range result.TLS.Warnings {
+            fmt.Printf("- ⚠️  %s\n", warning)
+        }
+    } else {
+        fmt.Println("- No TLS data available.")
+    }
+    fmt.Println()
+
     // Ping
     fmt.Println(titleStyle.Render("📈 Connection Test (Ping):"))
-    if result.Ping.Sent > 0 {
-        fmt.Printf("- Packets Sent: %d\n", result.Ping.Sent)
-        fmt.Printf("- Packets Received: %d\n", result.Ping.Received)
-        fmt.Printf("- Packet Loss: %.0f%%\n", result.Ping.LossPercent)
-        fmt.Printf("- Average Response Time: %d ms\n", result.Ping.AvgLatency)
+    if result.IsSkipped("ping") {
+        fmt.Println(skipped)
+    } else if result.Ping.Sent > 0 {
+        ping := result.Ping
+        target := ping.Target
+        if ping.Port != 0 {
+            target = fmt.Sprintf("%s port %d", target, ping.Port)
+        }
+        fmt.Printf("- Target: %s (%s)\n", target, strings.ToUpper(ping.Protocol))
+        if ping.Note != "" {
+            fmt.Printf("- Note: %s\n", ping.Note)
+        }
+        fmt.Printf("- Packets Sent: %d\n", ping.Sent)
+        fmt.Printf("- Packets Received: %d\n", ping.Received)
+        fmt.Printf("- Packet Loss: %.0f%%\n", ping.LossPercent)
+        if ping.Received > 0 {
+            fmt.Printf("- Round Trip: min %s | avg %s | max %s | stddev %s | jitter %s\n",
+                ping.MinLatency.Round(10*time.Microsecond), ping.AvgLatency.Round(10*time.Microsecond),
+                ping.MaxLatency.Round(10*time.Microsecond), ping.StdDev.Round(10*time.Microsecond),
+                ping.Jitter.Round(10*time.Microsecond))
+        }
+        for _, probe := range ping.Probes {
+            if probe.Success {
+                fmt.Printf("  seq=%d time=%s\n", probe.Seq, probe.RTT.Round(10*time.Microsecond))
+            } else {
+                fmt.Printf("  seq=%d %s\n", probe.Seq, probe.Error)
+            }
+        }
     } else {
         fmt.Println("- No ping data available.")
     }
     fmt.Println()
 
+    // Ports
+    fmt.Println(titleStyle.Render("🔌 Port Reachability:"))
+    if result.IsSkipped("ports") {
+        fmt.Println(skipped)
+    } else if len(result.Ports.Probes) > 0 {
+        ports := result.Ports
+        fmt.Printf("- Target: %s\n", ports.Target)
+        fmt.Printf("- Open: %d | Refused: %d | Filtered: %d\n", ports.Open, ports.Refused, ports.Filtered)
+        for _, probe := range ports.Probes {
+            line := fmt.Sprintf("  %d/%s %s", probe.Port, probe.Protocol, probe.State)
+            if probe.State == models.PortOpen || probe.State == models.PortRefused {
+                line += fmt.Sprintf(" (%s)", probe.Latency.Round(10*time.Microsecond))
+            }
+            if probe.Banner != "" {
+                line += fmt.Sprintf(" - %s", probe.Banner)
+            }
+            if probe.Error != "" && probe.State == models.PortError {
+                line += fmt.Sprintf(": %s", probe.Error)
+            }
+            fmt.Println(line)
+        }
+    } else {
+        fmt.Println("- No port data available.")
+    }
+    fmt.Println()
+
     // Netstat
-    fmt.Println(titleStyle.Render("🖥️ Active Connections (Netstat):"))
-    if len(result.Netstat.Connections) == 0 {
-        fmt.Println("- No active connections found.")
+    fmt.Println(titleStyle.Render("🖥️ Active Connections (Sockets):"))
+    if result.IsSkipped("netstat") {
+        fmt.Println(skipped)
     } else {
-        fmt.Println("- Active Connections:")
-        for _, conn := range result.Netstat.Connections {
-            fmt.Printf("  - %s %s → %s (%s)\n", conn.Protocol, conn.LocalAddress, conn.RemoteAddress, conn.Status)
+        if len(result.Netstat.Filter) > 0 {
+            fmt.Printf("- Sockets involving %s (%d of %d on this host)\n", strings.Join(result.Netstat.Filter, ", "), len(result.Netstat.Connections), result.Netstat.Total)
+        }
+        if len(result.Netstat.Connections) == 0 {
+            fmt.Println("- No active connections found.")
+        } else {
+            var states []string
+            for _, state := range sortedKeys(result.Netstat.States) {
+                states = append(states, fmt.Sprintf("%s %d", state, result.Netstat.States[state]))
+            }
+            fmt.Printf("- States: %s\n", strings.Join(states, " | "))
+            fmt.Println("- Active Connections:")
+            for _, conn := range result.Netstat.Connections {
+                owner := ""
+                if conn.PID != 0 {
+                    owner = fmt.Sprintf(" [%d/%s]", conn.PID, conn.Process)
+                }
+                fmt.Printf("  - %s %s → %s (%s)%s\n", conn.Protocol, conn.LocalAddress, conn.RemoteAddress, conn.Status, owner)
+            }
         }
     }
     fmt.Println()
 
-    // Iftop
-    fmt.Println(titleStyle.Render("📊 Current Network Usage (Iftop - Interface: eth0):"))
-    if result.Iftop.SendingKBps != "" || result.Iftop.ReceivingKBps != "" {
-        fmt.Println("- Current Traffic:")
-        fmt.Printf("  - Sending: %s\n", result.Iftop.SendingKBps)
-        fmt.Printf("  - Receiving: %s\n", result.Iftop.ReceivingKBps)
-        fmt.Println("- Top 3 Most Active Connections:")
-        for i, conn := range result.Iftop.TopConnections {
-            fmt.Printf("  %d. %s ↔ %s: Sending %s | Receiving %s\n", i+1, conn.Source, conn.Destination, conn.SentKBps, conn.ReceivedKBps)
+    // Interface
+    iface := result.Interface
+    fmt.Println(titleStyle.Render(fmt.Sprintf("📊 Current Network Usage (Interface: %s):", valueOrNone(iface.Name))))
+    if result.IsSkipped("interface") {
+        fmt.Println(skipped)
+    } else if iface.Window > 0 {
+        details := []string{"state " + valueOrNone(iface.OperState), fmt.Sprintf("MTU %d", iface.MTU)}
+        if iface.SpeedMbps > 0 {
+            details = append(details, fmt.Sprintf("%d Mb/s", iface.SpeedMbps))
         }
+        if iface.AutoDetected {
+            details = append(details, "default route")
+        }
+        fmt.Printf("- %s (%s)\n", iface.Name, strings.Join(details, ", "))
+        fmt.Printf("- Traffic over %s:\n", iface.Window.Round(time.Second))
+        fmt.Printf("  - Receiving: %s/s | %.1f packets/s\n", formatBytes(iface.RxBytesPerSec), iface.RxPacketsPerSec)
+        fmt.Printf("  - Sending: %s/s | %.1f packets/s\n", formatBytes(iface.TxBytesPerSec), iface.TxPacketsPerSec)
+        fmt.Printf("- Errors: rx %d, tx %d | Drops: rx %d, tx %d (since boot: errors rx %d, tx %d | drops rx %d, tx %d)\n",
+            iface.Delta.RxErrors, iface.Delta.TxErrors, iface.Delta.RxDropped, iface.Delta.TxDropped,
+            iface.Totals.RxErrors, iface.Totals.TxErrors, iface.Totals.RxDropped, iface.Totals.TxDropped)
     } else {
         fmt.Println("- No network usage data available.")
     }
+
+    // Raw output, only kept with --raw
+    if len(result.Raw) > 0 {
+        fmt.Println()
+        fmt.Println(titleStyle.Render("🧾 Raw Output:"))
+        for _, run := range result.Raw {
+            line := fmt.Sprintf("- %s: ran for %s", run.Check, run.Duration.Round(10*time.Microsecond))
+            if len(run.Commands) == 0 {
+                line += ", built in (no external commands)"
+            }
+            if run.Error != "" {
+                line += fmt.Sprintf(", error: %s", run.Error)
+            }
+            fmt.Println(line)
+            for _, command := range run.Commands {
+                fmt.Printf("  $ %s (exit %d, %s)\n", command.Command, command.ExitCode, command.Duration.Round(10*time.Microsecond))
+                printIndented("stdout", command.Stdout)
+                printIndented("stderr", command.Stderr)
+            }
+        }
+    }
+}
+
+// printIndented prints a command's output under a label, one indented line at a time.
+func printIndented(label, output string) {
+    output = strings.TrimRight(output, "\n")
+    if output == "" {
+        return
+    }
+    fmt.Printf("    %s:\n", label)
+    for _, line := range strings.Split(output, "\n") {
+        fmt.Printf("      %s\n", line)
+    }
+}
+
+// describeHTTPStatus labels a status code by its class.
+func describeHTTPStatus(code int) string {
+    switch {
+    case code >= 500:
+        return "Server error"
+    case code >= 400:
+        return "Client error"
+    case code >= 300:
+        return "Redirecting"
+    case code >= 200:
+        return "Working correctly"
+    }
+    return "Unexpected response"
+}
+
+func describeResponder(responder models.TracerouteResponder) string {
+    description := responder.Address
+    if responder.Hostname != "" {
+        description = fmt.Sprintf("%s (%s)", responder.Hostname, responder.Address)
+    }
+    if responder.ASN != "" {
+        description += fmt.Sprintf(" [%s %s]", responder.ASN, responder.ASName)
+    }
+    return description
+}
+
+func yesNo(value bool) string {
+    if value {
+        return "yes"
+    }
+    return "no"
+}
+
+func valueOrNone(value string) string {
+    if value == "" {
+        return "none"
+    }
+    return value
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+    keys := make([]string, 0, len(m))
+    for key := range m {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. 1.5 KiB.
+func formatBytes(n float64) string {
+    units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+    i := 0
+    for n >= 1024 && i < len(units)-1 {
+        n /= 1024
+        i++
+    }
+    if i == 0 {
+        return fmt.Sprintf("%.0f %s", n, units[i])
+    }
+    return fmt.Sprintf("%.1f %s", n, units[i])
 }
@@ -0,0 +1,483 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// Incident report formats.
+const (
+	ReportMarkdown = "markdown"
+	ReportHTML     = "html"
+)
+
+// ReportFormat picks the report format from the extension of path.
+func ReportFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return ReportMarkdown, nil
+	case ".html", ".htm":
+		return ReportHTML, nil
+	}
+	return "", fmt.Errorf("cannot tell the report format of '%s': use a .md or .html file", path)
+}
+
+// reportSection is one check of a report, in plain text so it pastes cleanly.
+type reportSection struct {
+	Check   string
+	Title   string
+	Status  string
+	Skipped bool
+	Reasons []string
+	Lines   []string
+	// Raw is the check's result as indented JSON.
+	Raw string
+	// Commands are the tools the check ran, when the result was kept with --raw.
+	Commands []models.RawCommand
+}
+
+// WriteNetworkDebugReport writes a self-contained incident report of result
+// for postmortem documents: the target, when and where it was diagnosed,
+// each check with its verdict and findings, the raw result of every check
+// in a collapsible section, and the errors. Unlike
+// FormatAndDisplayNetworkDebugResult it uses no colours or emoji.
+func WriteNetworkDebugReport(w io.Writer, result *models.NetworkDebugResult, format string) error {
+	sections, err := reportSections(result)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case ReportMarkdown:
+		return writeMarkdownReport(w, result, sections)
+	case ReportHTML:
+		return writeHTMLReport(w, result, sections)
+	}
+	return fmt.Errorf("unsupported report format '%s' (valid: %s, %s)", format, ReportMarkdown, ReportHTML)
+}
+
+func reportSections(result *models.NetworkDebugResult) ([]reportSection, error) {
+	statuses := make(map[string]models.CheckVerdict)
+	for _, verdict := range result.Verdict.Checks {
+		statuses[verdict.Check] = verdict
+	}
+	commands := make(map[string][]models.RawCommand)
+	for _, run := range result.Raw {
+		commands[run.Check] = run.Commands
+	}
+
+	checks := []struct {
+		name  string
+		title string
+		data  any
+	}{
+		{"dns", "DNS Verification", result.DNSLookup},
+		{"nslookup", "Address Lookup (system resolver)", result.NSLookup},
+		{"traceroute", "Data Route (Traceroute)", result.Traceroute},
+		{"http", "Site Verification (HTTP)", result.HTTPRequest},
+		{"tls", "TLS Certificate", result.TLS},
+		{"ping", "Connection Test (Ping)", result.Ping},
+		{"ports", "Port Reachability", result.Ports},
+		{"netstat", "Active Connections (Sockets)", result.Netstat},
+		{"interface", "Network Usage (Interface)", result.Interface},
+	}
+
+	var sections []reportSection
+	for _, check := range checks {
+		section := reportSection{Check: check.name, Title: check.title}
+		if result.IsSkipped(check.name) {
+			section.Skipped = true
+			section.Status = "skipped"
+			sections = append(sections, section)
+			continue
+		}
+		section.Status = statuses[check.name].Status
+		section.Reasons = statuses[check.name].Reasons
+		section.Lines = reportLines(check.name, result)
+
+		raw, err := json.MarshalIndent(check.data, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error encoding %s result: %w", check.name, err)
+		}
+		section.Raw = string(raw)
+		section.Commands = commands[check.name]
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+// reportLines summarizes one check the way the terminal report does.
+func reportLines(check string, result *models.NetworkDebugResult) []string {
+	var lines []string
+	add := func(format string, args ...any) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	switch check {
+	case "dns":
+		dns := result.DNSLookup
+		if len(dns.Records) == 0 {
+			add("No DNS records found.")
+		} else {
+			add("Answered by %s", dns.Server)
+		}
+		for _, record := range dns.Records {
+			add("%s %d %s %s %s", record.Name, record.TTL, record.Class, record.Type, record.Value)
+		}
+		if len(dns.CNAMEChain) > 0 {
+			add("CNAME chain: %s", strings.Join(dns.CNAMEChain, " -> "))
+		}
+		for _, query := range dns.Queries {
+			if query.Error != "" {
+				add("%s query failed: %s", query.Type, query.Error)
+			}
+		}
+	case "nslookup":
+		if len(result.NSLookup.Addresses) == 0 {
+			add("No IP address found.")
+		} else {
+			add("%s resolves to %s", result.Domain, strings.Join(result.NSLookup.Addresses, ", "))
+		}
+	case "traceroute":
+		trace := result.Traceroute
+		if len(trace.Hops) == 0 {
+			add("No traceroute data available.")
+			break
+		}
+		if trace.Note != "" {
+			add("Note: %s", trace.Note)
+		}
+		if trace.Reached {
+			add("Reached %s in %d hops (%s)", trace.Target, len(trace.Hops), strings.ToUpper(trace.Protocol))
+		} else {
+			add("%s was not reached within %d hops (%s)", trace.Target, trace.Hops[len(trace.Hops)-1].HopNumber, strings.ToUpper(trace.Protocol))
+		}
+		for _, hop := range trace.Hops {
+			if len(hop.Responders) == 0 {
+				add("%2d. * * * (no reply)", hop.HopNumber)
+				continue
+			}
+			var responders []string
+			for _, responder := range hop.Responders {
+				responders = append(responders, describeResponder(responder))
+			}
+			add("%2d. %s, loss %.0f%%, min %s, avg %s, max %s", hop.HopNumber, strings.Join(responders, ", "),
+				hop.LossPercent, hop.MinRTT.Round(10*time.Microsecond), hop.AvgRTT.Round(10*time.Microsecond), hop.MaxRTT.Round(10*time.Microsecond))
+		}
+	case "http":
+		httpResult := result.HTTPRequest
+		if httpResult.Status == "" {
+			add("No HTTP request data available.")
+			break
+		}
+		timings := httpResult.Timings
+		for _, redirect := range httpResult.Redirects {
+			add("Redirect: %s -> %d -> %s (%s)", redirect.URL, redirect.StatusCode, redirect.Location, redirect.Duration.Round(time.Millisecond))
+		}
+		add("%s %s (%s)", httpResult.Method, httpResult.URL, httpResult.Proto)
+		add("Status: %s (%s)", httpResult.Status, describeHTTPStatus(httpResult.StatusCode))
+		add("Timings: DNS %s, connect %s, TLS %s, TTFB %s, transfer %s, total %s",
+			timings.DNSLookup.Round(10*time.Microsecond), timings.TCPConnect.Round(10*time.Microsecond),
+			timings.TLSHandshake.Round(10*time.Microsecond), timings.TimeToFirstByte.Round(10*time.Microsecond),
+			timings.Transfer.Round(10*time.Microsecond), timings.Total.Round(10*time.Microsecond))
+		add("Content type: %s (%d bytes)", valueOrNone(httpResult.ContentType), httpResult.ContentLength)
+		for _, expectation := range httpResult.Expectations {
+			outcome := "passed"
+			if !expectation.Passed {
+				outcome = "failed"
+			}
+			add("Expect %s: %s", expectation.Description, outcome)
+		}
+	case "tls":
+		tlsResult := result.TLS
+		if len(tlsResult.Chain) == 0 {
+			add("No TLS data available.")
+			break
+		}
+		leaf := tlsResult.Chain[0]
+		add("Negotiated %s with %s (ALPN: %s) in %s", tlsResult.Version, tlsResult.CipherSuite, valueOrNone(tlsResult.ALPN), tlsResult.HandshakeTime.Round(time.Millisecond))
+		add("Hostname verified: %s, chain trusted: %s, OCSP stapled: %s", yesNo(tlsResult.HostnameVerified), yesNo(tlsResult.ChainVerified), yesNo(tlsResult.OCSPStapled))
+		add("Certificate for %s expires %s (%d days left)", strings.Join(leaf.SANs, ", "), leaf.NotAfter.Format("2006-01-02"), leaf.DaysToExpiry)
+		for i, cert := range tlsResult.Chain {
+			add("Chain %d: %s, issued by %s, valid %s to %s", i+1, cert.Subject, cert.Issuer, cert.NotBefore.Format("2006-01-02"), cert.NotAfter.Format("2006-01-02"))
+		}
+		for _, warning := range tlsResult.Warnings {
+			add("Warning: %s", warning)
+		}
+	case "ping":
+		ping := result.Ping
+		if ping.Sent == 0 {
+			add("No ping data available.")
+			break
+		}
+		target := ping.Target
+		if ping.Port != 0 {
+			target = fmt.Sprintf("%s port %d", target, ping.Port)
+		}
+		add("Target: %s (%s)", target, strings.ToUpper(ping.Protocol))
+		if ping.Note != "" {
+			add("Note: %s", ping.Note)
+		}
+		add("%d sent, %d received, %.0f%% loss", ping.Sent, ping.Received, ping.LossPercent)
+		if ping.Received > 0 {
+			add("Round trip: min %s, avg %s, max %s, stddev %s, jitter %s",
+				ping.MinLatency.Round(10*time.Microsecond), ping.AvgLatency.Round(10*time.Microsecond),
+				ping.MaxLatency.Round(10*time.Microsecond), ping.StdDev.Round(10*time.Microsecond),
+				ping.Jitter.Round(10*time.Microsecond))
+		}
+	case "ports":
+		ports := result.Ports
+		if len(ports.Probes) == 0 {
+			add("No port data available.")
+			break
+		}
+		add("%s: %d open, %d refused, %d filtered", ports.Target, ports.Open, ports.Refused, ports.Filtered)
+		for _, probe := range ports.Probes {
+			line := fmt.Sprintf("%d/%s %s", probe.Port, probe.Protocol, probe.State)
+			if probe.State == models.PortOpen || probe.State == models.PortRefused {
+				line += fmt.Sprintf(" (%s)", probe.Latency.Round(10*time.Microsecond))
+			}
+			if probe.Banner != "" {
+				line += fmt.Sprintf(" - %s", probe.Banner)
+			}
+			if probe.Error != "" && probe.State == models.PortError {
+				line += fmt.Sprintf(": %s", probe.Error)
+			}
+			add("%s", line)
+		}
+	case "netstat":
+		netstat := result.Netstat
+		if len(netstat.Filter) > 0 {
+			add("Sockets involving %s (%d of %d on this host)", strings.Join(netstat.Filter, ", "), len(netstat.Connections), netstat.Total)
+		}
+		if len(netstat.Connections) == 0 {
+			add("No active connections found.")
+			break
+		}
+		var states []string
+		for _, state := range sortedKeys(netstat.States) {
+			states = append(states, fmt.Sprintf("%s %d", state, netstat.States[state]))
+		}
+		add("States: %s", strings.Join(states, ", "))
+		for _, conn := range netstat.Connections {
+			owner := ""
+			if conn.PID != 0 {
+				owner = fmt.Sprintf(" [%d/%s]", conn.PID, conn.Process)
+			}
+			add("%s %s -> %s (%s)%s", conn.Protocol, conn.LocalAddress, conn.RemoteAddress, conn.Status, owner)
+		}
+	case "interface":
+		iface := result.Interface
+		if iface.Window == 0 {
+			add("No network usage data available.")
+			break
+		}
+		add("%s: state %s, MTU %d, %d Mb/s", iface.Name, valueOrNone(iface.OperState), iface.MTU, iface.SpeedMbps)
+		add("Receiving %s/s, %.1f packets/s over %s", formatBytes(iface.RxBytesPerSec), iface.RxPacketsPerSec, iface.Window.Round(time.Second))
+		add("Sending %s/s, %.1f packets/s over %s", formatBytes(iface.TxBytesPerSec), iface.TxPacketsPerSec, iface.Window.Round(time.Second))
+		add("Errors rx %d, tx %d; drops rx %d, tx %d (since boot: errors rx %d, tx %d; drops rx %d, tx %d)",
+			iface.Delta.RxErrors, iface.Delta.TxErrors, iface.Delta.RxDropped, iface.Delta.TxDropped,
+			iface.Totals.RxErrors, iface.Totals.TxErrors, iface.Totals.RxDropped, iface.Totals.TxDropped)
+	}
+	return lines
+}
+
+// reportHost describes where the diagnostics ran from in one line.
+func reportHost(host models.HostInfo) string {
+	platform := host.OS + "/" + host.Arch
+	if host.Kernel != "" {
+		platform += ", kernel " + host.Kernel
+	}
+	return fmt.Sprintf("%s (%s)", valueOrNone(host.Hostname), platform)
+}
+
+func reportTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05 MST")
+}
+
+// markdownEscaper escapes what would turn plain findings into markup; the
+// rest of Markdown's syntax only matters at the start of a line.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "[", `\[`, "]", `\]`, "<", `\<`, "|", `\|`,
+)
+
+func writeMarkdownReport(w io.Writer, result *models.NetworkDebugResult, sections []reportSection) error {
+	var b bytes.Buffer
+	esc := markdownEscaper.Replace
+
+	fmt.Fprintf(&b, "# Network incident report: %s\n\n", esc(result.Domain))
+	fmt.Fprintln(&b, "| | |")
+	fmt.Fprintln(&b, "|---|---|")
+	fmt.Fprintf(&b, "| Target | %s |\n", esc(result.Domain))
+	fmt.Fprintf(&b, "| Timestamp | %s |\n", reportTimestamp(result.Timestamp))
+	fmt.Fprintf(&b, "| Verdict | **%s** |\n", strings.ToUpper(valueOrNone(result.Verdict.Status)))
+	fmt.Fprintf(&b, "| Host | %s |\n", esc(reportHost(result.Host)))
+	fmt.Fprintf(&b, "| Host addresses | %s |\n", esc(valueOrNone(strings.Join(result.Host.Addresses, ", "))))
+	fmt.Fprintf(&b, "| Skipped checks | %s |\n", esc(valueOrNone(strings.Join(result.Skipped, ", "))))
+
+	for _, section := range sections {
+		fmt.Fprintf(&b, "\n## %s: %s\n\n", esc(section.Title), strings.ToUpper(valueOrNone(section.Status)))
+		if section.Skipped {
+			fmt.Fprintln(&b, "Skipped.")
+			continue
+		}
+		for _, reason := range section.Reasons {
+			fmt.Fprintf(&b, "> **%s:** %s\n\n", strings.ToUpper(section.Status), esc(reason))
+		}
+		for _, line := range section.Lines {
+			fmt.Fprintf(&b, "- %s\n", esc(strings.TrimSpace(line)))
+		}
+		fence := markdownFence(section.Raw)
+		fmt.Fprintf(&b, "\n<details>\n<summary>Raw %s result</summary>\n\n%sjson\n%s\n%s\n\n</details>\n", section.Check, fence, section.Raw, fence)
+		for _, command := range section.Commands {
+			output := commandOutput(command)
+			fence := markdownFence(output)
+			fmt.Fprintf(&b, "\n<details>\n<summary>%s</summary>\n\n%s\n%s\n%s\n\n</details>\n", template.HTMLEscapeString(commandSummary(command)), fence, output, fence)
+		}
+	}
+
+	fmt.Fprintln(&b, "\n## Errors")
+	fmt.Fprintln(&b)
+	if len(result.Errors) == 0 {
+		fmt.Fprintln(&b, "No errors.")
+	}
+	for _, toolErr := range result.Errors {
+		fmt.Fprintf(&b, "- **%s:** %s\n", esc(toolErr.Tool), esc(toolErr.Message))
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// markdownFence returns a code fence longer than any backtick run in s, so
+// raw output cannot close its block early.
+func markdownFence(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// commandSummary is the title of a command's collapsible section.
+func commandSummary(command models.RawCommand) string {
+	return fmt.Sprintf("$ %s (exit %d, %s)", command.Command, command.ExitCode, command.Duration.Round(10*time.Microsecond))
+}
+
+// commandOutput is what a command printed, stdout first.
+func commandOutput(command models.RawCommand) string {
+	output := strings.TrimRight(command.Stdout, "\n")
+	if stderr := strings.TrimRight(command.Stderr, "\n"); stderr != "" {
+		output += "\n--- stderr ---\n" + stderr
+	}
+	return output
+}
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"upper":          strings.ToUpper,
+	"commandSummary": commandSummary,
+	"commandOutput":  commandOutput,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Network incident report: {{.Domain}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #1f2328; }
+h1 { border-bottom: 1px solid #d0d7de; padding-bottom: .3em; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: .3em .8em; border: 1px solid #d0d7de; vertical-align: top; }
+pre { background: #f6f8fa; padding: 1em; overflow-x: auto; font-size: 85%; }
+details { margin: .5em 0 1.5em; }
+summary { cursor: pointer; color: #57606a; }
+.status { display: inline-block; padding: .1em .6em; border-radius: 1em; font-size: 75%; font-weight: 600; color: #fff; vertical-align: middle; background: #8c959f; }
+.pass { background: #1a7f37; }
+.warn { background: #bf8700; }
+.fail { background: #cf222e; }
+.reason { margin: .3em 0; padding: .3em .8em; border-left: 4px solid #bf8700; }
+.reason.fail { border-left-color: #cf222e; }
+</style>
+</head>
+<body>
+<h1>Network incident report: {{.Domain}}</h1>
+<table>
+<tr><th>Target</th><td>{{.Domain}}</td></tr>
+<tr><th>Timestamp</th><td>{{.Timestamp}}</td></tr>
+<tr><th>Verdict</th><td><span class="status {{.Status}}">{{upper .Status}}</span></td></tr>
+<tr><th>Host</th><td>{{.Host}}</td></tr>
+<tr><th>Host addresses</th><td>{{.Addresses}}</td></tr>
+<tr><th>Skipped checks</th><td>{{.Skipped}}</td></tr>
+</table>
+{{range .Sections}}
+<h2>{{.Title}} <span class="status {{.Status}}">{{upper .Status}}</span></h2>
+{{- if .Skipped}}
+<p>Skipped.</p>
+{{- else}}
+{{- $status := .Status}}
+{{- range .Reasons}}
+<div class="reason {{$status}}">{{.}}</div>
+{{- end}}
+<ul>
+{{- range .Lines}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+<details>
+<summary>Raw {{.Check}} result</summary>
+<pre>{{.Raw}}</pre>
+</details>
+{{- range .Commands}}
+<details>
+<summary>{{commandSummary .}}</summary>
+<pre>{{commandOutput .}}</pre>
+</details>
+{{- end}}
+{{- end}}
+{{end}}
+<h2>Errors</h2>
+{{- if .Errors}}
+<ul>
+{{- range .Errors}}
+<li><strong>{{.Tool}}:</strong> {{.Message}}</li>
+{{- end}}
+</ul>
+{{- else}}
+<p>No errors.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+func writeHTMLReport(w io.Writer, result *models.NetworkDebugResult, sections []reportSection) error {
+	return htmlReport.Execute(w, struct {
+		Domain    string
+		Timestamp string
+		Status    string
+		Host      string
+		Addresses string
+		Skipped   string
+		Sections  []reportSection
+		Errors    []models.ToolError
+	}{
+		Domain:    result.Domain,
+		Timestamp: reportTimestamp(result.Timestamp),
+		Status:    valueOrNone(result.Verdict.Status),
+		Host:      reportHost(result.Host),
+		Addresses: valueOrNone(strings.Join(result.Host.Addresses, ", ")),
+		Skipped:   valueOrNone(strings.Join(result.Skipped, ", ")),
+		Sections:  sections,
+		Errors:    result.Errors,
+	})
+}
@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+func TestReportFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "incident.md", want: ReportMarkdown},
+		{path: "notes/INCIDENT.Markdown", want: ReportMarkdown},
+		{path: "report.html", want: ReportHTML},
+		{path: "/tmp/report.HTM", want: ReportHTML},
+		{path: "report.txt"},
+		{path: "report"},
+		{path: "report.md.bak"},
+	}
+
+	for _, tt := range tests {
+		got, err := ReportFormat(tt.path)
+		if tt.want == "" {
+			want := "cannot tell the report format of '" + tt.path + "': use a .md or .html file"
+			if err == nil || err.Error() != want {
+				t.Errorf("ReportFormat(%q) = %q, %v; want %q", tt.path, got, err, want)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ReportFormat(%q) = %q, %v; want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
+
+func TestMarkdownEscaper(t *testing.T) {
+	tests := map[string]string{
+		"plain finding, 12ms":          "plain finding, 12ms",
+		"`rm -rf` *bold* [link](x)":    "\\`rm -rf\\` \\*bold\\* \\[link\\](x)",
+		"<b>a|b</b>":                   "\\<b>a\\|b\\</b>",
+		`C:\path`:                      `C:\\path`,
+		"# only special at line start": "# only special at line start",
+	}
+	for in, want := range tests {
+		if got := markdownEscaper.Replace(in); got != want {
+			t.Errorf("escape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMarkdownFence(t *testing.T) {
+	tests := map[string]string{
+		"":                   "```",
+		"no backticks":       "```",
+		"a `quoted` word":    "```",
+		"```\ncode\n```":     "````",
+		"`` and ````` and `": "``````",
+		"trailing ````":      "`````",
+	}
+	for in, want := range tests {
+		if got := markdownFence(in); got != want {
+			t.Errorf("markdownFence(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// hostileResult is a diagnosis whose target, findings, errors and tool
+// output all try to break out of the report's markup.
+func hostileResult() *models.NetworkDebugResult {
+	return &models.NetworkDebugResult{
+		Domain:  `<script>alert("domain")</script>`,
+		Skipped: []string{"dns", "nslookup", "http", "tls", "ping", "ports", "netstat", "interface"},
+		Verdict: models.Verdict{Status: models.VerdictFail, Checks: []models.CheckVerdict{
+			{Check: "traceroute", Status: models.VerdictFail, Reasons: []string{`hop <img src=x onerror=alert("reason")>`}},
+		}},
+		Raw: []models.CheckRun{{Check: "traceroute", Commands: []models.RawCommand{{
+			Command: `traceroute "<b>"`,
+			Stdout:  "```\n</pre><script>alert(\"stdout\")</script>\n```",
+		}}}},
+		Errors: []models.ToolError{{Tool: "traceroute", Message: `</li><script>alert("error")</script>`}},
+	}
+}
+
+func TestMarkdownReportFencesRawOutput(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteNetworkDebugReport(&b, hostileResult(), ReportMarkdown); err != nil {
+		t.Fatal(err)
+	}
+	report := b.String()
+
+	// The tool printed a ``` fence of its own, so its block needs four.
+	block := "````\n```\n</pre><script>alert(\"stdout\")</script>\n```\n````\n"
+	if !strings.Contains(report, block) {
+		t.Errorf("report does not keep the command output in a longer fence:\n%s", report)
+	}
+	for _, want := range []string{
+		"# Network incident report: \\<script>alert(\"domain\")\\</script>\n",
+		"> **FAIL:** hop \\<img src=x onerror=alert(\"reason\")>\n",
+		"<summary>$ traceroute &#34;&lt;b&gt;&#34; (exit 0, 0s)</summary>",
+		"- **traceroute:** \\</li>\\<script>alert(\"error\")\\</script>\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestHTMLReportEscapesResult(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteNetworkDebugReport(&b, hostileResult(), ReportHTML); err != nil {
+		t.Fatal(err)
+	}
+	report := b.String()
+
+	for _, markup := range []string{"<script", "<img", "</li><", "</pre><", "<b>"} {
+		if strings.Contains(report, markup) {
+			t.Errorf("report contains unescaped %q:\n%s", markup, report)
+		}
+	}
+	for _, want := range []string{
+		"<title>Network incident report: &lt;script&gt;alert(&#34;domain&#34;)&lt;/script&gt;</title>",
+		`<div class="reason fail">hop &lt;img src=x onerror=alert(&#34;reason&#34;)&gt;</div>`,
+		"<pre>```\n&lt;/pre&gt;&lt;script&gt;alert(&#34;stdout&#34;)&lt;/script&gt;\n```</pre>",
+		"<li><strong>traceroute:</strong> &lt;/li&gt;&lt;script&gt;alert(&#34;error&#34;)&lt;/script&gt;</li>",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestWriteNetworkDebugReportRejectsUnknownFormat(t *testing.T) {
+	err := WriteNetworkDebugReport(&bytes.Buffer{}, hostileResult(), "pdf")
+	if err == nil || err.Error() != "unsupported report format 'pdf' (valid: markdown, html)" {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator adds credentials to every request a Client sends.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// invalidator is implemented by authenticators whose credentials can go
+// stale; the client drops them and retries once when the API answers 401.
+type invalidator interface {
+	Invalidate()
+}
+
+// WithAuth authenticates every request with auth.
+func WithAuth(auth Authenticator) Option {
+	return func(c *Client) {
+		c.auth = auth
+	}
+}
+
+// BearerToken sends a static token as "Authorization: Bearer <token>".
+type BearerToken struct {
+	Token string
+}
+
+func (a BearerToken) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+	return nil
+}
+
+// DefaultAPIKeyHeader is the header APIKey uses when none is set.
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// APIKey sends a key in a header, X-API-Key unless Header is set.
+type APIKey struct {
+	Header string
+	Key    string
+}
+
+func (a APIKey) Authenticate(req *http.Request) error {
+	header := a.Header
+	if header == "" {
+		header = DefaultAPIKeyHeader
+	}
+	req.Header.Set(header, a.Key)
+	return nil
+}
+
+// BasicAuth sends a username and password with HTTP basic authentication.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+func (a BasicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// tokenExpirySkew renews tokens this long before they expire, so a token
+// does not run out between being attached and reaching the API.
+const tokenExpirySkew = 30 * time.Second
+
+// ClientCredentials fetches a bearer token with the OAuth2 client credentials
+// grant and reuses it until shortly before it expires. Token and Expiry may be
+// preset with a token cached by a previous run.
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// HTTPClient requests tokens; nil means http.DefaultClient.
+	HTTPClient Doer
+	// OnToken is called with every new token, e.g. to cache it on disk.
+	OnToken func(token string, expiry time.Time)
+
+	mu     sync.Mutex
+	Token  string
+	Expiry time.Time
+}
+
+func (a *ClientCredentials) Authenticate(req *http.Request) error {
+	token, err := a.token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Invalidate drops the cached token, e.g. after the API rejected it.
+func (a *ClientCredentials) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.Token, a.Expiry = "", time.Time{}
+}
+
+// FetchToken requests a new token, replacing the cached one.
+func (a *ClientCredentials) FetchToken(ctx context.Context) (string, error) {
+	a.Invalidate()
+	return a.token(ctx)
+}
+
+func (a *ClientCredentials) token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// A zero expiry means the server did not say, so the token is kept until rejected.
+	if a.Token != "" && (a.Expiry.IsZero() || time.Until(a.Expiry) > tokenExpirySkew) {
+		return a.Token, nil
+	}
+
+	form := url.Values{"grant_type": []string{"client_credentials"}}
+	if len(a.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("error creating token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(a.ClientID), url.QueryEscape(a.ClientSecret))
+
+	httpClient := a.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error requesting token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("error reading token response: %w", err)
+	}
+	var tokenResp struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", fmt.Errorf("token endpoint returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if resp.StatusCode != http.StatusOK || tokenResp.AccessToken == "" {
+		if tokenResp.Error != "" {
+			return "", fmt.Errorf("token endpoint returned HTTP %d: %s %s", resp.StatusCode, tokenResp.Error, tokenResp.ErrorDescription)
+		}
+		return "", fmt.Errorf("token endpoint returned HTTP %d without an access token", resp.StatusCode)
+	}
+	if tokenResp.TokenType != "" && !strings.EqualFold(tokenResp.TokenType, "bearer") {
+		return "", fmt.Errorf("unsupported token type '%s'", tokenResp.TokenType)
+	}
+
+	a.Token = tokenResp.AccessToken
+	a.Expiry = time.Time{}
+	if tokenResp.ExpiresIn > 0 {
+		a.Expiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	}
+	if a.OnToken != nil {
+		a.OnToken(a.Token, a.Expiry)
+	}
+	return a.Token, nil
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tokenServer is an OAuth2 token endpoint issuing "token-1", "token-2", ...
+// valid for expiresIn seconds to client cli with secret s3cret.
+func tokenServer(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		id, secret, _ := r.BasicAuth()
+		if r.Method != http.MethodPost || r.FormValue("grant_type") != "client_credentials" || id != "cli" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client","error_description":"bad client credentials"}`)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("token-%d", issued.Add(1)),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+			"scope":        r.FormValue("scope"),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &issued
+}
+
+func TestClientCredentialsReusesTokenUntilExpiry(t *testing.T) {
+	server, issued := tokenServer(t, 3600)
+	var cached []string
+	auth := &ClientCredentials{
+		TokenURL:     server.URL,
+		ClientID:     "cli",
+		ClientSecret: "s3cret",
+		OnToken:      func(token string, expiry time.Time) { cached = append(cached, token) },
+	}
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/resources", nil)
+		if err := auth.Authenticate(req); err != nil {
+			t.Fatal(err)
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer token-1" {
+			t.Errorf("Authorization = %q, want Bearer token-1", got)
+		}
+	}
+	if issued.Load() != 1 || len(cached) != 1 {
+		t.Errorf("fetched %d tokens and cached %q, want one", issued.Load(), cached)
+	}
+	if until := time.Until(auth.Expiry); until < 59*time.Minute || until > time.Hour {
+		t.Errorf("expiry in %s, want an hour", until)
+	}
+
+	// A token about to expire is renewed before it is sent.
+	auth.Expiry = time.Now().Add(tokenExpirySkew / 2)
+	req := httptest.NewRequest(http.MethodGet, "/resources", nil)
+	if err := auth.Authenticate(req); err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer token-2" || len(cached) != 2 || cached[1] != "token-2" {
+		t.Errorf("Authorization = %q, cached %q; want the renewed token-2", got, cached)
+	}
+
+	// An expired token cached by a previous run is replaced too.
+	auth.Token, auth.Expiry = "stale", time.Now().Add(-time.Minute)
+	if token, err := auth.token(context.Background()); err != nil || token != "token-3" {
+		t.Errorf("token = %q, %v; want token-3", token, err)
+	}
+}
+
+func TestClientCredentialsKeepsTokenWithoutExpiry(t *testing.T) {
+	server, issued := tokenServer(t, 0)
+	auth := &ClientCredentials{TokenURL: server.URL, ClientID: "cli", ClientSecret: "s3cret", Scopes: []string{"read", "write"}}
+
+	for i := 0; i < 2; i++ {
+		if token, err := auth.token(context.Background()); err != nil || token != "token-1" {
+			t.Fatalf("token = %q, %v", token, err)
+		}
+	}
+	if issued.Load() != 1 || !auth.Expiry.IsZero() {
+		t.Errorf("fetched %d tokens with expiry %s, want one without expiry", issued.Load(), auth.Expiry)
+	}
+
+	if token, err := auth.FetchToken(context.Background()); err != nil || token != "token-2" {
+		t.Errorf("FetchToken = %q, %v; want a new token", token, err)
+	}
+}
+
+func TestClientCredentialsRenewsRejectedToken(t *testing.T) {
+	tokens, issued := tokenServer(t, 3600)
+	var sent []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":[{"ID":1,"name":"api"}]}`)
+	}))
+	defer api.Close()
+
+	// A token revoked on the server but cached as valid for another hour.
+	auth := &ClientCredentials{TokenURL: tokens.URL, ClientID: "cli", ClientSecret: "s3cret", Token: "revoked", Expiry: time.Now().Add(time.Hour)}
+	c, err := New(api.URL, WithAuth(auth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources, err := c.ListResources(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 1 || issued.Load() != 1 || strings.Join(sent, ",") != "Bearer revoked,Bearer token-1" {
+		t.Errorf("resources %v after sending %q with %d tokens fetched", resources, sent, issued.Load())
+	}
+}
+
+func TestClientCredentialsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		secret  string
+		wantErr string
+	}{
+		{name: "rejected client", secret: "wrong", wantErr: "token endpoint returned HTTP 401: invalid_client bad client credentials"},
+		{name: "not json", status: http.StatusBadGateway, body: "<html>bad gateway</html>", wantErr: "token endpoint returned HTTP 502: <html>bad gateway</html>"},
+		{name: "no access token", status: http.StatusOK, body: `{"token_type":"bearer"}`, wantErr: "token endpoint returned HTTP 200 without an access token"},
+		{name: "other token type", status: http.StatusOK, body: `{"access_token":"x","token_type":"mac"}`, wantErr: "unsupported token type 'mac'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := ""
+			if tt.body == "" {
+				server, _ := tokenServer(t, 60)
+				url = server.URL
+			} else {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(tt.status)
+					fmt.Fprint(w, tt.body)
+				}))
+				defer server.Close()
+				url = server.URL
+			}
+			secret := tt.secret
+			if secret == "" {
+				secret = "s3cret"
+			}
+
+			auth := &ClientCredentials{TokenURL: url, ClientID: "cli", ClientSecret: secret}
+			err := auth.Authenticate(httptest.NewRequest(http.MethodGet, "/", nil))
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+			if auth.Token != "" {
+				t.Errorf("token %q kept after a failed request", auth.Token)
+			}
+		})
+	}
+}
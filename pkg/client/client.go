@@ -0,0 +1,162 @@
+// Package client is a typed client for the resource API behind the CLI. It
+// holds the one request pipeline every call goes through: joining paths to
+// the base URL, setting headers, encoding request bodies, mapping error
+// responses to typed errors and decoding the response envelope.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Doer sends HTTP requests; *http.Client satisfies it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client calls the resource API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient Doer
+	header     http.Header
+	auth       Authenticator
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends requests through doer instead of http.DefaultClient,
+// e.g. to set a timeout or a custom transport.
+func WithHTTPClient(doer Doer) Option {
+	return func(c *Client) {
+		c.httpClient = doer
+	}
+}
+
+// WithHeader adds a header sent with every request.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithUserAgent sets the User-Agent sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.header.Set("User-Agent", userAgent)
+	}
+}
+
+// New returns a client for the API at baseURL, e.g. http://localhost:8080/api/v1.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API base URL '%s': %w", baseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid API base URL '%s': expected http(s)://host[/path]", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		header:     http.Header{"Accept": []string{"application/json"}},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// BaseURL returns the URL paths are joined to.
+func (c *Client) BaseURL() string {
+	return c.baseURL.String()
+}
+
+// Do sends method to path, relative to the base URL, with query appended.
+// A non-nil body is sent as JSON. A 2xx response is decoded into out unless
+// out is nil; any other status is returned as an *APIError.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	req, err := c.NewRequest(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	return c.send(req, out)
+}
+
+// send sends req, maps error responses and decodes the body into out. When
+// the API rejects credentials that can go stale, they are renewed once.
+func (c *Client) send(req *http.Request, out any) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	if auth, ok := c.auth.(invalidator); ok && resp.StatusCode == http.StatusUnauthorized && (req.Body == nil || req.GetBody != nil) {
+		resp.Body.Close()
+		auth.Invalidate()
+		retry := req.Clone(req.Context())
+		if req.Body != nil {
+			if retry.Body, err = req.GetBody(); err != nil {
+				return fmt.Errorf("error sending request: %w", err)
+			}
+		}
+		if err := c.auth.Authenticate(retry); err != nil {
+			return fmt.Errorf("error authenticating request: %w", err)
+		}
+		if resp, err = c.httpClient.Do(retry); err != nil {
+			return fmt.Errorf("error sending request: %w", err)
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return parseAPIError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	return nil
+}
+
+// NewRequest builds the request Do sends.
+func (c *Client) NewRequest(ctx context.Context, method, path string, query url.Values, body any) (*http.Request, error) {
+	u := c.baseURL.JoinPath(strings.TrimPrefix(path, "/"))
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling JSON: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	for key, values := range c.header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.auth != nil {
+		if err := c.auth.Authenticate(req); err != nil {
+			return nil, fmt.Errorf("error authenticating request: %w", err)
+		}
+	}
+	return req, nil
+}
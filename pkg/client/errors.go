@@ -0,0 +1,75 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Errors matched by an *APIError with errors.Is, by status code.
+var (
+	// ErrNotFound is a 404 Not Found.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is a 409 Conflict, e.g. a duplicate resource.
+	ErrConflict = errors.New("conflict")
+	// ErrValidation is a 400 Bad Request or 422 Unprocessable Entity.
+	ErrValidation = errors.New("validation failed")
+	// ErrUnauthorized is a 401 Unauthorized or 403 Forbidden.
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// APIError is a response outside the 2xx range.
+type APIError struct {
+	StatusCode int
+	// Code and Message are the "error" and "message" fields of a JSON error body.
+	Code    string
+	Message string
+	// Body is the raw body when it was not a JSON error.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" || e.Message != "" {
+		return fmt.Sprintf("API Error: %s - %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("HTTP %d: %s - %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// Is lets errors.Is match ErrNotFound, ErrConflict, ErrValidation and ErrUnauthorized.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+func parseAPIError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return apiErr
+	}
+
+	if strings.Contains(resp.Header.Get("Content-Type"), "application/json") {
+		var errResp struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &errResp); err == nil {
+			apiErr.Code, apiErr.Message = errResp.Error, errResp.Message
+			return apiErr
+		}
+	}
+	apiErr.Body = string(body)
+	return apiErr
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	typed := []error{ErrNotFound, ErrConflict, ErrValidation, ErrUnauthorized}
+
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusBadRequest, ErrValidation},
+		{http.StatusUnprocessableEntity, ErrValidation},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusInternalServerError, nil},
+		{http.StatusServiceUnavailable, nil},
+		{http.StatusTooManyRequests, nil},
+		{http.StatusGone, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			err := error(&APIError{StatusCode: tt.status})
+			for _, target := range typed {
+				if got := errors.Is(err, target); got != (target == tt.want) {
+					t.Errorf("errors.Is(%d, %v) = %v", tt.status, target, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDoMapsErrorResponses(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		want        error
+		message     string
+	}{
+		{
+			name:        "json not found",
+			status:      http.StatusNotFound,
+			contentType: "application/json; charset=utf-8",
+			body:        `{"error":"not_found","message":"resource not found"}`,
+			want:        ErrNotFound,
+			message:     "API Error: not_found - resource not found",
+		},
+		{
+			name:        "json conflict",
+			status:      http.StatusConflict,
+			contentType: "application/json",
+			body:        `{"error":"conflict","message":"name already taken"}`,
+			want:        ErrConflict,
+			message:     "API Error: conflict - name already taken",
+		},
+		{
+			name:        "json validation",
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
+			body:        `{"error":"invalid","message":"dns is required"}`,
+			want:        ErrValidation,
+			message:     "API Error: invalid - dns is required",
+		},
+		{
+			name:    "plain text forbidden",
+			status:  http.StatusForbidden,
+			body:    "go away",
+			want:    ErrUnauthorized,
+			message: "HTTP 403: Forbidden - go away",
+		},
+		{
+			name:        "malformed json body",
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+			body:        "<html>bad request</html>",
+			want:        ErrValidation,
+			message:     "HTTP 400: Bad Request - <html>bad request</html>",
+		},
+		{
+			name:    "server error",
+			status:  http.StatusInternalServerError,
+			body:    "boom",
+			message: "HTTP 500: Internal Server Error - boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			c, err := New(server.URL + "/api/v1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = c.GetResource(context.Background(), 7)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Fatalf("error = %v, want an *APIError with status %d", err, tt.status)
+			}
+			if err.Error() != tt.message {
+				t.Errorf("message = %q, want %q", err, tt.message)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+		})
+	}
+}
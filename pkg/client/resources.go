@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/iagonc/jorge-cli/cmd/cli/internal/models"
+)
+
+// Resource types of the API, aliased so importers can name them.
+type (
+	Resource      = models.Resource
+	CreateRequest = models.CreateRequest
+	UpdateRequest = models.UpdateRequest
+)
+
+// ListResources returns every resource.
+func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
+	var resp models.ApiResponse
+	if err := c.Do(ctx, http.MethodGet, "resources", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// GetResource returns the resource with id.
+func (c *Client) GetResource(ctx context.Context, id int) (*Resource, error) {
+	var resp models.GetResponse
+	if err := c.Do(ctx, http.MethodGet, "resource", idQuery(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// CreateResource creates a resource and returns it as stored. It sends an
+// idempotency key, so a retried request does not create a duplicate.
+func (c *Client) CreateResource(ctx context.Context, req CreateRequest) (*Resource, error) {
+	httpReq, err := c.NewRequest(ctx, http.MethodPost, "resource", nil, req)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set(IdempotencyKeyHeader, key)
+
+	var resp models.CreateResponse
+	if err := c.send(httpReq, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// UpdateResource changes the non-empty fields of req on the resource with id.
+func (c *Client) UpdateResource(ctx context.Context, id int, req UpdateRequest) (*Resource, error) {
+	var resp models.UpdateResponse
+	if err := c.Do(ctx, http.MethodPut, "resource", idQuery(id), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// DeleteResource deletes the resource with id and returns it.
+func (c *Client) DeleteResource(ctx context.Context, id int) (*Resource, error) {
+	var resp models.DeleteResponse
+	if err := c.Do(ctx, http.MethodDelete, "resource", idQuery(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+func newIdempotencyKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("error generating idempotency key: %w", err)
+	}
+	return hex.EncodeToString(key), nil
+}
+
+func idQuery(id int) url.Values {
+	return url.Values{"id": []string{strconv.Itoa(id)}}
+}
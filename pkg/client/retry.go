@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// IdempotencyKeyHeader carries a key that lets the API recognise a repeated
+// request, so requests that carry it can be retried whatever their method.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Retry defaults used when the RetryTransport fields are zero.
+const (
+	DefaultMaxAttempts = 3
+	DefaultBaseDelay   = 200 * time.Millisecond
+	DefaultMaxDelay    = 5 * time.Second
+)
+
+// RetryTransport retries requests that failed to get a response or got a
+// 429, 500, 502, 503 or 504, waiting with exponential backoff and jitter, or
+// for as long as a Retry-After header asks. Only GET, HEAD and OPTIONS and
+// requests with an Idempotency-Key are retried, so nothing is applied twice.
+type RetryTransport struct {
+	// Base sends each attempt; nil means http.DefaultTransport.
+	Base http.RoundTripper
+	// MaxAttempts counts the first try; 1 disables retries.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry, doubled for each one after.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. A Retry-After longer than this is not waited
+	// for and the response is returned as is.
+	MaxDelay time.Duration
+	// AttemptTimeout bounds each attempt, reading the response body included;
+	// zero means no limit. Unlike http.Client.Timeout, which covers every
+	// attempt and the waits between them, it leaves room for the retries.
+	AttemptTimeout time.Duration
+	// Logger records every attempt; nil disables logging.
+	Logger *zap.Logger
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	maxAttempts := t.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if !retryable(req) || req.Body != nil && req.GetBody == nil {
+		maxAttempts = 1
+	}
+	logger := t.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+		cancel := context.CancelFunc(func() {})
+		if t.AttemptTimeout > 0 {
+			var ctx context.Context
+			ctx, cancel = context.WithTimeout(req.Context(), t.AttemptTimeout)
+			attemptReq = attemptReq.WithContext(ctx)
+		}
+
+		start := time.Now()
+		resp, err := base.RoundTrip(attemptReq)
+		if err != nil {
+			cancel()
+		} else {
+			// The deadline keeps running while the caller reads the body.
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		}
+		fields := []zap.Field{
+			zap.String("method", req.Method),
+			zap.String("url", req.URL.Redacted()),
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", maxAttempts),
+			zap.Duration("duration", time.Since(start)),
+		}
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+		} else {
+			fields = append(fields, zap.Int("status", resp.StatusCode))
+		}
+		logger.Debug("API request attempt", fields...)
+
+		if attempt >= maxAttempts || req.Context().Err() != nil || err == nil && !retryableStatus(resp.StatusCode) {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if err == nil {
+			if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				if wait > t.maxDelay() {
+					return resp, nil
+				}
+				delay = wait
+			}
+			// Drain so the connection can be reused.
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		logger.Warn("Retrying API request", append(fields, zap.Duration("delay", delay))...)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// cancelOnClose releases the context of an attempt once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// backoff doubles the base delay for each retry up to the maximum, then picks
+// a random wait between half and all of it so clients do not retry in step.
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	delay := t.BaseDelay
+	if delay <= 0 {
+		delay = DefaultBaseDelay
+	}
+	for i := 1; i < attempt && delay < t.maxDelay(); i++ {
+		delay *= 2
+	}
+	delay = min(delay, t.maxDelay())
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func (t *RetryTransport) maxDelay() time.Duration {
+	if t.MaxDelay <= 0 {
+		return DefaultMaxDelay
+	}
+	return t.MaxDelay
+}
+
+// retryable reports whether sending req twice has the same effect as once.
+// PUT and DELETE are idempotent by definition, but an API that answers a
+// repeated DELETE with 404 or applies a PUT with side effects twice would
+// turn a retry into a spurious error, so they need an Idempotency-Key too.
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,298 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures requests with status, then answers
+// 200 with the body of the request, and counts every request it got.
+func flakyServer(t *testing.T, failures int, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if int(requests.Add(1)) <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func fastRetries() *RetryTransport {
+	return &RetryTransport{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+}
+
+func TestRetryTransportAttempts(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		status   int
+		attempts int
+		want     int
+	}{
+		{name: "success", failures: 0, status: http.StatusServiceUnavailable, attempts: 1, want: http.StatusOK},
+		{name: "recovers", failures: 2, status: http.StatusServiceUnavailable, attempts: 3, want: http.StatusOK},
+		{name: "gives up", failures: 5, status: http.StatusBadGateway, attempts: 3, want: http.StatusBadGateway},
+		{name: "too many requests", failures: 1, status: http.StatusTooManyRequests, attempts: 2, want: http.StatusOK},
+		{name: "client error", failures: 1, status: http.StatusNotFound, attempts: 1, want: http.StatusNotFound},
+		{name: "not implemented", failures: 1, status: http.StatusNotImplemented, attempts: 1, want: http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := flakyServer(t, tt.failures, tt.status, nil)
+			resp, err := (&http.Client{Transport: fastRetries()}).Get(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want || int(requests.Load()) != tt.attempts {
+				t.Errorf("status %d after %d attempts, want %d after %d", resp.StatusCode, requests.Load(), tt.want, tt.attempts)
+			}
+		})
+	}
+
+	t.Run("retries disabled", func(t *testing.T) {
+		server, requests := flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+		transport := fastRetries()
+		transport.MaxAttempts = 1
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable || requests.Load() != 1 {
+			t.Errorf("status %d after %d attempts, want 503 after 1", resp.StatusCode, requests.Load())
+		}
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		server, _ := flakyServer(t, 0, 0, nil)
+		url := server.URL
+		server.Close()
+		var attempts atomic.Int32
+		transport := fastRetries()
+		transport.Base = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts.Add(1)
+			return http.DefaultTransport.RoundTrip(req)
+		})
+		if _, err := (&http.Client{Transport: transport}).Get(url); err == nil {
+			t.Fatal("expected an error from a closed server")
+		}
+		if attempts.Load() != 3 {
+			t.Errorf("%d attempts, want 3", attempts.Load())
+		}
+	})
+}
+
+func TestRetryTransportMethods(t *testing.T) {
+	tests := []struct {
+		method   string
+		key      bool
+		attempts int
+	}{
+		{method: http.MethodGet, attempts: 2},
+		{method: http.MethodHead, attempts: 2},
+		{method: http.MethodOptions, attempts: 2},
+		{method: http.MethodPost, attempts: 1},
+		{method: http.MethodPut, attempts: 1},
+		{method: http.MethodDelete, attempts: 1},
+		{method: http.MethodPatch, attempts: 1},
+		{method: http.MethodPost, key: true, attempts: 2},
+		{method: http.MethodPut, key: true, attempts: 2},
+		{method: http.MethodDelete, key: true, attempts: 2},
+	}
+
+	for _, tt := range tests {
+		name := tt.method
+		if tt.key {
+			name += " with idempotency key"
+		}
+		t.Run(name, func(t *testing.T) {
+			server, requests := flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+			var body io.Reader
+			if tt.method != http.MethodGet && tt.method != http.MethodHead {
+				body = strings.NewReader(`{"name":"api"}`)
+			}
+			req, err := http.NewRequest(tt.method, server.URL, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.key {
+				req.Header.Set(IdempotencyKeyHeader, "0123456789abcdef")
+			}
+
+			resp, err := (&http.Client{Transport: fastRetries()}).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if int(requests.Load()) != tt.attempts {
+				t.Errorf("%d attempts, want %d", requests.Load(), tt.attempts)
+			}
+			// A retried request sends its body again.
+			if tt.attempts > 1 && body != nil && string(got) != `{"name":"api"}` {
+				t.Errorf("retried body = %q", got)
+			}
+		})
+	}
+
+	t.Run("body that cannot be replayed", func(t *testing.T) {
+		server, requests := flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+		req, _ := http.NewRequest(http.MethodPost, server.URL, io.NopCloser(strings.NewReader("data")))
+		req.Header.Set(IdempotencyKeyHeader, "0123456789abcdef")
+		resp, err := (&http.Client{Transport: fastRetries()}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if requests.Load() != 1 {
+			t.Errorf("%d attempts, want 1", requests.Load())
+		}
+	})
+}
+
+func TestRetryTransportRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		maxDelay   time.Duration
+		attempts   int
+		minElapsed time.Duration
+	}{
+		{name: "seconds", retryAfter: "1", maxDelay: 2 * time.Second, attempts: 2, minElapsed: time.Second},
+		{name: "zero", retryAfter: "0", maxDelay: time.Second, attempts: 2},
+		{name: "date in the past", retryAfter: "Wed, 21 Oct 2015 07:28:00 GMT", maxDelay: time.Second, attempts: 2},
+		{name: "longer than the maximum", retryAfter: "120", maxDelay: time.Second, attempts: 1},
+		{name: "unparseable", retryAfter: "soon", maxDelay: time.Second, attempts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {tt.retryAfter}})
+			transport := &RetryTransport{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: tt.maxDelay}
+
+			start := time.Now()
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			elapsed := time.Since(start)
+
+			if int(requests.Load()) != tt.attempts {
+				t.Errorf("%d attempts, want %d", requests.Load(), tt.attempts)
+			}
+			if tt.attempts == 1 && (resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != tt.retryAfter) {
+				t.Errorf("got %d with Retry-After %q, want the 429 as is", resp.StatusCode, resp.Header.Get("Retry-After"))
+			}
+			if elapsed < tt.minElapsed {
+				t.Errorf("retried after %s, want at least %s", elapsed, tt.minElapsed)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if wait, ok := retryAfter("3"); !ok || wait != 3*time.Second {
+		t.Errorf("retryAfter(3) = %s, %v", wait, ok)
+	}
+	future := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	if wait, ok := retryAfter(future); !ok || wait < 28*time.Second || wait > 30*time.Second {
+		t.Errorf("retryAfter(%s) = %s, %v", future, wait, ok)
+	}
+	for _, value := range []string{"", "-1", "1.5", "tomorrow"} {
+		if wait, ok := retryAfter(value); ok {
+			t.Errorf("retryAfter(%q) = %s, want no value", value, wait)
+		}
+	}
+}
+
+func TestRetryTransportBackoff(t *testing.T) {
+	transport := &RetryTransport{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, ceiling := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		for i := 0; i < 50; i++ {
+			if delay := transport.backoff(attempt); delay < ceiling/2 || delay > ceiling {
+				t.Fatalf("backoff(%d) = %s, want between %s and %s", attempt, delay, ceiling/2, ceiling)
+			}
+		}
+	}
+
+	defaults := &RetryTransport{}
+	if delay := defaults.backoff(1); delay < DefaultBaseDelay/2 || delay > DefaultBaseDelay {
+		t.Errorf("default backoff(1) = %s", delay)
+	}
+}
+
+func TestRetryTransportAttemptTimeout(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// Hang until the client gives up on the attempt.
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	transport := fastRetries()
+	transport.AttemptTimeout = 100 * time.Millisecond
+	start := time.Now()
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// The deadline of the attempt must not cut the body short.
+	time.Sleep(2 * transport.AttemptTimeout)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "ok" {
+		t.Errorf("body = %q, %v", body, err)
+	}
+	if requests.Load() != 2 || time.Since(start) > 5*time.Second {
+		t.Errorf("%d attempts in %s, want the hung one abandoned and retried", requests.Load(), time.Since(start))
+	}
+}
+
+func TestRetryTransportStopsWhenCancelled(t *testing.T) {
+	server, requests := flakyServer(t, 5, http.StatusServiceUnavailable, nil)
+	transport := &RetryTransport{MaxAttempts: 5, BaseDelay: time.Minute, MaxDelay: time.Minute}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	_, err := (&http.Client{Transport: transport}).Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) || requests.Load() != 1 {
+		t.Errorf("error = %v after %d attempts, want the deadline after 1", err, requests.Load())
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}